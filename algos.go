@@ -0,0 +1,1128 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Directions used for traversing a hexagonal ring counterclockwise around
+// {1, 0, 0}
+//
+//	   _
+//	 _/ \_
+//	/ \5/ \
+//	\0/ \4/
+//	/ \_/ \
+//	\1/ \3/
+//	  \2/
+var DIRECTIONS = [6]Direction{
+	J_AXES_DIGIT, JK_AXES_DIGIT, K_AXES_DIGIT,
+	IK_AXES_DIGIT, I_AXES_DIGIT, IJ_AXES_DIGIT,
+}
+
+// Direction used for traversing to the next outward hexagonal ring.
+const NEXT_RING_DIRECTION = I_AXES_DIGIT
+
+// New digit when traversing along class II grids.
+//
+// Current digit . desired digit . new digit
+var NEW_DIGIT_II = [7][7]Direction{
+	{CENTER_DIGIT, K_AXES_DIGIT, J_AXES_DIGIT, JK_AXES_DIGIT, I_AXES_DIGIT, IK_AXES_DIGIT, IJ_AXES_DIGIT}, // 0
+	{K_AXES_DIGIT, I_AXES_DIGIT, JK_AXES_DIGIT, IJ_AXES_DIGIT, IK_AXES_DIGIT, J_AXES_DIGIT, CENTER_DIGIT}, // 1
+	{J_AXES_DIGIT, JK_AXES_DIGIT, K_AXES_DIGIT, I_AXES_DIGIT, IJ_AXES_DIGIT, CENTER_DIGIT, IK_AXES_DIGIT}, // 2
+	{JK_AXES_DIGIT, IJ_AXES_DIGIT, I_AXES_DIGIT, IK_AXES_DIGIT, CENTER_DIGIT, K_AXES_DIGIT, J_AXES_DIGIT}, // 3
+	{I_AXES_DIGIT, IK_AXES_DIGIT, IJ_AXES_DIGIT, CENTER_DIGIT, J_AXES_DIGIT, JK_AXES_DIGIT, K_AXES_DIGIT}, // 4
+	{IK_AXES_DIGIT, J_AXES_DIGIT, CENTER_DIGIT, K_AXES_DIGIT, JK_AXES_DIGIT, IJ_AXES_DIGIT, I_AXES_DIGIT}, // 5
+	{IJ_AXES_DIGIT, CENTER_DIGIT, IK_AXES_DIGIT, J_AXES_DIGIT, K_AXES_DIGIT, I_AXES_DIGIT, JK_AXES_DIGIT}, // 6
+}
+
+// New traversal direction when traversing along class II grids.
+//
+// Current digit . desired digit . new ijk direction
+var NEW_ADJUSTMENT_II = [7][7]Direction{
+	{CENTER_DIGIT, CENTER_DIGIT, CENTER_DIGIT, CENTER_DIGIT, CENTER_DIGIT, CENTER_DIGIT, CENTER_DIGIT},   // 0
+	{CENTER_DIGIT, K_AXES_DIGIT, CENTER_DIGIT, K_AXES_DIGIT, CENTER_DIGIT, IK_AXES_DIGIT, CENTER_DIGIT},  // 1
+	{CENTER_DIGIT, CENTER_DIGIT, J_AXES_DIGIT, JK_AXES_DIGIT, CENTER_DIGIT, CENTER_DIGIT, J_AXES_DIGIT},  // 2
+	{CENTER_DIGIT, K_AXES_DIGIT, JK_AXES_DIGIT, JK_AXES_DIGIT, CENTER_DIGIT, CENTER_DIGIT, CENTER_DIGIT}, // 3
+	{CENTER_DIGIT, CENTER_DIGIT, CENTER_DIGIT, CENTER_DIGIT, I_AXES_DIGIT, I_AXES_DIGIT, IJ_AXES_DIGIT},  // 4
+	{CENTER_DIGIT, IK_AXES_DIGIT, CENTER_DIGIT, CENTER_DIGIT, I_AXES_DIGIT, IK_AXES_DIGIT, CENTER_DIGIT}, // 5
+	{CENTER_DIGIT, CENTER_DIGIT, J_AXES_DIGIT, CENTER_DIGIT, IJ_AXES_DIGIT, CENTER_DIGIT, IJ_AXES_DIGIT}, // 6
+}
+
+// New digit when traversing along class III grids.
+//
+// Current digit . desired digit . new digit
+var NEW_DIGIT_III = [7][7]Direction{
+	{CENTER_DIGIT, K_AXES_DIGIT, J_AXES_DIGIT, JK_AXES_DIGIT, I_AXES_DIGIT, IK_AXES_DIGIT, IJ_AXES_DIGIT}, // 0
+	{K_AXES_DIGIT, J_AXES_DIGIT, JK_AXES_DIGIT, I_AXES_DIGIT, IK_AXES_DIGIT, IJ_AXES_DIGIT, CENTER_DIGIT}, // 1
+	{J_AXES_DIGIT, JK_AXES_DIGIT, I_AXES_DIGIT, IK_AXES_DIGIT, IJ_AXES_DIGIT, CENTER_DIGIT, K_AXES_DIGIT}, // 2
+	{JK_AXES_DIGIT, I_AXES_DIGIT, IK_AXES_DIGIT, IJ_AXES_DIGIT, CENTER_DIGIT, K_AXES_DIGIT, J_AXES_DIGIT}, // 3
+	{I_AXES_DIGIT, IK_AXES_DIGIT, IJ_AXES_DIGIT, CENTER_DIGIT, K_AXES_DIGIT, J_AXES_DIGIT, JK_AXES_DIGIT}, // 4
+	{IK_AXES_DIGIT, IJ_AXES_DIGIT, CENTER_DIGIT, K_AXES_DIGIT, J_AXES_DIGIT, JK_AXES_DIGIT, I_AXES_DIGIT}, // 5
+	{IJ_AXES_DIGIT, CENTER_DIGIT, K_AXES_DIGIT, J_AXES_DIGIT, JK_AXES_DIGIT, I_AXES_DIGIT, IK_AXES_DIGIT}, // 6
+}
+
+// New traversal direction when traversing along class III grids.
+//
+// Current digit . desired digit . new ijk direction
+var NEW_ADJUSTMENT_III = [7][7]Direction{
+	{CENTER_DIGIT, CENTER_DIGIT, CENTER_DIGIT, CENTER_DIGIT, CENTER_DIGIT, CENTER_DIGIT, CENTER_DIGIT},   // 0
+	{CENTER_DIGIT, K_AXES_DIGIT, CENTER_DIGIT, JK_AXES_DIGIT, CENTER_DIGIT, K_AXES_DIGIT, CENTER_DIGIT},  // 1
+	{CENTER_DIGIT, CENTER_DIGIT, J_AXES_DIGIT, J_AXES_DIGIT, CENTER_DIGIT, CENTER_DIGIT, IJ_AXES_DIGIT},  // 2
+	{CENTER_DIGIT, JK_AXES_DIGIT, J_AXES_DIGIT, JK_AXES_DIGIT, CENTER_DIGIT, CENTER_DIGIT, CENTER_DIGIT}, // 3
+	{CENTER_DIGIT, CENTER_DIGIT, CENTER_DIGIT, CENTER_DIGIT, I_AXES_DIGIT, IK_AXES_DIGIT, I_AXES_DIGIT},  // 4
+	{CENTER_DIGIT, K_AXES_DIGIT, CENTER_DIGIT, CENTER_DIGIT, IK_AXES_DIGIT, IK_AXES_DIGIT, CENTER_DIGIT}, // 5
+	{CENTER_DIGIT, CENTER_DIGIT, IJ_AXES_DIGIT, CENTER_DIGIT, I_AXES_DIGIT, CENTER_DIGIT, IJ_AXES_DIGIT}, // 6
+}
+
+// Return codes of the hexRange family of functions.
+const (
+	// HEX_RANGE_SUCCESS is returned when the traversal succeeded.
+	HEX_RANGE_SUCCESS = 0
+	// HEX_RANGE_PENTAGON is returned when a pentagon was encountered.
+	HEX_RANGE_PENTAGON = 1
+	// HEX_RANGE_K_SUBSEQUENCE is returned when pentagon distortion (the
+	// deleted k subsequence) was encountered.
+	HEX_RANGE_K_SUBSEQUENCE = 2
+)
+
+// maxKringSize returns the maximum number of indices that result from the
+// kRing algorithm with the given k. Formula source and proof:
+// https://oeis.org/A003215
+//
+// Return the maximum number of indices.
+func maxKringSize(k int) int {
+	return 3*k*(k+1) + 1
+}
+
+// kRing produces indices within k distance of the origin index.
+//
+// k-ring 0 is defined as the origin index, k-ring 1 is defined as k-ring 0 and
+// all neighboring indices, and so on.
+//
+// Output is placed in a slice of maxKringSize(k) elements, in no particular
+// order. Elements of the slice may be left zero, as can happen when crossing
+// a pentagon.
+func kRing(origin H3Index, k int) []H3Index {
+	out, _ := kRingDistances(origin, k)
+	return out
+}
+
+// kRingDistances produces indices within k distance of the origin index,
+// along with the distance of each index from the origin.
+//
+// k-ring 0 is defined as the origin index, k-ring 1 is defined as k-ring 0 and
+// all neighboring indices, and so on.
+//
+// Output is placed in slices of maxKringSize(k) elements, in no particular
+// order. Elements of the output slice may be left zero, as can happen when
+// crossing a pentagon.
+func kRingDistances(origin H3Index, k int) ([]H3Index, []int) {
+	maxIdx := maxKringSize(k)
+	out := make([]H3Index, maxIdx)
+	distances := make([]int, maxIdx)
+
+	// Optimistically try the faster hexRange algorithm first
+	if hexRangeDistances(origin, k, out, distances) != HEX_RANGE_SUCCESS {
+		// Fast algo failed, fall back to slower, correct algo
+		// and also wipe out array because contents untrustworthy
+		recordEvent(EventPentagonFallback, 1)
+		clear(out)
+		clear(distances)
+		_kRingInternal(origin, k, out, distances, maxIdx, 0)
+	}
+
+	return out, distances
+}
+
+// _kRingInternal is the internal recursive helper function for kRing.
+//
+// Adds the origin index to the output set (treating it as a hash set) and
+// recurses to its neighbors, if needed.
+func _kRingInternal(origin H3Index, k int, out []H3Index, distances []int, maxIdx int, curK int) {
+	if origin == 0 {
+		return
+	}
+
+	// Put origin in the output array. out is used as a hash set.
+	off := int(origin % H3Index(maxIdx))
+	for out[off] != 0 && out[off] != origin {
+		off = (off + 1) % maxIdx
+	}
+
+	// We either got a free slot in the hash set or hit a duplicate
+	// We might need to process the duplicate anyways because we got
+	// here on a longer path before.
+	if out[off] == origin && distances[off] <= curK {
+		return
+	}
+
+	out[off] = origin
+	distances[off] = curK
+
+	// Base case: reached an index k away from the origin.
+	if curK >= k {
+		return
+	}
+
+	// Recurse to all neighbors in no particular order.
+	for i := 0; i < 6; i++ {
+		rotations := 0
+		_kRingInternal(h3NeighborRotations(origin, DIRECTIONS[i], &rotations),
+			k, out, distances, maxIdx, curK+1)
+	}
+}
+
+// h3NeighborRotations returns the hexagon index neighboring the origin, in the
+// direction dir.
+//
+// Implementation note: The only reachable case where this returns 0 is if the
+// origin is a pentagon and the translation is in the k direction. Thus, 0 can
+// only be returned if origin is a pentagon.
+//
+// Return H3Index of the specified neighbor or H3_NULL if deleted k-subsequence
+// distortion is encountered.
+func h3NeighborRotations(origin H3Index, dir Direction, rotations *int) H3Index {
+	out := origin
+
+	for i := 0; i < *rotations; i++ {
+		dir = _rotate60ccw(dir)
+	}
+
+	newRotations := 0
+	oldBaseCell := H3_GET_BASE_CELL(out)
+	oldLeadingDigit := _h3LeadingNonZeroDigit(out)
+
+	// Adjust the indexing digits and, if needed, the base cell.
+	r := H3_GET_RESOLUTION(out) - 1
+	for {
+		if r == -1 {
+			H3_SET_BASE_CELL(&out, baseCellNeighbors[oldBaseCell][dir])
+			newRotations = baseCellNeighbor60CCWRots[oldBaseCell][dir]
+
+			if H3_GET_BASE_CELL(out) == INVALID_BASE_CELL {
+				// Adjust for the deleted k vertex at the base cell level.
+				// This edge actually borders a different neighbor.
+				H3_SET_BASE_CELL(&out, baseCellNeighbors[oldBaseCell][IK_AXES_DIGIT])
+				newRotations = baseCellNeighbor60CCWRots[oldBaseCell][IK_AXES_DIGIT]
+
+				// perform the adjustment for the k-subsequence we're skipping
+				// over.
+				out = _h3Rotate60ccw(out)
+				*rotations = *rotations + 1
+			}
+
+			break
+		}
+
+		oldDigit := H3_GET_INDEX_DIGIT(out, r+1)
+		var nextDir Direction
+		if oldDigit == INVALID_DIGIT {
+			// Only possible on invalid input
+			return H3_NULL
+		} else if isResClassIII(r + 1) {
+			H3_SET_INDEX_DIGIT(&out, r+1, NEW_DIGIT_II[oldDigit][dir])
+			nextDir = NEW_ADJUSTMENT_II[oldDigit][dir]
+		} else {
+			H3_SET_INDEX_DIGIT(&out, r+1, NEW_DIGIT_III[oldDigit][dir])
+			nextDir = NEW_ADJUSTMENT_III[oldDigit][dir]
+		}
+
+		if nextDir != CENTER_DIGIT {
+			dir = nextDir
+			r--
+		} else {
+			// No more adjustment to perform
+			break
+		}
+	}
+
+	newBaseCell := H3_GET_BASE_CELL(out)
+	if _isBaseCellPentagon(newBaseCell) {
+		alreadyAdjustedKSubsequence := false
+
+		// force rotation out of missing k-axes sub-sequence
+		if _h3LeadingNonZeroDigit(out) == K_AXES_DIGIT {
+			if oldBaseCell != newBaseCell {
+				// in this case, we traversed into the deleted
+				// k subsequence of a pentagon base cell.
+				// We need to rotate out of that case depending
+				// on how we got here.
+				// check for a cw/ccw offset face; default is ccw
+
+				if _baseCellIsCwOffset(newBaseCell, baseCellData[oldBaseCell].homeFijk.face) {
+					out = _h3Rotate60cw(out)
+				} else {
+					// See cwOffsetPent in testKRing.c for why this is
+					// unreachable.
+					out = _h3Rotate60ccw(out) // LCOV_EXCL_LINE
+				}
+				alreadyAdjustedKSubsequence = true
+			} else {
+				// In this case, we traversed into the deleted
+				// k subsequence from within the same pentagon
+				// base cell.
+				if oldLeadingDigit == CENTER_DIGIT {
+					// Undefined: the k direction is deleted from here
+					return H3_NULL
+				} else if oldLeadingDigit == JK_AXES_DIGIT {
+					// Rotate out of the deleted k subsequence
+					// We also need an additional change to the direction we're
+					// moving in
+					out = _h3Rotate60ccw(out)
+					*rotations = *rotations + 1
+				} else if oldLeadingDigit == IK_AXES_DIGIT {
+					// Rotate out of the deleted k subsequence
+					// We also need an additional change to the direction we're
+					// moving in
+					out = _h3Rotate60cw(out)
+					*rotations = *rotations + 5
+				} else {
+					// Should never occur
+					return H3_NULL // LCOV_EXCL_LINE
+				}
+			}
+		}
+
+		for i := 0; i < newRotations; i++ {
+			out = _h3RotatePent60ccw(out)
+		}
+
+		// Account for differing orientation of the base cells (this edge
+		// might not follow properties of some other edges.)
+		if oldBaseCell != newBaseCell {
+			if _isBaseCellPolarPentagon(newBaseCell) {
+				// 'polar' base cells behave differently because they have all
+				// i neighbors.
+				if oldBaseCell != 118 && oldBaseCell != 8 &&
+					_h3LeadingNonZeroDigit(out) != JK_AXES_DIGIT {
+					*rotations = *rotations + 1
+				}
+			} else if _h3LeadingNonZeroDigit(out) == IK_AXES_DIGIT &&
+				!alreadyAdjustedKSubsequence {
+				// account for distortion introduced to the 5 neighbor by the
+				// deleted k subsequence.
+				*rotations = *rotations + 1
+			}
+		}
+	} else {
+		for i := 0; i < newRotations; i++ {
+			out = _h3Rotate60ccw(out)
+		}
+	}
+
+	*rotations = (*rotations + newRotations) % 6
+
+	return out
+}
+
+// hexRangeDistances produces indexes within k distance of the origin index.
+// Output behavior is undefined when one of the indexes returned by this
+// function is a pentagon or is in the pentagon distortion area.
+//
+// k-ring 0 is defined as the origin index, k-ring 1 is defined as k-ring 0 and
+// all neighboring indexes, and so on.
+//
+// Output is placed in the provided slices in order of increasing distance from
+// the origin. The distances in hexagons is placed in the distances slice at
+// the same offset, if distances is not nil. Both slices must have room for
+// maxKringSize(k) elements.
+//
+// Return HEX_RANGE_SUCCESS, or HEX_RANGE_PENTAGON or HEX_RANGE_K_SUBSEQUENCE
+// if pentagonal distortion was encountered.
+func hexRangeDistances(origin H3Index, k int, out []H3Index, distances []int) int {
+	// k must be >= 0, so origin is always needed
+	idx := 0 // Current index for output
+	out[idx] = origin
+	if distances != nil {
+		distances[idx] = 0
+	}
+	idx++
+
+	if H3IsPentagon(origin) {
+		// Pentagon was encountered; bail out as user doesn't want this.
+		return HEX_RANGE_PENTAGON
+	}
+
+	// 0 < ring <= k, current ring
+	ring := 1
+	// 0 <= direction < 6, current side of the ring
+	direction := 0
+	// 0 <= i < ring, current position on the side of the ring
+	i := 0
+	// Number of 60 degree ccw rotations to perform on the direction (based on
+	// which faces have been crossed.)
+	rotations := 0
+
+	for ring <= k {
+		if direction == 0 && i == 0 {
+			// Not putting in the output set as it will be done later, at
+			// the end of this ring.
+			origin = h3NeighborRotations(origin, NEXT_RING_DIRECTION, &rotations)
+			if origin == 0 { // LCOV_EXCL_BR_LINE
+				// Should not be possible because `origin` would have to be a
+				// pentagon
+				return HEX_RANGE_K_SUBSEQUENCE // LCOV_EXCL_LINE
+			}
+
+			if H3IsPentagon(origin) {
+				// Pentagon was encountered; bail out as user doesn't want this.
+				return HEX_RANGE_PENTAGON
+			}
+		}
+
+		origin = h3NeighborRotations(origin, DIRECTIONS[direction], &rotations)
+		if origin == 0 { // LCOV_EXCL_BR_LINE
+			// Should not be possible because `origin` would have to be a
+			// pentagon
+			return HEX_RANGE_K_SUBSEQUENCE // LCOV_EXCL_LINE
+		}
+		out[idx] = origin
+		if distances != nil {
+			distances[idx] = ring
+		}
+		idx++
+
+		i++
+		// Check if end of this side of the k-ring
+		if i == ring {
+			i = 0
+			direction++
+			// Check if end of this ring.
+			if direction == 6 {
+				direction = 0
+				ring++
+			}
+		}
+
+		if H3IsPentagon(origin) {
+			// Pentagon was encountered; bail out as user doesn't want this.
+			return HEX_RANGE_PENTAGON
+		}
+	}
+	return HEX_RANGE_SUCCESS
+}
+
+// hexRange produces indexes within k distance of the origin index. Output
+// behavior is undefined when one of the indexes returned by this function is a
+// pentagon or is in the pentagon distortion area.
+//
+// k-ring 0 is defined as the origin index, k-ring 1 is defined as k-ring 0 and
+// all neighboring indexes, and so on.
+//
+// Output is placed in the provided slice in order of increasing distance from
+// the origin. The slice must have room for maxKringSize(k) elements.
+//
+// Return HEX_RANGE_SUCCESS, or HEX_RANGE_PENTAGON or HEX_RANGE_K_SUBSEQUENCE
+// if pentagonal distortion was encountered.
+func hexRange(origin H3Index, k int, out []H3Index) int {
+	return hexRangeDistances(origin, k, out, nil)
+}
+
+// hexRanges takes an array of input hex IDs and a max k-ring and returns an
+// array of hexagon IDs sorted first by the original hex IDs and then by the
+// k-ring (0 to max), with no guaranteed sorting within each k-ring group.
+//
+// The output slice must have room for len(h3Set) * maxKringSize(k) elements.
+//
+// Return HEX_RANGE_SUCCESS, or HEX_RANGE_PENTAGON or HEX_RANGE_K_SUBSEQUENCE
+// if pentagonal distortion was encountered.
+func hexRanges(h3Set []H3Index, k int, out []H3Index) int {
+	segmentSize := maxKringSize(k)
+	for i := range h3Set {
+		// Determine the appropriate segment of the output array to operate on
+		segment := out[i*segmentSize : (i+1)*segmentSize]
+		if success := hexRange(h3Set[i], k, segment); success != HEX_RANGE_SUCCESS {
+			return success
+		}
+	}
+	return HEX_RANGE_SUCCESS
+}
+
+// hexRing produces the hollow hexagonal ring centered at origin with sides of
+// length k.
+//
+// The output slice must have room for 6 * k elements, or 1 if k is 0.
+//
+// Return HEX_RANGE_SUCCESS, or HEX_RANGE_PENTAGON or HEX_RANGE_K_SUBSEQUENCE
+// if pentagonal distortion was encountered.
+func hexRing(origin H3Index, k int, out []H3Index) int {
+	// Short-circuit on 'identity' ring
+	if k == 0 {
+		out[0] = origin
+		return HEX_RANGE_SUCCESS
+	}
+	idx := 0
+	// Number of 60 degree ccw rotations to perform on the direction (based on
+	// which faces have been crossed.)
+	rotations := 0
+	// Scratch structure for checking for pentagons
+	if H3IsPentagon(origin) {
+		// Pentagon was encountered; bail out as user doesn't want this.
+		return HEX_RANGE_PENTAGON
+	}
+
+	for ring := 0; ring < k; ring++ {
+		origin = h3NeighborRotations(origin, NEXT_RING_DIRECTION, &rotations)
+		if origin == 0 { // LCOV_EXCL_BR_LINE
+			// Should not be possible because `origin` would have to be a
+			// pentagon
+			return HEX_RANGE_K_SUBSEQUENCE // LCOV_EXCL_LINE
+		}
+
+		if H3IsPentagon(origin) {
+			return HEX_RANGE_PENTAGON
+		}
+	}
+
+	lastIndex := origin
+
+	out[idx] = origin
+	idx++
+
+	for direction := 0; direction < 6; direction++ {
+		for pos := 0; pos < k; pos++ {
+			origin = h3NeighborRotations(origin, DIRECTIONS[direction], &rotations)
+			if origin == 0 { // LCOV_EXCL_BR_LINE
+				// Should not be possible because `origin` would have to be a
+				// pentagon
+				return HEX_RANGE_K_SUBSEQUENCE // LCOV_EXCL_LINE
+			}
+
+			// Skip the very last index, it was already added. We do
+			// however need to traverse to it because of the pentagonal
+			// distortion check, below.
+			if pos != k-1 || direction != 5 {
+				out[idx] = origin
+				idx++
+
+				if H3IsPentagon(origin) {
+					return HEX_RANGE_PENTAGON
+				}
+			}
+		}
+	}
+
+	// Check that this matches the expected lastIndex, if it doesn't,
+	// it indicates pentagonal distortion occurred and we should report
+	// failure.
+	if lastIndex != origin {
+		return HEX_RANGE_PENTAGON
+	}
+	return HEX_RANGE_SUCCESS
+}
+
+// ContainmentMode selects which cells PolygonToCellsWithMode considers to be
+// in a polygon.
+type ContainmentMode int
+
+const (
+	// Cells whose center is inside the polygon, as PolygonToCells.
+	CONTAINMENT_CENTER ContainmentMode = 0
+
+	// Cells entirely inside the polygon, touching none of its edges.
+	CONTAINMENT_FULL ContainmentMode = 1
+
+	// Cells overlapping the polygon, see CellIntersectsPolygon.
+	CONTAINMENT_OVERLAPPING ContainmentMode = 2
+
+	// Cells whose bounding box overlaps the polygon.
+	CONTAINMENT_OVERLAPPING_BBOX ContainmentMode = 3
+)
+
+// PolygonToCells produces the cells at res whose centers are contained by
+// poly, excluding those contained by one of its holes, in ascending order.
+//
+// It follows the reference algorithm: the cells along the edges of the
+// exterior boundary and of the holes seed a breadth-first search, which
+// moves to a neighbor whenever its center is inside the polygon. Edges are
+// straight lines in latitude and longitude, consistent with the
+// point-in-polygon test, and edges more than 180 degrees of longitude long
+// are taken to cross the antimeridian. Polygons containing a pole are not
+// supported.
+//
+// The options WithContainmentMode, WithMaxCells, WithParallelism and
+// WithContext change which cells are produced and how; without options the
+// cells are found by center on the calling goroutine.
+//
+// Return the cells, ErrInvalidResolution if res is out of range,
+// ErrInvalidGeoCoord if a vertex of the polygon is not finite,
+// ErrInvalidContainment if the containment mode is unknown,
+// ErrBudgetExceeded if there are more cells than allowed, or ctx.Err() if
+// the context was done.
+func PolygonToCells(poly GeoPolygon, res int, opts ...Option) ([]H3Index, error) {
+	o := newOptions(opts)
+	cells, err := polygonToCellsWithMode(poly, res, o.mode, o.polygonFill())
+	if err != nil {
+		return nil, err
+	}
+	return o.checkMaxCells(cells)
+}
+
+// polygonToCells is PolygonToCells without options, finding the cells by
+// center.
+func polygonToCells(poly GeoPolygon, res int) ([]H3Index, error) {
+	if res < 0 || res > MAX_H3_RES {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidResolution, res)
+	}
+	if poly.geofence.numVerts == 0 {
+		return nil, nil
+	}
+
+	bboxes := make([]BBox, poly.numHoles+1)
+	bboxesFromGeoPolygon(&poly, bboxes)
+
+	// 1. Trace the cells along the exterior boundary and the holes into the
+	// search set. They are only candidates, as their centers can lie on
+	// either side of the edges.
+	search := NewCellSet()
+	if err := _getEdgeHexagons(&poly.geofence, res, search); err != nil {
+		return nil, err
+	}
+	for i := 0; i < poly.numHoles; i++ {
+		if err := _getEdgeHexagons(&poly.holes[i], res, search); err != nil {
+			return nil, err
+		}
+	}
+
+	// 2. Keep the candidates whose centers are inside the polygon, which for
+	// a polygon smaller than a cell can be the only cells found.
+	found := NewCellSet()
+	frontier := search.Cells()
+	for _, cell := range frontier {
+		recordEvent(EventPolyfillCellVisited, 1)
+		var center GeoCoord
+		H3ToGeo(cell, &center)
+		if pointInsidePolygon(&poly, bboxes, &center) {
+			found.Add(cell)
+		}
+	}
+
+	// 3. Search outward from the candidates, keeping every neighbor whose
+	// center is inside the polygon and searching from it in turn, until no
+	// new cells are found.
+	var next, neighbors []H3Index
+	for len(frontier) > 0 {
+		next = next[:0]
+		for _, cell := range frontier {
+			neighbors = appendNeighbors(neighbors[:0], cell)
+			for _, neighbor := range neighbors {
+				if found.Has(neighbor) {
+					continue
+				}
+
+				recordEvent(EventPolyfillCellVisited, 1)
+				var center GeoCoord
+				H3ToGeo(neighbor, &center)
+				if pointInsidePolygon(&poly, bboxes, &center) {
+					found.Add(neighbor)
+					next = append(next, neighbor)
+				}
+			}
+		}
+		frontier, next = next, frontier
+	}
+
+	// 4. The found set is the output.
+	return found.Cells(), nil
+}
+
+// _getEdgeHexagons adds the cells at res along the edges of geofence to
+// search, sampling each edge at about the spacing of the cells.
+//
+// Return ErrInvalidGeoCoord if a vertex of geofence is not finite.
+func _getEdgeHexagons(geofence *Geofence, res int, search CellSet) error {
+	for i := 0; i < geofence.numVerts; i++ {
+		origin := geofence.verts[i]
+		destination := geofence.verts[(i+1)%geofence.numVerts]
+		if !isFinite(origin.lat) || !isFinite(origin.lon) {
+			return fmt.Errorf("%w: vertex %d", ErrInvalidGeoCoord, i)
+		}
+
+		// Interpolate across the antimeridian rather than around the globe.
+		dLon := destination.lon - origin.lon
+		if dLon > M_PI {
+			dLon -= M_2PI
+		} else if dLon < -M_PI {
+			dLon += M_2PI
+		}
+
+		numHexesEstimate := lineHexEstimate(&origin, &destination, res)
+		for j := 0; j < numHexesEstimate; j++ {
+			f := float64(j) / float64(numHexesEstimate)
+			interpolate := GeoCoord{
+				lat: origin.lat + (destination.lat-origin.lat)*f,
+				lon: constrainLng(origin.lon + dLon*f),
+			}
+			search.Add(GeoToH3(&interpolate, res))
+		}
+	}
+	return nil
+}
+
+// Resolution at which PolygonToCellsCompact starts refining, coarse enough
+// for few cells to overlap any polygon and fine enough for the cells to be
+// drawn reliably as straight-edged loops.
+const compactPolyfillStartRes = 2
+
+// PolygonToCellsCompact produces the compacted form of PolygonToCells: the
+// same area, as coarse cells in the interior of poly and cells at res along
+// its boundary, in ascending order.
+//
+// Rather than polyfilling at res and compacting afterwards, it refines the
+// cells overlapping the polygon from a coarse resolution down: a cell whose
+// neighborhood lies entirely inside the polygon is kept whole, one whose
+// neighborhood lies entirely outside is dropped, and only the rest are split
+// into children. The descendants of a cell stay within its neighborhood, so
+// the result is exactly Compact(PolygonToCells(poly, res)), while the memory
+// used grows with the length of the boundary rather than with the area.
+//
+// Return the cells, or the errors of PolygonToCells.
+func PolygonToCellsCompact(poly GeoPolygon, res int) ([]H3Index, error) {
+	if res <= compactPolyfillStartRes {
+		cells, err := PolygonToCells(poly, res)
+		if err != nil {
+			return nil, err
+		}
+		return Compact(cells)
+	}
+
+	seeds, err := compactPolyfillSeeds(&poly)
+	if err != nil || len(seeds) == 0 {
+		return nil, err
+	}
+
+	bboxes := make([]BBox, poly.numHoles+1)
+	bboxesFromGeoPolygon(&poly, bboxes)
+
+	var out []H3Index
+	stack := seeds
+	for len(stack) > 0 {
+		cell := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		keep, split := classifyPolyfillCell(cell, res, &poly, bboxes)
+		switch {
+		case split:
+			stack = AppendChildren(stack, cell, cell.GetResolution()+1)
+		case keep:
+			out = append(out, cell)
+		}
+	}
+
+	// Complete groups of siblings at res and groups of kept cells above the
+	// starting resolution are merged here.
+	out, err = normalizeCoverage(out)
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+// compactPolyfillSeeds returns the cells at compactPolyfillStartRes whose
+// descendants could have their centers inside poly, which are the cells
+// overlapping it and their neighbors, in ascending order.
+//
+// Return the cells, or the errors of PolygonToCells.
+func compactPolyfillSeeds(poly *GeoPolygon) ([]H3Index, error) {
+	overlapping, err := PolygonToCellsWithMode(*poly, compactPolyfillStartRes, CONTAINMENT_OVERLAPPING)
+	if err != nil {
+		return nil, err
+	}
+
+	seeds := NewCellSet(overlapping...)
+	var neighbors []H3Index
+	for _, cell := range overlapping {
+		neighbors = appendNeighbors(neighbors[:0], cell)
+		for _, neighbor := range neighbors {
+			seeds.Add(neighbor)
+		}
+	}
+	return seeds.Cells(), nil
+}
+
+// classifyPolyfillCell decides what becomes of cell, at res or coarser, when
+// poly, with its bounding boxes, is filled at res by refinement: at res it is
+// kept if its center is inside the polygon, and above it is kept whole or
+// dropped if its neighborhood is on one side of the boundary and split into
+// its children otherwise.
+//
+// Return whether to keep the cell, and whether to split it instead.
+func classifyPolyfillCell(cell H3Index, res int, poly *GeoPolygon, bboxes []BBox) (keep, split bool) {
+	recordEvent(EventPolyfillCellVisited, 1)
+	if cell.GetResolution() == res {
+		var center GeoCoord
+		H3ToGeo(cell, &center)
+		return pointInsidePolygon(poly, bboxes, &center), false
+	}
+
+	inside, uniform := polygonContainsNeighborhood(cell, poly, bboxes)
+	return inside && uniform, !uniform
+}
+
+// polygonContainsNeighborhood reports whether cell and its neighbors lie all
+// entirely inside poly, with its bounding boxes, or all entirely outside it.
+//
+// Return whether the neighborhood is inside, and whether it is uniformly so;
+// if it is not, it touches an edge of the polygon.
+func polygonContainsNeighborhood(cell H3Index, poly *GeoPolygon, bboxes []BBox) (inside, uniform bool) {
+	loops := make([]*Geofence, 0, poly.numHoles+1)
+	loopBBoxes := make([]*BBox, 0, poly.numHoles+1)
+	loops = append(loops, &poly.geofence)
+	loopBBoxes = append(loopBBoxes, &bboxes[0])
+	for i := 0; i < poly.numHoles; i++ {
+		if poly.holes[i].numVerts > 0 {
+			loops = append(loops, &poly.holes[i])
+			loopBBoxes = append(loopBBoxes, &bboxes[i+1])
+		}
+	}
+
+	var center GeoCoord
+	first := true
+	for _, g := range kRing(cell, 1) {
+		if g == H3_NULL {
+			continue
+		}
+
+		// A cell crossing no loop contains either all or none of the
+		// vertices of each loop, and is then on one side of the boundary.
+		fence, bbox := cellGeofence(g)
+		isTransmeridian := bboxIsTransmeridian(&bbox) || bboxIsTransmeridian(&bboxes[0])
+		for j, loop := range loops {
+			if bbox.north < loopBBoxes[j].south || bbox.south > loopBBoxes[j].north {
+				continue
+			}
+			if geofenceIntersectsGeofence(&fence, loop, isTransmeridian) ||
+				pointInsideGeofence(&fence, &bbox, &loop.verts[0]) {
+				return false, false
+			}
+		}
+
+		H3ToGeo(g, &center)
+		gInside := pointInsidePolygon(poly, bboxes, &center)
+		if !first && gInside != inside {
+			return false, false
+		}
+		inside, first = gInside, false
+	}
+	return inside, true
+}
+
+// PolygonToCellsWithMode produces the cells at res in poly according to
+// mode, in ascending order. The fully contained cells are among the cells by
+// center, which are among the overlapping cells, which are among the cells
+// overlapping by bounding box. Fully contained cells suit strict compliance
+// checks, while overlapping cells form a coverage guaranteed to include the
+// whole polygon. Edges are straight lines in latitude and longitude, as for
+// PolygonToCells.
+//
+// It is PolygonToCells with the WithContainmentMode option.
+//
+// Return the cells, ErrInvalidContainment if mode is unknown, or the errors
+// of PolygonToCells.
+func PolygonToCellsWithMode(poly GeoPolygon, res int, mode ContainmentMode) ([]H3Index, error) {
+	return PolygonToCells(poly, res, WithContainmentMode(mode))
+}
+
+// polygonToCellsWithMode produces the cells at res in poly according to
+// mode, finding the cells by center with fill.
+func polygonToCellsWithMode(poly GeoPolygon, res int, mode ContainmentMode,
+	fill func(GeoPolygon, int) ([]H3Index, error)) ([]H3Index, error) {
+	if mode < CONTAINMENT_CENTER || mode > CONTAINMENT_OVERLAPPING_BBOX {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidContainment, mode)
+	}
+	centers, err := fill(poly, res)
+	if err != nil || mode == CONTAINMENT_CENTER {
+		return centers, err
+	}
+
+	// A cell crossing no edge is either entirely inside the polygon or
+	// entirely outside it, depending on its center; a cell crossing an edge
+	// overlaps the polygon, holes being inside the exterior boundary.
+	edges, err := PolygonBoundaryCells(poly, res)
+	if err != nil {
+		return nil, err
+	}
+	crossing := NewCellSet(edges...)
+
+	if mode == CONTAINMENT_FULL {
+		out := centers[:0]
+		for _, cell := range centers {
+			if !crossing.Has(cell) {
+				out = append(out, cell)
+			}
+		}
+		return out, nil
+	}
+
+	overlapping := NewCellSet(centers...)
+	for _, cell := range edges {
+		overlapping.Add(cell)
+	}
+	if mode == CONTAINMENT_OVERLAPPING {
+		return overlapping.Cells(), nil
+	}
+
+	// The bounding box of a cell reaches into its neighbors only, so the
+	// cells whose bounding box overlaps the polygon are found by growing the
+	// overlapping cells.
+	bboxes := make([]BBox, poly.numHoles+1)
+	bboxesFromGeoPolygon(&poly, bboxes)
+	frontier := overlapping.Cells()
+	var next, neighbors []H3Index
+	for len(frontier) > 0 {
+		next = next[:0]
+		for _, cell := range frontier {
+			neighbors = appendNeighbors(neighbors[:0], cell)
+			for _, neighbor := range neighbors {
+				if overlapping.Has(neighbor) {
+					continue
+				}
+
+				recordEvent(EventPolyfillCellVisited, 1)
+				if cellBBoxIntersectsPolygon(neighbor, &poly, bboxes) {
+					overlapping.Add(neighbor)
+					next = append(next, neighbor)
+				}
+			}
+		}
+		frontier, next = next, frontier
+	}
+	return overlapping.Cells(), nil
+}
+
+// PolygonToCellsBuffered produces the cells at res whose centers are
+// contained by poly, as PolygonToCells does, together with every cell within
+// k grid steps of them, in ascending order. This covers the polygon with a
+// margin of about k cells, as for a service area with a safety buffer.
+//
+// Only the cells on the border of the fill are grown from, a ring at a
+// time, since any cell within k steps of the fill is within k steps of one of
+// them.
+//
+// Return the cells, ErrInvalidK if k is out of range, or the errors of
+// PolygonToCells.
+func PolygonToCellsBuffered(poly GeoPolygon, res int, k int) ([]H3Index, error) {
+	if _, err := MaxGridDiskSize(k); err != nil {
+		return nil, err
+	}
+	cells, err := PolygonToCells(poly, res)
+	if err != nil || k == 0 {
+		return cells, err
+	}
+
+	set := NewCellSet(cells...)
+	frontier := make([]H3Index, 0, len(cells))
+	for _, cell := range cells {
+		if hasNeighborOutside(set, cell) {
+			frontier = append(frontier, cell)
+		}
+	}
+
+	var next, neighbors []H3Index
+	for d := 1; d <= k && len(frontier) > 0; d++ {
+		next = next[:0]
+		for _, cell := range frontier {
+			neighbors = appendNeighbors(neighbors[:0], cell)
+			for _, neighbor := range neighbors {
+				if !set.Has(neighbor) {
+					set.Add(neighbor)
+					next = append(next, neighbor)
+				}
+			}
+		}
+		frontier, next = next, frontier
+	}
+	return set.Cells(), nil
+}
+
+// cellBBoxIntersectsPolygon reports whether the bounding box of cell and the
+// area of poly, with its bounding boxes, overlap.
+func cellBBoxIntersectsPolygon(cell H3Index, poly *GeoPolygon, bboxes []BBox) bool {
+	_, bbox := cellGeofence(cell)
+	fence := Geofence{numVerts: 4, verts: []GeoCoord{
+		{lat: bbox.north, lon: bbox.west},
+		{lat: bbox.north, lon: bbox.east},
+		{lat: bbox.south, lon: bbox.east},
+		{lat: bbox.south, lon: bbox.west},
+	}}
+	return cellIntersectsPolygon(&fence, &bbox, poly, bboxes)
+}
+
+// h3SetToVertexGraph creates a vertex graph from a set of hexagons of the
+// same resolution. It is the responsibility of the caller to call
+// destroyVertexGraph on the populated graph, otherwise the memory in the
+// graph nodes will not be freed.
+func h3SetToVertexGraph(h3Set []H3Index, graph *VertexGraph) {
+	if len(h3Set) < 1 {
+		// We still need to init the graph, or calls to destroyVertexGraph
+		// will fail
+		initVertexGraph(graph, 0, 0)
+		return
+	}
+
+	res := h3Set[0].GetResolution()
+	const minBuckets = 6
+	// TODO: Better way to calculate/guess?
+	numBuckets := max(len(h3Set), minBuckets)
+	initVertexGraph(graph, numBuckets, res)
+
+	// Iterate through every hexagon
+	var vertices GeoBoundary
+	for _, h3 := range h3Set {
+		H3ToGeoBoundary(h3, &vertices)
+		// iterate through every edge
+		for j := 0; j < vertices.numVerts; j++ {
+			fromVtx := &vertices.verts[j]
+			toVtx := &vertices.verts[(j+1)%vertices.numVerts]
+			// An icosahedron edge crossing a hexagon vertex can add a
+			// distortion vertex equal to it but for rounding, and the zero
+			// length edge to it has no match in the neighboring cell
+			if geoAlmostEqual(fromVtx, toVtx) {
+				continue
+			}
+			// If we've seen this edge already, it will be reversed
+			edge := findNodeForEdge(graph, toVtx, fromVtx)
+			if edge != nil {
+				// If we've seen it, drop it. No edge is shared by more than
+				// 2 hexagons, so we'll never see it again.
+				removeVertexNode(graph, edge)
+			} else {
+				// Add a new node for this edge
+				addVertexNode(graph, fromVtx, toVtx)
+			}
+		}
+	}
+}
+
+// _vertexGraphToLinkedGeo is an internal function for h3SetToLinkedGeo that
+// traces the edges of a vertex graph into loops, without normalizing them.
+// The graph is emptied in the process.
+func _vertexGraphToLinkedGeo(graph *VertexGraph, out *LinkedGeoPolygon) {
+	*out = LinkedGeoPolygon{}
+
+	// Find the next unused entry point
+	for edge := firstVertexNode(graph); edge != nil; edge = firstVertexNode(graph) {
+		loop := addNewLinkedLoop(out)
+		// Walk the graph to get the outline
+		for edge != nil {
+			addLinkedCoord(loop, &edge.from)
+			nextVtx := edge.to
+			removeVertexNode(graph, edge)
+			edge = findNodeForVertex(graph, &nextVtx)
+		}
+	}
+}
+
+// H3SetToLinkedGeo creates a linked set of geo loops from a set of hexagons
+// of the same resolution, without duplicates. The result is a multipolygon:
+// every polygon has one outer loop, counter-clockwise, followed by its
+// holes, clockwise.
+func H3SetToLinkedGeo(h3Set []H3Index, out *LinkedGeoPolygon) {
+	var graph VertexGraph
+	h3SetToVertexGraph(h3Set, &graph)
+	_vertexGraphToLinkedGeo(&graph, out)
+	// The return value, possibly indicating an error, is discarded, as by
+	// the reference implementation
+	normalizeMultiPolygon(out)
+	destroyVertexGraph(&graph)
+}
+
+// CellsToMultiPolygon outlines the area of a set of cells of the same
+// resolution as polygons with holes, the inverse of PolygonToCells. Each
+// polygon is a connected group of cells; its exterior boundary runs
+// counter-clockwise, and its holes, the areas it surrounds without covering,
+// run clockwise. Duplicates are ignored, and the result does not depend on
+// the order of cells. As for PolygonToCells, areas containing a pole are not
+// supported.
+//
+// Return the polygons, ErrInvalidCell if a cell is invalid, or
+// ErrResolutionMismatch if the cells are not all at the same resolution.
+func CellsToMultiPolygon(cells []H3Index) ([]GeoPolygon, error) {
+	set, err := outlineCellSet(cells)
+	if err != nil || len(set) == 0 {
+		return nil, err
+	}
+
+	var linked LinkedGeoPolygon
+	H3SetToLinkedGeo(set.Cells(), &linked)
+
+	out := make([]GeoPolygon, 0, countLinkedPolygons(&linked))
+	for polygon := &linked; polygon != nil; polygon = polygon.next {
+		if polygon.first != nil {
+			out = append(out, polygon.GeoPolygon())
+		}
+	}
+	return out, nil
+}
+
+// CellsToOutlines traces the outline of a set of cells of the same resolution
+// as loops of vertices, without grouping them into polygons as
+// CellsToMultiPolygon does, which is enough for drawing it. Outer loops run
+// counter-clockwise around the areas covered by the cells, and hole loops
+// run clockwise around the areas they surround without covering. The first
+// vertex of a loop is not repeated at its end. Duplicates are ignored, and
+// areas containing a pole are not supported.
+//
+// Return the outer and hole loops, ErrInvalidCell if a cell is invalid, or
+// ErrResolutionMismatch if the cells are not all at the same resolution.
+func CellsToOutlines(cells []H3Index) (outer, holes [][]GeoCoord, err error) {
+	set, err := outlineCellSet(cells)
+	if err != nil || len(set) == 0 {
+		return nil, nil, err
+	}
+
+	var graph VertexGraph
+	h3SetToVertexGraph(set.Cells(), &graph)
+	for edge := firstVertexNode(&graph); edge != nil; edge = firstVertexNode(&graph) {
+		var loop []GeoCoord
+		for edge != nil {
+			loop = append(loop, edge.from)
+			nextVtx := edge.to
+			removeVertexNode(&graph, edge)
+			edge = findNodeForVertex(&graph, &nextVtx)
+		}
+
+		fence := Geofence{numVerts: len(loop), verts: loop}
+		if isClockwiseGeofence(&fence) {
+			holes = append(holes, loop)
+		} else {
+			outer = append(outer, loop)
+		}
+	}
+	destroyVertexGraph(&graph)
+	return outer, holes, nil
+}
+
+// outlineCellSet collects cells to be outlined into a set.
+//
+// Return the set, ErrInvalidCell if a cell is invalid, or
+// ErrResolutionMismatch if the cells are not all at the same resolution.
+func outlineCellSet(cells []H3Index) (CellSet, error) {
+	set := NewCellSet()
+	for _, cell := range cells {
+		if !cell.IsValid() {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCell, cell)
+		}
+		if cell.GetResolution() != cells[0].GetResolution() {
+			return nil, fmt.Errorf("%w: %s and %s", ErrResolutionMismatch, cells[0], cell)
+		}
+		set.Add(cell)
+	}
+	return set, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// boxPolygon returns the polygon of the box with the given edges, in
+// degrees.
+func boxPolygon(north, south, east, west float64) GeoPolygon {
+	return NewGeoPolygon(NewGeofence([]GeoCoord{
+		NewGeoCoordDegs(north, west),
+		NewGeoCoordDegs(north, east),
+		NewGeoCoordDegs(south, east),
+		NewGeoCoordDegs(south, west),
+	}))
+}
+
+// boxAround returns a square polygon around the center of cell, frac edge
+// lengths of the cell on a side.
+func boxAround(cell H3Index, frac float64) GeoPolygon {
+	var center GeoCoord
+	H3ToGeo(cell, &center)
+	half := RadsToDegs(frac / 2 * EdgeLengthKm(cell.GetResolution()) / EARTH_RADIUS_KM)
+	lat, lon := RadsToDegs(center.lat), RadsToDegs(center.lon)
+	return boxPolygon(lat+half, lat-half, lon+half, lon-half)
+}
+
+func TestPolygonToCellsInsideOneCell(t *testing.T) {
+	origin := NewGeoCoordDegs(37.7749, -122.4194)
+	for res := 0; res <= MAX_H3_RES; res++ {
+		cell := GeoToH3(&origin, res)
+		want := []H3Index{cell}
+		got, err := PolygonToCells(boxAround(cell, 0.1), res)
+		if err != nil || !slices.Equal(got, want) {
+			t.Errorf("PolygonToCells at res %d = %v, %v, want %v", res, got, err, want)
+		}
+	}
+}
+
+func TestPolygonToCellsMatchesCenters(t *testing.T) {
+	// Every cell within reach of the box, kept when its center is inside.
+	tests := []struct {
+		north, south, east, west float64
+		res                      int
+	}{
+		{37.9, 37.6, -122.2, -122.6, 6},
+		{1, -1, 1, -1, 4},
+		{-16, -19, -179, 177, 3},
+	}
+	for _, tt := range tests {
+		poly := boxPolygon(tt.north, tt.south, tt.east, tt.west)
+		got, err := PolygonToCells(poly, tt.res)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		center := NewGeoCoordDegs((tt.north+tt.south)/2, (tt.east+tt.west)/2)
+		if tt.east < tt.west {
+			center = NewGeoCoordDegs((tt.north+tt.south)/2, (tt.east+tt.west)/2+180)
+		}
+		corner := NewGeoCoordDegs(tt.north, tt.west)
+		reach := arcLengthRads(&center, &corner) * EARTH_RADIUS_KM
+		k := int(math.Ceil(reach/(1.5*EdgeLengthKm(tt.res))*1.5)) + 2
+		disk, err := GridDisk(GeoToH3(&center, tt.res), k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bboxes := make([]BBox, 1)
+		bboxesFromGeoPolygon(&poly, bboxes)
+		var want []H3Index
+		for _, cell := range disk {
+			var c GeoCoord
+			H3ToGeo(cell, &c)
+			if pointInsidePolygon(&poly, bboxes, &c) {
+				want = append(want, cell)
+			}
+		}
+		slices.Sort(want)
+
+		if len(want) == 0 {
+			t.Fatalf("no cell center inside %+v", tt)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("PolygonToCells(%+v) gave %d cells, want %d", tt, len(got), len(want))
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import "iter"
+
+// AllCellsAtRes returns an iterator over every cell at the given resolution,
+// in increasing numeric order. Cells are produced one at a time by walking
+// the base cells and their descendants, so nothing is materialized; the
+// whole grid at resolution 15 has over 569 trillion cells.
+//
+// The iterator is empty if res is out of range.
+func AllCellsAtRes(res int) iter.Seq[H3Index] {
+	return func(yield func(H3Index) bool) {
+		if res < 0 || res > MAX_H3_RES {
+			return
+		}
+		for bc := 0; bc < NUM_BASE_CELLS; bc++ {
+			if !yieldBaseCellDescendants(bc, res, yield) {
+				return
+			}
+		}
+	}
+}
+
+// ChildIter returns an iterator over the children of h at childRes, in
+// increasing numeric order, skipping the deleted subsequence under
+// pentagons. Unlike H3ToChildren nothing is materialized, so enumerating the
+// 7^(childRes-res) children of a coarse cell takes constant memory.
+//
+// The iterator is empty if h is not a valid cell, or if childRes is coarser
+// than h or out of range.
+func ChildIter(h H3Index, childRes int) iter.Seq[H3Index] {
+	return func(yield func(H3Index) bool) {
+		if !H3IsValid(h) || !_isValidChildRes(H3_GET_RESOLUTION(h), childRes) {
+			return
+		}
+		yieldDescendants(h, childRes, yield)
+	}
+}
+
+// yieldBaseCellDescendants yields the descendants of base cell bc at
+// resolution res in increasing numeric order, skipping the deleted
+// subsequence of pentagons.
+//
+// Return false if yield asked to stop.
+func yieldBaseCellDescendants(bc int, res int, yield func(H3Index) bool) bool {
+	h := H3_INIT
+	H3_SET_MODE(&h, H3_HEXAGON_MODE)
+	H3_SET_BASE_CELL(&h, bc)
+	return yieldDescendants(h, res, yield)
+}
+
+// yieldDescendants yields the descendants of parent at resolution res in
+// increasing numeric order, skipping the deleted subsequence if parent is a
+// pentagon.
+//
+// Return false if yield asked to stop.
+func yieldDescendants(parent H3Index, res int, yield func(H3Index) bool) bool {
+	parentRes := H3_GET_RESOLUTION(parent)
+	h := parent
+	H3_SET_RESOLUTION(&h, res)
+	for r := parentRes + 1; r <= res; r++ {
+		H3_SET_INDEX_DIGIT(&h, r, CENTER_DIGIT)
+	}
+
+	isPentagon := H3IsPentagon(parent)
+	var digits [MAX_H3_RES + 1]Direction
+	for {
+		if !yield(h) {
+			return false
+		}
+
+		// advance the digits like an odometer, finest resolution first
+		r := res
+		for ; r > parentRes; r-- {
+			digits[r]++
+			if isPentagon && digits[r] == K_AXES_DIGIT && allCenterDigits(digits[parentRes+1:r]) {
+				// a leading K digit is the deleted subsequence of a pentagon
+				digits[r]++
+			}
+			if digits[r] < Direction(NUM_DIGITS) {
+				break
+			}
+			digits[r] = CENTER_DIGIT
+		}
+		if r == parentRes {
+			return true
+		}
+
+		for ; r <= res; r++ {
+			H3_SET_INDEX_DIGIT(&h, r, digits[r])
+		}
+	}
+}
+
+// allCenterDigits reports whether every digit of digits is CENTER_DIGIT.
+func allCenterDigits(digits []Direction) bool {
+	for _, d := range digits {
+		if d != CENTER_DIGIT {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/isbang/h3go"
+	"github.com/isbang/h3go/h3test"
+)
+
+func TestPolygonToCellsAntimeridianLoops(t *testing.T) {
+	const radiusRads = 0.02 // about 130 km
+
+	gen := h3test.New(1)
+	for i := 0; i < 20; i++ {
+		center := gen.AntimeridianCoord()
+		if math.Abs(center.LatRads()) > math.Pi/2-2*radiusRads {
+			continue
+		}
+		loop := gen.Loop(center, radiusRads, 12)
+		poly := h3go.NewGeoPolygon(h3go.NewGeofence(loop))
+
+		cells, err := h3go.PolygonToCells(poly, 6)
+		if err != nil {
+			t.Fatalf("PolygonToCells around %v: %v", center, err)
+		}
+
+		// The same ring written with longitudes running past 180 degrees
+		// must normalize back to the same cells.
+		unwrapped := make([]h3go.GeoCoord, len(loop))
+		for j, v := range loop {
+			lon := v.LonRads()
+			if lon < 0 {
+				lon += 2 * math.Pi
+			}
+			unwrapped[j] = h3go.NewGeoCoordRads(v.LatRads(), lon)
+		}
+		normalized := h3go.NewGeoPolygon(h3go.NormalizeGeofence(h3go.NewGeofence(unwrapped)))
+		again, err := h3go.PolygonToCells(normalized, 6)
+		if err != nil {
+			t.Fatalf("PolygonToCells of normalized ring around %v: %v", center, err)
+		}
+		if !h3go.SameCoverage(cells, again) {
+			t.Errorf("normalized ring around %v gave %d cells, want %d",
+				center, len(again), len(cells))
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import "math"
+
+// CellAzimuthDeg determines the initial great circle bearing from the center
+// of one cell to the center of another, measured clockwise from north.
+//
+// Return the bearing in degrees in [0, 360), 0 if the centers coincide, or
+// NaN if either cell is invalid.
+func CellAzimuthDeg(from, to H3Index) float64 {
+	if !H3IsValid(from) || !H3IsValid(to) {
+		return math.NaN()
+	}
+
+	var a, b GeoCoord
+	H3ToGeo(from, &a)
+	H3ToGeo(to, &b)
+	az := _posAngleRads(_geoAzimuthRads(&a, &b)) * M_180_PI
+	if az >= 360 {
+		// _posAngleRads can round up to a full turn
+		az = 0
+	}
+	return az
+}
@@ -14,6 +14,8 @@
 
 package h3go
 
+import "fmt"
+
 const (
 	INVALID_BASE_CELL = 127
 
@@ -942,3 +944,55 @@ func GetRes0Indexes() []H3Index {
 	}
 	return out
 }
+
+// validateBaseCell checks that bc is a base cell number.
+func validateBaseCell(bc int) error {
+	if bc < 0 || bc >= NUM_BASE_CELLS {
+		return fmt.Errorf("%w: %d", ErrInvalidBaseCell, bc)
+	}
+	return nil
+}
+
+// BaseCellNeighbor looks up the base cell neighboring bc in direction dir,
+// and the number of 60 degree ccw rotations from the coordinate system of bc
+// into that of the neighbor. CENTER_DIGIT yields bc itself.
+//
+// Return the neighbor and the rotations, ErrInvalidBaseCell if bc is out of
+// range, or ErrInvalidDirection if dir is not a direction or is the deleted K
+// axes direction of a pentagon.
+func BaseCellNeighbor(bc int, dir Direction) (int, int, error) {
+	if err := validateBaseCell(bc); err != nil {
+		return INVALID_BASE_CELL, 0, err
+	}
+	if dir < CENTER_DIGIT || dir >= Direction(NUM_DIGITS) {
+		return INVALID_BASE_CELL, 0, fmt.Errorf("%w: %d", ErrInvalidDirection, dir)
+	}
+
+	neighbor := _getBaseCellNeighbor(bc, dir)
+	if neighbor == INVALID_BASE_CELL {
+		return INVALID_BASE_CELL, 0, fmt.Errorf("%w: %d from pentagon base cell %d",
+			ErrInvalidDirection, dir, bc)
+	}
+	return neighbor, baseCellNeighbor60CCWRots[bc][dir], nil
+}
+
+// BaseCellIsPentagon returns whether or not the indicated base cell is a
+// pentagon. It returns false for numbers out of range.
+func BaseCellIsPentagon(bc int) bool {
+	return validateBaseCell(bc) == nil && _isBaseCellPentagon(bc)
+}
+
+// BaseCellHomeFaceIJK returns the home face of base cell bc and its
+// normalized resolution 0 ijk coordinates on that face.
+//
+// Return the face and coordinates, or ErrInvalidBaseCell if bc is out of
+// range.
+func BaseCellHomeFaceIJK(bc int) (face int, i, j, k int, err error) {
+	if err := validateBaseCell(bc); err != nil {
+		return 0, 0, 0, 0, err
+	}
+
+	var fijk FaceIJK
+	_baseCellToFaceIjk(bc, &fijk)
+	return fijk.face, fijk.coord.i, fijk.coord.j, fijk.coord.k, nil
+}
@@ -24,6 +24,20 @@ type BBox struct {
 	west  float64 // west longitude
 }
 
+// NewBBoxDegs returns a bounding box from the latitudes of its north and
+// south edges and the longitudes of its east and west edges in degrees. A box
+// whose east edge is west of its west edge crosses the antimeridian.
+func NewBBoxDegs(north, south, east, west float64) BBox {
+	return NewBBoxRads(DegsToRads(north), DegsToRads(south), DegsToRads(east), DegsToRads(west))
+}
+
+// NewBBoxRads returns a bounding box from the latitudes of its north and
+// south edges and the longitudes of its east and west edges in radians, see
+// NewBBoxDegs.
+func NewBBoxRads(north, south, east, west float64) BBox {
+	return BBox{north: north, south: south, east: east, west: west}
+}
+
 // bboxIsTransmeridian returns whether the given bounding box crosses the
 // antimeridian
 func bboxIsTransmeridian(bbox *BBox) bool {
@@ -41,6 +55,21 @@ func bboxCenter(bbox *BBox, center *GeoCoord) {
 	center.lon = constrainLng((east + bbox.west) / 2.0)
 }
 
+// bboxWidthRads returns the width of a bounding box in radians, measured
+// eastward from its west edge, so that it is positive across the
+// antimeridian too.
+func bboxWidthRads(bbox *BBox) float64 {
+	if bboxIsTransmeridian(bbox) {
+		return bbox.east - bbox.west + M_2PI
+	}
+	return bbox.east - bbox.west
+}
+
+// bboxHeightRads returns the height of a bounding box in radians.
+func bboxHeightRads(bbox *BBox) float64 {
+	return bbox.north - bbox.south
+}
+
 // bboxContains returns whether the bounding box contains a given point
 func bboxContains(bbox *BBox, point *GeoCoord) bool {
 	if bboxIsTransmeridian(bbox) {
@@ -91,7 +120,9 @@ func bboxHexEstimate(bbox *BBox, res int) int {
 	d := PointDistKm(&p1, &p2)
 	// Derived constant based on: https://math.stackexchange.com/a/1921940
 	// Clamped to 3 as higher values tend to rapidly drag the estimate to zero.
-	a := d * d / math.Min(3.0, math.Abs((p1.lon-p2.lon)/(p1.lat-p2.lat)))
+	// The width is taken across the antimeridian for transmeridian boxes,
+	// whose corner longitudes are almost a full turn apart.
+	a := d * d / math.Min(3.0, math.Abs(bboxWidthRads(bbox)/bboxHeightRads(bbox)))
 
 	// Divide the two to get an estimate of the number of hexagons needed
 	estimate := int(math.Ceil(a / pentagonAreaKm2))
@@ -109,7 +140,8 @@ func lineHexEstimate(origin *GeoCoord, destination *GeoCoord, res int) int {
 	GetPentagonIndexes(res, &pentagons)
 	pentagonRadiusKm := _hexRadiusKm(pentagons[0])
 
-	dist := PointDistKm(origin, destination)
+	// unlike PointDistKm, arcLengthRads is defined for antipodal points
+	dist := arcLengthRads(origin, destination) * EARTH_RADIUS_KM
 	estimate := int(math.Ceil(dist / (2 * pentagonRadiusKm)))
 	if estimate == 0 {
 		estimate = 1
@@ -0,0 +1,185 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"fmt"
+	"iter"
+	"math"
+	"slices"
+)
+
+// CellsInBBox produces the cells at res whose centers are inside bbox, in
+// ascending order, as PolygonToCells does for the rectangle it bounds. Its
+// edges are meridians and parallels, straight in latitude and longitude.
+// Boxes crossing the antimeridian and boxes wider than 180 degrees of
+// longitude are both supported.
+//
+// Return the cells, ErrInvalidResolution if res is out of range, or
+// ErrInvalidGeoCoord if an edge of bbox is not finite or out of range, or its
+// south edge is north of its north edge.
+func CellsInBBox(bbox BBox, res int) ([]H3Index, error) {
+	if res < 0 || res > MAX_H3_RES {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidResolution, res)
+	}
+	pieces, err := bboxPolygons(&bbox)
+	if err != nil || len(pieces) == 0 {
+		return nil, err
+	}
+	if len(pieces) == 1 {
+		return PolygonToCells(pieces[0], res)
+	}
+
+	// The pieces share edges, on which a center falls to the western piece
+	// only, see pointInsideGeofence, so the cells need no deduplication.
+	var out []H3Index
+	for _, piece := range pieces {
+		cells, err := PolygonToCells(piece, res)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, cells...)
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+// CountCellsInBBox counts the cells at res whose centers are inside bbox,
+// the cells CellsInBBox produces, to preallocate for them or to hold them to
+// a quota. Unlike the estimate of MaxPolygonToCellsSize the count is exact.
+//
+// The box is filled with the largest cells that fit, as by
+// PolygonToCellsCompact, whose descendants at res are counted without being
+// produced, so the cost grows with the perimeter of the box rather than with
+// its area.
+//
+// Return the count, or the errors of CellsInBBox.
+func CountCellsInBBox(bbox BBox, res int) (int, error) {
+	if res < 0 || res > MAX_H3_RES {
+		return 0, fmt.Errorf("%w: %d", ErrInvalidResolution, res)
+	}
+	pieces, err := bboxPolygons(&bbox)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, piece := range pieces {
+		cells, err := PolygonToCellsCompact(piece, res)
+		if err != nil {
+			return 0, err
+		}
+		for _, cell := range cells {
+			count += childrenSize(cell, res)
+		}
+	}
+	return count, nil
+}
+
+// GraticuleCells returns an iterator over the tiles of a graticule of
+// squares stepDegs degrees on a side covering the globe, each with the cells
+// at res whose centers are inside it, as CellsInBBox produces them. Tiles
+// are keyed by their south-west corner and visited west to east in rows from
+// south to north; if stepDegs does not divide the globe evenly, the last row
+// and column are narrower. Each tile is filled only when reached, so a global
+// job can be chunked by tile without holding more than one in memory, and
+// stopping the iteration stops the work.
+//
+// Every cell is in exactly one tile: a center on a shared meridian goes to
+// the western tile, see CellsInBBox, and one on a shared parallel to the
+// northern tile.
+//
+// The iterator is empty if stepDegs is not positive and finite, or if res is
+// out of range.
+func GraticuleCells(stepDegs float64, res int) iter.Seq2[GeoCoord, []H3Index] {
+	return func(yield func(GeoCoord, []H3Index) bool) {
+		if !(stepDegs > 0) || math.IsInf(stepDegs, 1) || res < 0 || res > MAX_H3_RES {
+			return
+		}
+		// Tolerate rounding in the division, as for a step of 0.1
+		numRows := int(math.Ceil(180/stepDegs - 1e-9))
+		numCols := int(math.Ceil(360/stepDegs - 1e-9))
+
+		for row := 0; row < numRows; row++ {
+			south := -90 + float64(row)*stepDegs
+			north := math.Min(south+stepDegs, 90)
+			northRads := DegsToRads(north)
+			if row < numRows-1 {
+				// Leave the shared parallel to the tile north of this one.
+				northRads = math.Nextafter(northRads, math.Inf(-1))
+			}
+
+			for col := 0; col < numCols; col++ {
+				west := -180 + float64(col)*stepDegs
+				east := math.Min(west+stepDegs, 180)
+				bbox := NewBBoxRads(northRads, DegsToRads(south), DegsToRads(east), DegsToRads(west))
+				cells, err := CellsInBBox(bbox, res)
+				if err != nil {
+					// Not reached, the box being valid
+					return
+				}
+				if !yield(NewGeoCoordDegs(south, west), cells) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// bboxPolygons returns the rectangle bounded by bbox as polygons, splitting
+// it into pieces at most 90 degrees of longitude wide so that no edge is
+// taken to cross the antimeridian when it does not.
+//
+// Return the polygons, none if bbox is empty, or ErrInvalidGeoCoord if it is
+// invalid.
+func bboxPolygons(bbox *BBox) ([]GeoPolygon, error) {
+	for _, v := range [...]float64{bbox.north, bbox.south, bbox.east, bbox.west} {
+		if !isFinite(v) {
+			return nil, fmt.Errorf("%w: bounding box edge %v", ErrInvalidGeoCoord, v)
+		}
+	}
+	if math.Abs(bbox.north) > M_PI_2 || math.Abs(bbox.south) > M_PI_2 {
+		return nil, fmt.Errorf("%w: bounding box latitudes %v and %v",
+			ErrInvalidGeoCoord, bbox.north, bbox.south)
+	}
+	if math.Abs(bbox.east) > M_PI || math.Abs(bbox.west) > M_PI {
+		return nil, fmt.Errorf("%w: bounding box longitudes %v and %v",
+			ErrInvalidGeoCoord, bbox.east, bbox.west)
+	}
+	if bbox.south > bbox.north {
+		return nil, fmt.Errorf("%w: bounding box south %v above north %v",
+			ErrInvalidGeoCoord, bbox.south, bbox.north)
+	}
+
+	width := bboxWidthRads(bbox)
+	if width == 0 || bbox.north == bbox.south {
+		return nil, nil
+	}
+
+	n := int(math.Ceil(width / M_PI_2))
+	pieces := make([]GeoPolygon, n)
+	for i := range pieces {
+		west := constrainLng(bbox.west + width*float64(i)/float64(n))
+		east := constrainLng(bbox.west + width*float64(i+1)/float64(n))
+		verts := []GeoCoord{
+			{lat: bbox.south, lon: west},
+			{lat: bbox.south, lon: east},
+			{lat: bbox.north, lon: east},
+			{lat: bbox.north, lon: west},
+		}
+		pieces[i] = GeoPolygon{geofence: Geofence{numVerts: len(verts), verts: verts}}
+	}
+	return pieces, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGraticuleCells(t *testing.T) {
+	// Steps dividing the globe evenly, and one leaving narrower tiles along
+	// the north and east edges.
+	for _, tt := range []struct {
+		stepDegs float64
+		res      int
+	}{
+		{90, 0}, {30, 1}, {50, 1}, {45, 2}, {50, 2},
+	} {
+		seen := NewCellSet()
+		tiles := 0
+		for corner, cells := range GraticuleCells(tt.stepDegs, tt.res) {
+			tiles++
+			south, west := RadsToDegs(corner.lat), RadsToDegs(corner.lon)
+			for _, cell := range cells {
+				if seen.Has(cell) {
+					t.Fatalf("GraticuleCells(%v, %d) yields %s twice", tt.stepDegs, tt.res, cell)
+				}
+				seen.Add(cell)
+
+				var center GeoCoord
+				H3ToGeo(cell, &center)
+				lat, lon := RadsToDegs(center.lat), RadsToDegs(center.lon)
+				if lat < south-1e-9 || lat > south+tt.stepDegs+1e-9 ||
+					lon < west-1e-9 || lon > west+tt.stepDegs+1e-9 {
+					t.Fatalf("GraticuleCells(%v, %d) yields %s centered at %v, %v in the tile at %v, %v",
+						tt.stepDegs, tt.res, cell, lat, lon, south, west)
+				}
+			}
+		}
+
+		wantTiles := int(math.Ceil(180/tt.stepDegs) * math.Ceil(360/tt.stepDegs))
+		if tiles != wantTiles {
+			t.Errorf("GraticuleCells(%v, %d) yields %d tiles, want %d", tt.stepDegs, tt.res, tiles, wantTiles)
+		}
+		if int64(seen.Len()) != NumHexagons(tt.res) {
+			t.Errorf("GraticuleCells(%v, %d) yields %d cells, want %d", tt.stepDegs, tt.res, seen.Len(), NumHexagons(tt.res))
+		}
+	}
+}
+
+func TestGraticuleCellsStop(t *testing.T) {
+	tiles := 0
+	for range GraticuleCells(10, 0) {
+		tiles++
+		if tiles == 3 {
+			break
+		}
+	}
+	if tiles != 3 {
+		t.Errorf("GraticuleCells went on for %d tiles after stopping at 3", tiles)
+	}
+
+	for _, tt := range []struct {
+		stepDegs float64
+		res      int
+	}{
+		{0, 0}, {-10, 0}, {math.Inf(1), 0}, {math.NaN(), 0}, {10, -1}, {10, MAX_H3_RES + 1},
+	} {
+		for range GraticuleCells(tt.stepDegs, tt.res) {
+			t.Errorf("GraticuleCells(%v, %d) yields tiles", tt.stepDegs, tt.res)
+			break
+		}
+	}
+}
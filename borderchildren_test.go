@@ -0,0 +1,80 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// naiveBorderingChildren returns the neighbors outside cell of all of its
+// descendants at childRes.
+func naiveBorderingChildren(cell H3Index, childRes int) []H3Index {
+	res := H3_GET_RESOLUTION(cell)
+	var children, neighbors []H3Index
+	H3ToChildren(cell, childRes, &children)
+	found := NewCellSet()
+	for _, child := range children {
+		neighbors = appendNeighbors(neighbors[:0], child)
+		for _, neighbor := range neighbors {
+			if H3ToParent(neighbor, res) != cell {
+				found.Add(neighbor)
+			}
+		}
+	}
+	return found.Cells()
+}
+
+func TestBorderingChildren(t *testing.T) {
+	cells := make([]H3Index, PentagonIndexCount())
+	GetPentagonIndexes(2, &cells)
+	rng := rand.New(rand.NewPCG(5, 6))
+	for range 20 {
+		g := GeoCoord{
+			lat: DegsToRads(rng.Float64()*180 - 90),
+			lon: DegsToRads(rng.Float64()*360 - 180),
+		}
+		cells = append(cells, GeoToH3(&g, 2))
+	}
+	// a hexagon next to a pentagon
+	neighbors := appendNeighbors(nil, cells[0])
+	cells = append(cells, neighbors[len(neighbors)-1])
+
+	for _, cell := range cells {
+		for childRes := 2; childRes <= 5; childRes++ {
+			got, err := BorderingChildren(cell, childRes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := naiveBorderingChildren(cell, childRes); !slices.Equal(got, want) {
+				t.Fatalf("BorderingChildren(%s, %d) = %d cells, want %d", cell, childRes, len(got), len(want))
+			}
+		}
+	}
+}
+
+func TestBorderingChildrenErrors(t *testing.T) {
+	cell := H3Index(0x8528342bfffffff)
+	if _, err := BorderingChildren(H3Index(0x7fffffffffffffff), 6); !errors.Is(err, ErrInvalidCell) {
+		t.Errorf("BorderingChildren of an invalid cell error = %v, want %v", err, ErrInvalidCell)
+	}
+	for _, childRes := range []int{4, MAX_H3_RES + 1} {
+		if _, err := BorderingChildren(cell, childRes); !errors.Is(err, ErrInvalidResolution) {
+			t.Errorf("BorderingChildren(%s, %d) error = %v, want %v", cell, childRes, err, ErrInvalidResolution)
+		}
+	}
+}
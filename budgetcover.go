@@ -0,0 +1,135 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// BudgetCover approximates the area of cells, all at the same resolution,
+// with at most maxCells cells of mixed resolutions.
+//
+// It starts from the compacted cells, which cover the area exactly, and
+// replaces groups of cells by a common ancestor, which also covers some
+// cells at the input resolution that are not in the area. The error of an
+// ancestor is the fraction of its descendants at the input resolution that
+// are not in the area, counted exactly under pentagons. Ancestors are taken
+// in order of increasing error, finer ones first among equal errors, until
+// the cover fits in maxCells; no ancestor with an error above tolerance is
+// taken. The result is sorted in ascending order, and H3_NULL entries and
+// duplicates in cells are ignored.
+//
+// Return ErrInvalidTolerance if tolerance is not between 0 and 1,
+// ErrInvalidCell or ErrResolutionMismatch as Compact does, or
+// ErrBudgetExceeded if the area cannot be covered by maxCells cells within
+// tolerance.
+func BudgetCover(cells []H3Index, maxCells int, tolerance float64) ([]H3Index, error) {
+	if !(tolerance >= 0 && tolerance <= 1) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTolerance, tolerance)
+	}
+
+	fine := NewCellSet(cells...).Cells()
+	exact, err := Compact(fine)
+	if err != nil {
+		return nil, err
+	}
+	if len(exact) == 0 || len(exact) <= maxCells {
+		return exact, nil
+	}
+	fineRes := H3_GET_RESOLUTION(fine[0])
+
+	// covered counts the cells of the area under each ancestor, and under
+	// the cells of the current cover under each ancestor.
+	covered := make(map[H3Index]int)
+	for _, cell := range fine {
+		for r := 0; r < fineRes; r++ {
+			covered[H3ToParent(cell, r)]++
+		}
+	}
+	under := make(map[H3Index]int)
+	for _, cell := range exact {
+		for r := 0; r < H3_GET_RESOLUTION(cell); r++ {
+			under[H3ToParent(cell, r)]++
+		}
+	}
+
+	type candidate struct {
+		cell H3Index
+		err  float64
+	}
+	candidates := make([]candidate, 0, len(under))
+	for cell := range under {
+		total := childrenSize(cell, fineRes)
+		candidates = append(candidates, candidate{
+			cell: cell,
+			err:  float64(total-covered[cell]) / float64(total),
+		})
+	}
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		if c := cmp.Compare(a.err, b.err); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(H3_GET_RESOLUTION(b.cell), H3_GET_RESOLUTION(a.cell)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.cell, b.cell)
+	})
+
+	count := len(exact)
+	merged := NewCellSet()
+	for _, c := range candidates {
+		if count <= maxCells || c.err > tolerance {
+			break
+		}
+		n := under[c.cell]
+		if n <= 1 || hasAncestorIn(merged, c.cell) {
+			continue
+		}
+		merged.Add(c.cell)
+		count -= n - 1
+		for r := 0; r < H3_GET_RESOLUTION(c.cell); r++ {
+			under[H3ToParent(c.cell, r)] -= n - 1
+		}
+	}
+	if count > maxCells {
+		return nil, fmt.Errorf("%w: %d cells needed for %d", ErrBudgetExceeded, count, maxCells)
+	}
+
+	out := make([]H3Index, 0, count)
+	for _, cell := range exact {
+		if !hasAncestorIn(merged, cell) {
+			out = append(out, cell)
+		}
+	}
+	for cell := range merged {
+		if !hasAncestorIn(merged, cell) {
+			out = append(out, cell)
+		}
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+// hasAncestorIn reports whether set holds a strict ancestor of cell.
+func hasAncestorIn(set CellSet, cell H3Index) bool {
+	for ancestor := range cell.Ancestors() {
+		if set.Has(ancestor) {
+			return true
+		}
+	}
+	return false
+}
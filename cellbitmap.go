@@ -0,0 +1,259 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"fmt"
+	"iter"
+	"math/bits"
+	"slices"
+)
+
+// Number of resolutions between a CellBitmap block and its cells.
+const CELL_BITMAP_BLOCK_DEPTH = 4
+
+// Largest number of cells a block stores as a sorted array; above it the
+// block switches to a bitmap, which then takes less space.
+const cellBitmapArrayMax = 150
+
+// CellBitmap is a compressed set of cells at a single resolution, intended
+// for coverages far too large for a CellSet or a []H3Index, such as a
+// country at resolution 9 or finer.
+//
+// Cells are grouped in blocks by their ancestor CELL_BITMAP_BLOCK_DEPTH
+// resolutions up, which has up to 2401 descendants at the resolution of the
+// set.
+// Like a roaring bitmap, a block holding few cells keeps them as a sorted
+// array of 16 bit offsets, and a block holding more keeps a 2401 bit bitmap,
+// so a densely covered area costs about one bit per cell.
+type CellBitmap struct {
+	res    int                      // resolution of the cells
+	keyRes int                      // resolution of the block keys
+	width  int                      // number of offsets in a block
+	blocks map[H3Index]*bitmapBlock // blocks by ancestor at keyRes
+	n      int                      // number of cells
+}
+
+// bitmapBlock holds the cells of a CellBitmap under one ancestor, as offsets
+// formed by their digits below the ancestor.
+type bitmapBlock struct {
+	offsets []uint16 // sorted offsets while the block is sparse
+	words   []uint64 // bitmap of offsets once the block is dense
+	n       int      // number of offsets
+}
+
+// NewCellBitmap returns an empty set of cells at res.
+//
+// Return the set, or ErrInvalidResolution if res is out of range.
+func NewCellBitmap(res int) (*CellBitmap, error) {
+	if res < 0 || res > MAX_H3_RES {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidResolution, res)
+	}
+	keyRes := max(res-CELL_BITMAP_BLOCK_DEPTH, 0)
+	return &CellBitmap{
+		res:    res,
+		keyRes: keyRes,
+		width:  _ipow(7, res-keyRes),
+		blocks: make(map[H3Index]*bitmapBlock),
+	}, nil
+}
+
+// Resolution returns the resolution of the cells of the set.
+func (b *CellBitmap) Resolution() int {
+	return b.res
+}
+
+// Len returns the number of cells in the set.
+func (b *CellBitmap) Len() int {
+	return b.n
+}
+
+// Add adds cell to the set.
+//
+// Return ErrInvalidCell if cell is not a valid cell, or
+// ErrResolutionMismatch if it is not at the resolution of the set.
+func (b *CellBitmap) Add(cell H3Index) error {
+	if !H3IsValid(cell) {
+		return fmt.Errorf("%w: %s", ErrInvalidCell, cell)
+	}
+	if H3_GET_RESOLUTION(cell) != b.res {
+		return fmt.Errorf("%w: %d and %d", ErrResolutionMismatch, H3_GET_RESOLUTION(cell), b.res)
+	}
+
+	key, off := b.split(cell)
+	block := b.blocks[key]
+	if block == nil {
+		block = &bitmapBlock{}
+		b.blocks[key] = block
+	}
+	if block.add(off, b.width) {
+		b.n++
+	}
+	return nil
+}
+
+// Remove removes cell from the set. Invalid cells are never in the set, so
+// removing one does nothing.
+func (b *CellBitmap) Remove(cell H3Index) {
+	if !H3IsValid(cell) || H3_GET_RESOLUTION(cell) != b.res {
+		return
+	}
+	key, off := b.split(cell)
+	block := b.blocks[key]
+	if block == nil || !block.remove(off) {
+		return
+	}
+	b.n--
+	if block.n == 0 {
+		delete(b.blocks, key)
+	}
+}
+
+// Has reports whether cell is in the set.
+func (b *CellBitmap) Has(cell H3Index) bool {
+	if !H3IsValid(cell) || H3_GET_RESOLUTION(cell) != b.res {
+		return false
+	}
+	key, off := b.split(cell)
+	block := b.blocks[key]
+	return block != nil && block.has(off)
+}
+
+// All returns an iterator over the cells of the set in ascending order.
+func (b *CellBitmap) All() iter.Seq[H3Index] {
+	return func(yield func(H3Index) bool) {
+		keys := make([]H3Index, 0, len(b.blocks))
+		for key := range b.blocks {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			block := b.blocks[key]
+			if block.words == nil {
+				for _, off := range block.offsets {
+					if !yield(b.join(key, off)) {
+						return
+					}
+				}
+				continue
+			}
+			for i, word := range block.words {
+				for word != 0 {
+					off := uint16(i*64 + bits.TrailingZeros64(word))
+					word &= word - 1
+					if !yield(b.join(key, off)) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// Cells returns the cells of the set in ascending order.
+func (b *CellBitmap) Cells() []H3Index {
+	out := make([]H3Index, 0, b.n)
+	for cell := range b.All() {
+		out = append(out, cell)
+	}
+	return out
+}
+
+// split returns the block key of the valid cell and its offset within the
+// block. The offset reads the digits below the key as a base 7 number, so
+// offsets ascend with the cells.
+func (b *CellBitmap) split(cell H3Index) (key H3Index, off uint16) {
+	key = H3ToParent(cell, b.keyRes)
+	for r := b.keyRes + 1; r <= b.res; r++ {
+		off = off*7 + uint16(H3_GET_INDEX_DIGIT(cell, r))
+	}
+	return key, off
+}
+
+// join returns the cell at offset off within the block with key key.
+func (b *CellBitmap) join(key H3Index, off uint16) H3Index {
+	cell := key
+	H3_SET_RESOLUTION(&cell, b.res)
+	for r := b.res; r > b.keyRes; r-- {
+		H3_SET_INDEX_DIGIT(&cell, r, Direction(off%7))
+		off /= 7
+	}
+	return cell
+}
+
+// add adds off to the block, switching to a bitmap of width offsets when the
+// array grows too large.
+//
+// Return whether off was not in the block yet.
+func (c *bitmapBlock) add(off uint16, width int) bool {
+	if c.words != nil {
+		word, bit := off/64, uint64(1)<<(off%64)
+		if c.words[word]&bit != 0 {
+			return false
+		}
+		c.words[word] |= bit
+		c.n++
+		return true
+	}
+
+	i, found := slices.BinarySearch(c.offsets, off)
+	if found {
+		return false
+	}
+	c.offsets = slices.Insert(c.offsets, i, off)
+	c.n++
+
+	if c.n > cellBitmapArrayMax {
+		c.words = make([]uint64, (width+63)/64)
+		for _, o := range c.offsets {
+			c.words[o/64] |= 1 << (o % 64)
+		}
+		c.offsets = nil
+	}
+	return true
+}
+
+// remove removes off from the block.
+//
+// Return whether off was in the block.
+func (c *bitmapBlock) remove(off uint16) bool {
+	if c.words != nil {
+		word, bit := off/64, uint64(1)<<(off%64)
+		if c.words[word]&bit == 0 {
+			return false
+		}
+		c.words[word] &^= bit
+		c.n--
+		return true
+	}
+
+	i, found := slices.BinarySearch(c.offsets, off)
+	if !found {
+		return false
+	}
+	c.offsets = slices.Delete(c.offsets, i, i+1)
+	c.n--
+	return true
+}
+
+// has reports whether off is in the block.
+func (c *bitmapBlock) has(off uint16) bool {
+	if c.words != nil {
+		return c.words[off/64]&(1<<(off%64)) != 0
+	}
+	_, found := slices.BinarySearch(c.offsets, off)
+	return found
+}
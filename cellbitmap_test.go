@@ -0,0 +1,133 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestCellBitmap(t *testing.T) {
+	const res = 6
+	pentagons := make([]H3Index, PentagonIndexCount())
+	GetPentagonIndexes(2, &pentagons)
+	var pool []H3Index
+	for _, parent := range []H3Index{pentagons[0], H3Index(0x822837fffffffff)} {
+		H3ToChildren(parent, res, &pool)
+	}
+
+	rng := rand.New(rand.NewPCG(7, 8))
+	b, err := NewCellBitmap(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := NewCellSet()
+	for range 20000 {
+		cell := pool[rng.IntN(len(pool))]
+		if rng.IntN(3) == 0 {
+			b.Remove(cell)
+			want.Remove(cell)
+		} else if err := b.Add(cell); err != nil {
+			t.Fatal(err)
+		} else {
+			want.Add(cell)
+		}
+	}
+
+	// All ascends like a sorted slice, across blocks in both forms.
+	if got := b.Cells(); !slices.Equal(got, want.Cells()) {
+		t.Fatalf("Cells() = %d cells, want %d", len(got), want.Len())
+	}
+	if b.Len() != want.Len() {
+		t.Fatalf("Len() = %d, want %d", b.Len(), want.Len())
+	}
+	for _, cell := range pool {
+		if b.Has(cell) != want.Has(cell) {
+			t.Fatalf("Has(%s) = %t, want %t", cell, b.Has(cell), want.Has(cell))
+		}
+	}
+}
+
+func TestCellBitmapArrayToBitmap(t *testing.T) {
+	const res = 6
+	parent := H3Index(0x822837fffffffff)
+	var children []H3Index
+	H3ToChildren(parent, res, &children)
+
+	b, _ := NewCellBitmap(res)
+	key, _ := b.split(children[0])
+	for i, cell := range children[:cellBitmapArrayMax+1] {
+		if err := b.Add(cell); err != nil {
+			t.Fatal(err)
+		}
+		if dense := b.blocks[key].words != nil; dense != (i == cellBitmapArrayMax) {
+			t.Fatalf("block with %d cells is dense: %t", i+1, dense)
+		}
+	}
+	if !slices.Equal(b.Cells(), children[:cellBitmapArrayMax+1]) {
+		t.Fatalf("Cells() after switching to a bitmap = %v", b.Cells())
+	}
+
+	// Adding a present cell leaves the count alone in either form.
+	if err := b.Add(children[0]); err != nil || b.Len() != cellBitmapArrayMax+1 {
+		t.Fatalf("Len() after adding a present cell = %d, %v", b.Len(), err)
+	}
+	for _, cell := range children[:cellBitmapArrayMax+1] {
+		b.Remove(cell)
+		if b.Has(cell) {
+			t.Fatalf("Has(%s) after removing it", cell)
+		}
+	}
+	if b.Len() != 0 || len(b.blocks) != 0 {
+		t.Fatalf("emptied set has %d cells in %d blocks", b.Len(), len(b.blocks))
+	}
+}
+
+func TestCellBitmapInvalid(t *testing.T) {
+	// a dense block, whose bitmap ends at the last offset of the block
+	var children []H3Index
+	H3ToChildren(H3Index(0x822837fffffffff), 6, &children)
+	b, _ := NewCellBitmap(6)
+	for _, cell := range children {
+		if err := b.Add(cell); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Digits of 7 below the block key give offsets past the end of the
+	// block.
+	bad := children[len(children)-1]
+	H3_SET_INDEX_DIGIT(&bad, 3, INVALID_DIGIT)
+	for _, c := range []H3Index{bad, H3Index(0x7fffffffffffffff), H3_NULL} {
+		if err := b.Add(c); !errors.Is(err, ErrInvalidCell) {
+			t.Errorf("Add(%s) error = %v, want %v", c, err, ErrInvalidCell)
+		}
+		if b.Has(c) {
+			t.Errorf("Has(%s) = true, want false", c)
+		}
+		b.Remove(c)
+	}
+	if err := b.Add(H3ToParent(children[0], 5)); !errors.Is(err, ErrResolutionMismatch) {
+		t.Errorf("Add at resolution 5 error = %v, want %v", err, ErrResolutionMismatch)
+	}
+	if !slices.Equal(b.Cells(), children) {
+		t.Errorf("invalid cells changed the set to %d cells", b.Len())
+	}
+	if _, err := NewCellBitmap(MAX_H3_RES + 1); !errors.Is(err, ErrInvalidResolution) {
+		t.Errorf("NewCellBitmap(%d) error = %v, want %v", MAX_H3_RES+1, err, ErrInvalidResolution)
+	}
+}
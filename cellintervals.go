@@ -0,0 +1,249 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// CellInterval is a range of cell indexes, from First to Last inclusive, in
+// numeric order. Ranges built from CellRange also span indexes which are not
+// valid cells, but never a valid cell at their resolution outside of the
+// coverage they stand for.
+type CellInterval struct {
+	First uint64
+	Last  uint64
+}
+
+// CellIntervals is a coverage stored as sorted, disjoint ranges of the cell
+// indexes at a single resolution, see CellRange. A contiguous area of
+// descendants takes a single range however many cells it has, so a
+// compacted coverage is usually far smaller this way than as a list of cells
+// at the resolution, and the ranges translate directly to BETWEEN queries on
+// a store of indexes at that resolution.
+type CellIntervals struct {
+	res    int            // resolution of the ranges
+	ranges []CellInterval // sorted, disjoint and not adjacent
+}
+
+// NewCellIntervals returns the ranges at res covering cells, which may mix
+// resolutions, such as the output of Compact. H3_NULL entries are skipped.
+//
+// Return ErrInvalidResolution if res is out of range, ErrInvalidCell if
+// cells contains an index which is not a valid cell, or
+// ErrResolutionMismatch if a cell is finer than res.
+func NewCellIntervals(cells []H3Index, res int) (*CellIntervals, error) {
+	if res < 0 || res > MAX_H3_RES {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidResolution, res)
+	}
+
+	ranges := make([]CellInterval, 0, len(cells))
+	for _, cell := range cells {
+		if cell == H3_NULL {
+			continue
+		}
+		if !H3IsValid(cell) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCell, cell)
+		}
+		if H3_GET_RESOLUTION(cell) > res {
+			return nil, fmt.Errorf("%w: %d and %d", ErrResolutionMismatch, H3_GET_RESOLUTION(cell), res)
+		}
+		first, last := CellRange(cell, res)
+		ranges = append(ranges, CellInterval{First: uint64(first), Last: uint64(last)})
+	}
+	slices.SortFunc(ranges, func(a, b CellInterval) int {
+		if a.First != b.First {
+			return cmp.Compare(a.First, b.First)
+		}
+		return cmp.Compare(a.Last, b.Last)
+	})
+
+	return &CellIntervals{res: res, ranges: mergeIntervals(ranges, res)}, nil
+}
+
+// Resolution returns the resolution of the ranges.
+func (s *CellIntervals) Resolution() int {
+	return s.res
+}
+
+// Len returns the number of ranges.
+func (s *CellIntervals) Len() int {
+	return len(s.ranges)
+}
+
+// Intervals returns the ranges in ascending order.
+func (s *CellIntervals) Intervals() []CellInterval {
+	return slices.Clone(s.ranges)
+}
+
+// Contains reports whether the area of cell is covered. Cells finer than the
+// resolution of the ranges are looked up by their ancestor at it.
+func (s *CellIntervals) Contains(cell H3Index) bool {
+	if !H3IsValid(cell) {
+		return false
+	}
+	if H3_GET_RESOLUTION(cell) > s.res {
+		cell = H3ToParent(cell, s.res)
+	}
+	first, last := CellRange(cell, s.res)
+
+	// The last range starting at or before first is the only one which can
+	// hold cell.
+	i, found := slices.BinarySearchFunc(s.ranges, uint64(first), func(r CellInterval, v uint64) int {
+		return cmp.Compare(r.First, v)
+	})
+	if !found {
+		i--
+	}
+	return i >= 0 && uint64(last) <= s.ranges[i].Last
+}
+
+// Union returns the ranges covering the area covered by s, o or both.
+//
+// Return ErrResolutionMismatch if s and o are at different resolutions.
+func (s *CellIntervals) Union(o *CellIntervals) (*CellIntervals, error) {
+	if s.res != o.res {
+		return nil, fmt.Errorf("%w: %d and %d", ErrResolutionMismatch, s.res, o.res)
+	}
+
+	ranges := make([]CellInterval, 0, len(s.ranges)+len(o.ranges))
+	i, j := 0, 0
+	for i < len(s.ranges) || j < len(o.ranges) {
+		if j == len(o.ranges) || i < len(s.ranges) && s.ranges[i].First <= o.ranges[j].First {
+			ranges = append(ranges, s.ranges[i])
+			i++
+		} else {
+			ranges = append(ranges, o.ranges[j])
+			j++
+		}
+	}
+
+	return &CellIntervals{res: s.res, ranges: mergeIntervals(ranges, s.res)}, nil
+}
+
+// Intersect returns the ranges covering the area covered by both s and o.
+//
+// Return ErrResolutionMismatch if s and o are at different resolutions.
+func (s *CellIntervals) Intersect(o *CellIntervals) (*CellIntervals, error) {
+	if s.res != o.res {
+		return nil, fmt.Errorf("%w: %d and %d", ErrResolutionMismatch, s.res, o.res)
+	}
+
+	// The pieces are bounded by ends of ranges of s and o, which are not
+	// adjacent to other ranges of their own set, so the pieces need no
+	// merging.
+	var ranges []CellInterval
+	i, j := 0, 0
+	for i < len(s.ranges) && j < len(o.ranges) {
+		a, b := s.ranges[i], o.ranges[j]
+		first, last := a.First, a.Last
+		if b.First > first {
+			first = b.First
+		}
+		if b.Last < last {
+			last = b.Last
+		}
+		if first <= last {
+			ranges = append(ranges, CellInterval{First: first, Last: last})
+		}
+		if a.Last < b.Last {
+			i++
+		} else {
+			j++
+		}
+	}
+
+	return &CellIntervals{res: s.res, ranges: ranges}, nil
+}
+
+// Cells returns the fewest cells covering the ranges, in ascending order.
+// For ranges built from a compacted set of cells this is the same set.
+func (s *CellIntervals) Cells() []H3Index {
+	var out []H3Index
+	for _, r := range s.ranges {
+		cur, ok := r.First, true
+		for ok && cur <= r.Last {
+			// Climb while the parent starts at the same index and ends
+			// within the range.
+			cell := H3Index(cur)
+			for res := H3_GET_RESOLUTION(cell); res > 0 && H3_GET_INDEX_DIGIT(cell, res) == CENTER_DIGIT; res-- {
+				parent := H3ToParent(cell, res-1)
+				if _, last := CellRange(parent, s.res); uint64(last) > r.Last {
+					break
+				}
+				cell = parent
+			}
+			out = append(out, cell)
+
+			_, last := CellRange(cell, s.res)
+			cur, ok = nextCellIndex(uint64(last), s.res)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// mergeIntervals merges the ranges at res, sorted by their first index, that
+// overlap or have no valid cell between them, in place.
+func mergeIntervals(ranges []CellInterval, res int) []CellInterval {
+	out := ranges[:0]
+	for _, r := range ranges {
+		if n := len(out); n > 0 {
+			prev := &out[n-1]
+			next, ok := nextCellIndex(prev.Last, res)
+			if r.First <= prev.Last || ok && r.First == next {
+				if r.Last > prev.Last {
+					prev.Last = r.Last
+				}
+				continue
+			}
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// nextCellIndex returns the smallest valid cell at res greater than the
+// valid cell v, skipping the unused digit 7 and the deleted subsequence of
+// pentagons.
+//
+// Return false if v is the last cell at res.
+func nextCellIndex(v uint64, res int) (uint64, bool) {
+	h := H3Index(v)
+	r := res
+	for ; r > 0; r-- {
+		if d := H3_GET_INDEX_DIGIT(h, r); d < IJ_AXES_DIGIT {
+			H3_SET_INDEX_DIGIT(&h, r, d+1)
+			break
+		}
+		H3_SET_INDEX_DIGIT(&h, r, CENTER_DIGIT)
+	}
+	if r == 0 {
+		bc := H3_GET_BASE_CELL(h) + 1
+		if bc == NUM_BASE_CELLS {
+			return 0, false
+		}
+		H3_SET_BASE_CELL(&h, bc)
+	}
+
+	// Only the digit just incremented can have become the leading non-zero
+	// digit.
+	if r > 0 && _isBaseCellPentagon(H3_GET_BASE_CELL(h)) && _h3LeadingNonZeroDigit(h) == K_AXES_DIGIT {
+		H3_SET_INDEX_DIGIT(&h, r, J_AXES_DIGIT)
+	}
+	return uint64(h), true
+}
@@ -0,0 +1,193 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// sortedCells returns all cells at res under the given cells, sorted.
+func sortedCells(cells []H3Index, res int) []H3Index {
+	var out []H3Index
+	for _, cell := range cells {
+		H3ToChildren(cell, res, &out)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// coverageProbes returns cells to look up in random coverages: every cell
+// under the coverage roots down to coverageRes, and a few of their children,
+// some of them finer than coverageRes.
+func coverageProbes(rng *rand.Rand) []H3Index {
+	var probes []H3Index
+	for _, root := range coverageRoots() {
+		for res := H3_GET_RESOLUTION(root); res <= coverageRes; res++ {
+			H3ToChildren(root, res, &probes)
+		}
+	}
+	for n := len(probes); len(probes) < n+20; {
+		cell := makeDirectChild(probes[rng.IntN(n)], Direction(rng.IntN(int(INVALID_DIGIT))))
+		if H3IsValid(cell) {
+			probes = append(probes, cell)
+		}
+	}
+	return probes
+}
+
+// naiveCovers reports whether the sorted cells at coverageRes cover all of
+// cell, and whether they cover any of it.
+func naiveCovers(cells []H3Index, cell H3Index) (all, any bool) {
+	if H3_GET_RESOLUTION(cell) > coverageRes {
+		_, found := slices.BinarySearch(cells, H3ToParent(cell, coverageRes))
+		return found, found
+	}
+	all = true
+	for _, c := range expandCoverage([]H3Index{cell}) {
+		_, found := slices.BinarySearch(cells, c)
+		all = all && found
+		any = any || found
+	}
+	return all, any
+}
+
+func TestNextCellIndex(t *testing.T) {
+	// Every cell at resolution 2, and the cells at resolution 4 of a
+	// pentagon base cell, skipping the deleted subsequences at each level.
+	pentagon := H3ToParent(coverageRoots()[0], 0)
+	for _, tt := range []struct {
+		cells []H3Index
+		res   int
+	}{
+		{sortedCells(GetRes0Indexes(), 2), 2},
+		{sortedCells([]H3Index{pentagon}, 4), 4},
+	} {
+		for i, cell := range tt.cells[:len(tt.cells)-1] {
+			next, ok := nextCellIndex(uint64(cell), tt.res)
+			if !ok || H3Index(next) != tt.cells[i+1] {
+				t.Fatalf("nextCellIndex(%s, %d) = %s, %t, want %s", cell, tt.res, H3Index(next), ok, tt.cells[i+1])
+			}
+		}
+	}
+	last := sortedCells(GetRes0Indexes(), 2)
+	if _, ok := nextCellIndex(uint64(last[len(last)-1]), 2); ok {
+		t.Errorf("nextCellIndex(%s, 2) found a cell after the last one", last[len(last)-1])
+	}
+}
+
+func TestCellIntervalsAcrossPentagon(t *testing.T) {
+	// The children of a pentagon leave out the K axes child, and the ranges
+	// either side of it join as no valid cell lies between them.
+	pentagon := coverageRoots()[0]
+	var children []H3Index
+	H3ToChildren(pentagon, 2, &children)
+	s, err := NewCellIntervals(children, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Len() != 1 {
+		t.Errorf("children of pentagon %s give %d ranges, want 1", pentagon, s.Len())
+	}
+	if got := s.Cells(); !slices.Equal(got, []H3Index{pentagon}) {
+		t.Errorf("Cells() = %v, want %v", got, []H3Index{pentagon})
+	}
+
+	// Without the center child, the gap is real.
+	s, err = NewCellIntervals(children[1:], 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Cells(); !slices.Equal(got, children[1:]) {
+		t.Errorf("Cells() = %v, want %v", got, children[1:])
+	}
+}
+
+func TestCellIntervals(t *testing.T) {
+	rng := rand.New(rand.NewPCG(11, 12))
+	probes := coverageProbes(rng)
+	for range 50 {
+		a := expandCoverage(randomCoverage(rng, 1+rng.IntN(30)))
+		b := expandCoverage(randomCoverage(rng, 1+rng.IntN(30)))
+		sa, err := NewCellIntervals(reshapeCoverage(rng, mustCompact(t, a)), coverageRes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sb, err := NewCellIntervals(b, coverageRes)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checkCompacted(t, "Cells()", sa.Cells(), a)
+		for _, cell := range probes {
+			if want, _ := naiveCovers(a, cell); sa.Contains(cell) != want {
+				t.Fatalf("Contains(%s) = %t, want %t", cell, !want, want)
+			}
+		}
+
+		union, err := sa.Union(sb)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkCompacted(t, "Union", union.Cells(), naiveSetOp(a, b, func(inA, inB bool) bool { return inA || inB }))
+		intersection, err := sa.Intersect(sb)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkCompacted(t, "Intersect", intersection.Cells(), naiveSetOp(a, b, func(inA, inB bool) bool { return inA && inB }))
+
+		// Ranges never touch, so the same area always gives the same ranges.
+		again, err := NewCellIntervals(union.Cells(), coverageRes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(again.Intervals(), union.Intervals()) {
+			t.Fatalf("ranges of Union %v differ from the ranges of its cells %v", union.Intervals(), again.Intervals())
+		}
+	}
+}
+
+func TestCellIntervalsErrors(t *testing.T) {
+	cell := coverageRoots()[1]
+	if _, err := NewCellIntervals([]H3Index{cell}, MAX_H3_RES+1); !errors.Is(err, ErrInvalidResolution) {
+		t.Errorf("NewCellIntervals at %d error = %v, want %v", MAX_H3_RES+1, err, ErrInvalidResolution)
+	}
+	if _, err := NewCellIntervals([]H3Index{H3Index(0x7fffffffffffffff)}, 3); !errors.Is(err, ErrInvalidCell) {
+		t.Errorf("NewCellIntervals of an invalid cell error = %v, want %v", err, ErrInvalidCell)
+	}
+	if _, err := NewCellIntervals([]H3Index{makeDirectChild(cell, CENTER_DIGIT)}, 1); !errors.Is(err, ErrResolutionMismatch) {
+		t.Errorf("NewCellIntervals of a finer cell error = %v, want %v", err, ErrResolutionMismatch)
+	}
+	a, _ := NewCellIntervals([]H3Index{cell}, 2)
+	b, _ := NewCellIntervals([]H3Index{cell}, 3)
+	if _, err := a.Union(b); !errors.Is(err, ErrResolutionMismatch) {
+		t.Errorf("Union at different resolutions error = %v, want %v", err, ErrResolutionMismatch)
+	}
+	if _, err := a.Intersect(b); !errors.Is(err, ErrResolutionMismatch) {
+		t.Errorf("Intersect at different resolutions error = %v, want %v", err, ErrResolutionMismatch)
+	}
+}
+
+// mustCompact returns the compacted cells, failing the test on an error.
+func mustCompact(t *testing.T, cells []H3Index) []H3Index {
+	t.Helper()
+	out, err := Compact(cells)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
@@ -0,0 +1,146 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"iter"
+	"slices"
+	"sort"
+)
+
+// CellMap is a map from cells to values of type V which also answers
+// hierarchical queries: the value covering a cell from one of its ancestors,
+// and the values stored under a cell at finer resolutions. Keys may mix
+// resolutions.
+//
+// The descendant queries use an index of the keys sorted in pre-order, which
+// is rebuilt on the first query after keys were added or deleted, so even
+// read-only use is not safe for concurrent use.
+type CellMap[V any] struct {
+	values map[H3Index]V
+	keys   []H3Index // keys sorted by comparePreorder, nil when stale
+}
+
+// NewCellMap returns an empty map.
+func NewCellMap[V any]() *CellMap[V] {
+	return &CellMap[V]{values: make(map[H3Index]V)}
+}
+
+// Len returns the number of cells in the map.
+func (m *CellMap[V]) Len() int {
+	return len(m.values)
+}
+
+// Set stores v for cell.
+func (m *CellMap[V]) Set(cell H3Index, v V) {
+	if _, ok := m.values[cell]; !ok {
+		m.keys = nil
+	}
+	m.values[cell] = v
+}
+
+// Get returns the value stored for cell itself, and whether there is one.
+func (m *CellMap[V]) Get(cell H3Index) (V, bool) {
+	v, ok := m.values[cell]
+	return v, ok
+}
+
+// Delete removes the value stored for cell.
+func (m *CellMap[V]) Delete(cell H3Index) {
+	if _, ok := m.values[cell]; ok {
+		delete(m.values, cell)
+		m.keys = nil
+	}
+}
+
+// GetCovering returns the value stored for cell or, failing that, for its
+// nearest ancestor, and whether there is one.
+func (m *CellMap[V]) GetCovering(cell H3Index) (V, bool) {
+	if cell != H3_NULL {
+		for res := H3_GET_RESOLUTION(cell); res >= 0; res-- {
+			if v, ok := m.values[H3ToParent(cell, res)]; ok {
+				return v, true
+			}
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Under returns an iterator over the cells stored at cell or its
+// descendants, with their values, in pre-order: every cell comes before its
+// descendants.
+func (m *CellMap[V]) Under(cell H3Index) iter.Seq2[H3Index, V] {
+	return func(yield func(H3Index, V) bool) {
+		if cell == H3_NULL {
+			return
+		}
+		keys := m.sortedKeys()
+		_, hi := cellRange(cell)
+
+		// Ancestors of cell sort before it, so every key from cell on whose
+		// range starts within the range of cell is a descendant.
+		i := sort.Search(len(keys), func(i int) bool { return comparePreorder(keys[i], cell) >= 0 })
+		for ; i < len(keys); i++ {
+			if lo, _ := cellRange(keys[i]); lo > hi {
+				return
+			}
+			if !yield(keys[i], m.values[keys[i]]) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the cells of the map with their values, in
+// no particular order.
+func (m *CellMap[V]) All() iter.Seq2[H3Index, V] {
+	return func(yield func(H3Index, V) bool) {
+		for cell, v := range m.values {
+			if !yield(cell, v) {
+				return
+			}
+		}
+	}
+}
+
+// sortedKeys returns the keys in pre-order, rebuilding the index if stale.
+func (m *CellMap[V]) sortedKeys() []H3Index {
+	if m.keys == nil {
+		m.keys = make([]H3Index, 0, len(m.values))
+		for cell := range m.values {
+			m.keys = append(m.keys, cell)
+		}
+		slices.SortFunc(m.keys, comparePreorder)
+	}
+	return m.keys
+}
+
+// number is the set of types SumUnder can add up.
+type number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// SumUnder returns the sum of the values stored in m for cell and its
+// descendants, see (*CellMap).Under.
+func SumUnder[V number](m *CellMap[V], cell H3Index) V {
+	var sum V
+	for _, v := range m.Under(cell) {
+		sum += v
+	}
+	return sum
+}
@@ -0,0 +1,102 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestCellMap(t *testing.T) {
+	rng := rand.New(rand.NewPCG(15, 16))
+	probes := coverageProbes(rng)
+	m := NewCellMap[int]()
+	want := make(map[H3Index]int)
+	for round := range 20 {
+		// keys mixing resolutions, ancestors and descendants
+		for _, cell := range randomCoverage(rng, 20) {
+			v := rng.IntN(100)
+			m.Set(cell, v)
+			want[cell] = v
+		}
+		for cell := range want {
+			if rng.IntN(4) == 0 {
+				m.Delete(cell)
+				delete(want, cell)
+			}
+		}
+		if m.Len() != len(want) {
+			t.Fatalf("round %d: Len() = %d, want %d", round, m.Len(), len(want))
+		}
+
+		for _, cell := range probes {
+			wantV, wantOK := want[cell]
+			if v, ok := m.Get(cell); v != wantV || ok != wantOK {
+				t.Fatalf("Get(%s) = %d, %t, want %d, %t", cell, v, ok, wantV, wantOK)
+			}
+
+			// the value of cell itself or else of its nearest ancestor
+			for res := H3_GET_RESOLUTION(cell) - 1; res >= 0 && !wantOK; res-- {
+				wantV, wantOK = want[H3ToParent(cell, res)]
+			}
+			if v, ok := m.GetCovering(cell); v != wantV || ok != wantOK {
+				t.Fatalf("GetCovering(%s) = %d, %t, want %d, %t", cell, v, ok, wantV, wantOK)
+			}
+
+			// the keys at cell or under it, every one before its descendants
+			res := H3_GET_RESOLUTION(cell)
+			var wantKeys []H3Index
+			wantSum := 0
+			for key, v := range want {
+				if H3_GET_RESOLUTION(key) >= res && H3ToParent(key, res) == cell {
+					wantKeys = append(wantKeys, key)
+					wantSum += v
+				}
+			}
+			var keys []H3Index
+			for key, v := range m.Under(cell) {
+				if v != want[key] {
+					t.Fatalf("Under(%s) yields %s with %d, want %d", cell, key, v, want[key])
+				}
+				for _, prev := range keys {
+					if H3_GET_RESOLUTION(prev) > H3_GET_RESOLUTION(key) && H3ToParent(prev, H3_GET_RESOLUTION(key)) == key {
+						t.Fatalf("Under(%s) yields %s after its descendant %s", cell, key, prev)
+					}
+				}
+				keys = append(keys, key)
+			}
+			slices.Sort(keys)
+			slices.Sort(wantKeys)
+			if !slices.Equal(keys, wantKeys) {
+				t.Fatalf("Under(%s) = %v, want %v", cell, keys, wantKeys)
+			}
+			if sum := SumUnder(m, cell); sum != wantSum {
+				t.Fatalf("SumUnder(%s) = %d, want %d", cell, sum, wantSum)
+			}
+		}
+	}
+
+	n := 0
+	for cell, v := range m.All() {
+		if want[cell] != v {
+			t.Fatalf("All() yields %s with %d, want %d", cell, v, want[cell])
+		}
+		n++
+	}
+	if n != len(want) {
+		t.Fatalf("All() yields %d cells, want %d", n, len(want))
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"maps"
+	"slices"
+)
+
+// BoundaryCells returns the cells of set with at least one neighbor outside
+// of set, sorted in ascending order.
+//
+// Neighbors are stepped to one direction at a time, so a cell deep inside the
+// set costs six lookups and a cell on the boundary is settled by the first
+// neighbor found outside.
+func BoundaryCells(set CellSet) []H3Index {
+	var out []H3Index
+	for cell := range set {
+		if hasNeighborOutside(set, cell) {
+			out = append(out, cell)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// Dilate grows set by every cell within k grid steps of one of its cells,
+// buffering it in grid space.
+//
+// Return the cells of the grown set sorted in ascending order, or ErrInvalidK
+// if k is out of range.
+func Dilate(set CellSet, k int) ([]H3Index, error) {
+	if _, err := MaxGridDiskSize(k); err != nil {
+		return nil, err
+	}
+
+	dist := DistanceTransform(set, k)
+	out := make([]H3Index, 0, len(dist))
+	for cell := range dist {
+		out = append(out, cell)
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+// Erode shrinks set to the cells whose every cell within k grid steps is
+// also in set, the inverse of Dilate. The set is peeled one layer of boundary
+// cells at a time, and after the first layer only the cells next to the ones
+// just removed are examined.
+//
+// Return the cells of the shrunk set sorted in ascending order, or
+// ErrInvalidK if k is out of range.
+func Erode(set CellSet, k int) ([]H3Index, error) {
+	if _, err := MaxGridDiskSize(k); err != nil {
+		return nil, err
+	}
+
+	remaining := maps.Clone(set)
+	if remaining == nil {
+		remaining = NewCellSet()
+	}
+	var layer []H3Index
+	for cell := range set {
+		if hasNeighborOutside(set, cell) {
+			layer = append(layer, cell)
+		}
+	}
+
+	for i := 0; i < k && len(layer) > 0; i++ {
+		for _, cell := range layer {
+			remaining.Remove(cell)
+		}
+		if i == k-1 {
+			break
+		}
+
+		candidates := NewCellSet()
+		for _, cell := range layer {
+			for _, dir := range DIRECTIONS {
+				rotations := 0
+				neighbor := h3NeighborRotations(cell, dir, &rotations)
+				if remaining.Has(neighbor) {
+					candidates.Add(neighbor)
+				}
+			}
+		}
+		layer = layer[:0]
+		for cell := range candidates {
+			if hasNeighborOutside(remaining, cell) {
+				layer = append(layer, cell)
+			}
+		}
+	}
+	return remaining.Cells(), nil
+}
+
+// hasNeighborOutside reports whether any neighbor of cell is missing from
+// set.
+func hasNeighborOutside(set CellSet, cell H3Index) bool {
+	for _, dir := range DIRECTIONS {
+		rotations := 0
+		neighbor := h3NeighborRotations(cell, dir, &rotations)
+		if neighbor != H3_NULL && !set.Has(neighbor) {
+			return true
+		}
+	}
+	return false
+}
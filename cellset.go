@@ -0,0 +1,137 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import "slices"
+
+// CellSet is a set of cells.
+type CellSet map[H3Index]struct{}
+
+// NewCellSet returns a set holding the given cells. H3_NULL entries are
+// ignored.
+func NewCellSet(cells ...H3Index) CellSet {
+	s := make(CellSet, len(cells))
+	for _, cell := range cells {
+		s.Add(cell)
+	}
+	return s
+}
+
+// Add adds cell to the set. H3_NULL is ignored.
+func (s CellSet) Add(cell H3Index) {
+	if cell != H3_NULL {
+		s[cell] = struct{}{}
+	}
+}
+
+// Remove removes cell from the set.
+func (s CellSet) Remove(cell H3Index) {
+	delete(s, cell)
+}
+
+// Has reports whether cell is in the set.
+func (s CellSet) Has(cell H3Index) bool {
+	_, ok := s[cell]
+	return ok
+}
+
+// Len returns the number of cells in the set.
+func (s CellSet) Len() int {
+	return len(s)
+}
+
+// Cells returns the cells of the set sorted in ascending order.
+func (s CellSet) Cells() []H3Index {
+	out := make([]H3Index, 0, len(s))
+	for cell := range s {
+		out = append(out, cell)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// Contains reports whether cell lies within the area of the set, that is
+// whether the set holds cell itself or one of its ancestors. Unlike Has it
+// treats the set as a multi-resolution coverage.
+func (s CellSet) Contains(cell H3Index) bool {
+	if cell == H3_NULL {
+		return false
+	}
+	for res := H3_GET_RESOLUTION(cell); res >= 0; res-- {
+		if s.Has(H3ToParent(cell, res)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Union returns the area covered by s or o as a new set, treating both as
+// multi-resolution coverages. The result is compacted: cells covered by an
+// ancestor are dropped and complete groups of siblings are merged, see
+// CoverageUnion.
+//
+// Return the union, or ErrInvalidCell if either set holds an invalid cell.
+func (s CellSet) Union(o CellSet) (CellSet, error) {
+	cells, err := CoverageUnion(s.Cells(), o.Cells())
+	if err != nil {
+		return nil, err
+	}
+	return NewCellSet(cells...), nil
+}
+
+// Intersect returns the area covered by both s and o as a new set, treating
+// both as multi-resolution coverages: where a cell of one set contains finer
+// cells of the other, those finer cells are the intersection. The result is
+// compacted, see CoverageIntersection.
+//
+// Return the intersection, or ErrInvalidCell if either set holds an invalid
+// cell.
+func (s CellSet) Intersect(o CellSet) (CellSet, error) {
+	cells, err := CoverageIntersection(s.Cells(), o.Cells())
+	if err != nil {
+		return nil, err
+	}
+	return NewCellSet(cells...), nil
+}
+
+// Difference returns the area covered by s but not by o as a new set,
+// treating both as multi-resolution coverages: cells of s partially covered
+// by o are split into their coarsest children outside o. The result is
+// compacted, see CoverageDifference.
+//
+// Return the difference, or ErrInvalidCell if either set holds an invalid
+// cell.
+func (s CellSet) Difference(o CellSet) (CellSet, error) {
+	cells, err := CoverageDifference(s.Cells(), o.Cells())
+	if err != nil {
+		return nil, err
+	}
+	return NewCellSet(cells...), nil
+}
+
+// Diff returns the changes from coverage a to coverage b: added is the area
+// covered by b but not by a, and removed the area covered by a but not by b.
+// Both sets may be compacted differently; where a cell of one contains finer
+// cells of the other, it is split into its coarsest children around them, so
+// the result stays compacted rather than expanded to a common resolution.
+//
+// Return the changes, or ErrInvalidCell if either set holds an invalid cell.
+func Diff(a, b CellSet) (added, removed CellSet, err error) {
+	na, nb, err := normalizeCoverages(a.Cells(), b.Cells())
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewCellSet(subtractCoverage(nb, na)...), NewCellSet(subtractCoverage(na, nb)...), nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import "slices"
+
+// SortCells sorts cells in ascending order in place, the order used by the
+// rest of the package for sorted results.
+func SortCells(cells []H3Index) {
+	slices.Sort(cells)
+}
+
+// SearchCell finds cell in cells, which must be sorted in ascending order,
+// with a binary search.
+//
+// Return the position of cell, or where it would be inserted if absent, and
+// whether it was found.
+func SearchCell(cells []H3Index, cell H3Index) (int, bool) {
+	return slices.BinarySearch(cells, cell)
+}
+
+// InsertCell inserts cell into cells, which must be sorted in ascending
+// order, keeping them sorted. cells is treated as a set: if cell is already
+// present it is returned unchanged.
+//
+// Return the updated slice, which may share memory with cells as the result
+// of append does.
+func InsertCell(cells []H3Index, cell H3Index) []H3Index {
+	i, found := slices.BinarySearch(cells, cell)
+	if found {
+		return cells
+	}
+	return slices.Insert(cells, i, cell)
+}
@@ -0,0 +1,171 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"fmt"
+	"slices"
+)
+
+// CellTrie is a coverage stored as a prefix tree over the base cell and the
+// indexing digits of its cells, for point-in-coverage tests against a
+// compacted set. A lookup follows the digits of the queried cell, one node
+// per resolution, instead of looking up each of its ancestors in a map as
+// CellSet.Contains does.
+type CellTrie struct {
+	roots [NUM_BASE_CELLS]*trieNode
+	n     int // number of cells
+}
+
+// trieNode is the node of a CellTrie for one cell. A leaf node stands for a
+// cell of the coverage and has no children; other nodes have cells of the
+// coverage among their descendants.
+type trieNode struct {
+	children [NUM_DIGITS]*trieNode
+	leaf     bool
+}
+
+// NewCellTrie returns a trie holding the area of cells, which may mix
+// resolutions. H3_NULL entries are skipped.
+//
+// Return the trie, or ErrInvalidCell if cells contains an index which is not
+// a valid cell.
+func NewCellTrie(cells []H3Index) (*CellTrie, error) {
+	t := &CellTrie{}
+	for _, cell := range cells {
+		if cell == H3_NULL {
+			continue
+		}
+		if err := t.Add(cell); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// Len returns the number of cells stored, after dropping the cells covered
+// by an ancestor.
+func (t *CellTrie) Len() int {
+	return t.n
+}
+
+// Add adds the area of cell to the trie. Cells already covered by an
+// ancestor are ignored, and cells covered by cell are dropped.
+//
+// Return ErrInvalidCell if cell is not a valid cell.
+func (t *CellTrie) Add(cell H3Index) error {
+	if !H3IsValid(cell) {
+		return fmt.Errorf("%w: %s", ErrInvalidCell, cell)
+	}
+
+	node := &t.roots[H3_GET_BASE_CELL(cell)]
+	for r := 1; ; r++ {
+		if *node == nil {
+			*node = &trieNode{}
+		} else if (*node).leaf {
+			return nil
+		}
+		if r > H3_GET_RESOLUTION(cell) {
+			break
+		}
+		node = &(*node).children[H3_GET_INDEX_DIGIT(cell, r)]
+	}
+
+	t.n += 1 - (*node).count()
+	**node = trieNode{leaf: true}
+	return nil
+}
+
+// Contains reports whether cell lies within the area of the trie, that is
+// whether it holds cell itself or one of its ancestors.
+func (t *CellTrie) Contains(cell H3Index) bool {
+	node := t.find(cell)
+	return node != nil && node.leaf
+}
+
+// Intersects reports whether cell and the area of the trie overlap, that is
+// whether the trie holds cell, one of its ancestors or one of its
+// descendants.
+func (t *CellTrie) Intersects(cell H3Index) bool {
+	return t.find(cell) != nil
+}
+
+// Cells returns the cells stored in the trie in ascending order.
+func (t *CellTrie) Cells() []H3Index {
+	out := make([]H3Index, 0, t.n)
+	for bc, root := range t.roots {
+		var cell H3Index
+		setH3Index(&cell, 0, bc, CENTER_DIGIT)
+		out = root.appendCells(out, cell)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// find follows the digits of cell down the trie.
+//
+// Return the node of cell, the leaf node of its ancestor if the trie holds
+// one, or nil if the trie holds neither cell nor any cell related to it.
+func (t *CellTrie) find(cell H3Index) *trieNode {
+	if H3_GET_MODE(cell) != H3_HEXAGON_MODE {
+		return nil
+	}
+	bc := H3_GET_BASE_CELL(cell)
+	if bc < 0 || bc >= NUM_BASE_CELLS {
+		return nil
+	}
+
+	node := t.roots[bc]
+	for r := 1; node != nil && !node.leaf && r <= H3_GET_RESOLUTION(cell); r++ {
+		digit := H3_GET_INDEX_DIGIT(cell, r)
+		if digit >= INVALID_DIGIT {
+			return nil
+		}
+		node = node.children[digit]
+	}
+	return node
+}
+
+// count returns the number of leaves under n.
+func (n *trieNode) count() int {
+	if n == nil {
+		return 0
+	}
+	if n.leaf {
+		return 1
+	}
+	total := 0
+	for _, child := range n.children {
+		total += child.count()
+	}
+	return total
+}
+
+// appendCells appends the cells of the leaves under n, which is the node of
+// cell, to out.
+func (n *trieNode) appendCells(out []H3Index, cell H3Index) []H3Index {
+	if n == nil {
+		return out
+	}
+	if n.leaf {
+		return append(out, cell)
+	}
+	for digit, child := range n.children {
+		if child != nil {
+			out = child.appendCells(out, makeDirectChild(cell, Direction(digit)))
+		}
+	}
+	return out
+}
@@ -0,0 +1,99 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestCellTrie(t *testing.T) {
+	rng := rand.New(rand.NewPCG(13, 14))
+	probes := coverageProbes(rng)
+	for range 50 {
+		input := randomCoverage(rng, 1+rng.IntN(30))
+		area := expandCoverage(input)
+		compacted, err := Compact(area)
+		if err != nil {
+			t.Fatal(err)
+		}
+		trie, err := NewCellTrie(append(compacted, H3_NULL))
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkCompacted(t, "Cells()", trie.Cells(), area)
+		if trie.Len() != len(compacted) {
+			t.Fatalf("Len() = %d, want %d", trie.Len(), len(compacted))
+		}
+
+		for _, cell := range probes {
+			all, any := naiveCovers(area, cell)
+			if got := trie.Contains(cell); got != all {
+				t.Fatalf("Contains(%s) = %t, want %t", cell, got, all)
+			}
+			if got := trie.Intersects(cell); got != any {
+				t.Fatalf("Intersects(%s) = %t, want %t", cell, got, any)
+			}
+		}
+
+		// Uncompacted input keeps the cells not covered by an ancestor.
+		reshaped := reshapeCoverage(rng, input)
+		trie, err = NewCellTrie(reshaped)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var want []H3Index
+		for _, cell := range reshaped {
+			covered := false
+			for res := H3_GET_RESOLUTION(cell) - 1; res >= 0 && !covered; res-- {
+				covered = slices.Contains(reshaped, H3ToParent(cell, res))
+			}
+			if !covered {
+				want = append(want, cell)
+			}
+		}
+		slices.Sort(want)
+		want = slices.Compact(want)
+		if got := trie.Cells(); !slices.Equal(got, want) {
+			t.Fatalf("Cells() of %v = %v, want %v", reshaped, got, want)
+		}
+		if trie.Len() != len(want) {
+			t.Fatalf("Len() = %d, want %d", trie.Len(), len(want))
+		}
+	}
+}
+
+func TestCellTrieInvalid(t *testing.T) {
+	cell := coverageRoots()[1]
+	trie, err := NewCellTrie([]H3Index{cell})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCellTrie([]H3Index{cell, H3Index(0x7fffffffffffffff)}); !errors.Is(err, ErrInvalidCell) {
+		t.Errorf("NewCellTrie of an invalid cell error = %v, want %v", err, ErrInvalidCell)
+	}
+	if err := trie.Add(H3Index(0x7fffffffffffffff)); !errors.Is(err, ErrInvalidCell) {
+		t.Errorf("Add of an invalid cell error = %v, want %v", err, ErrInvalidCell)
+	}
+
+	bad := makeDirectChild(makeDirectChild(H3ToParent(cell, 0), INVALID_DIGIT), CENTER_DIGIT)
+	for _, c := range []H3Index{bad, H3_NULL, H3Index(0x7fffffffffffffff)} {
+		if trie.Contains(c) || trie.Intersects(c) {
+			t.Errorf("trie holding %s overlaps %s", cell, c)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import "fmt"
+
+// Compactor compacts a stream of cells, as Compact does for a slice, without
+// holding the stream in memory. The cells must arrive in ascending order,
+// as they come out of a sorted file or table, so that the children of every
+// parent arrive one after another. The compactor then only keeps the
+// siblings of the current group at each resolution, at most 7 per
+// resolution, and hands every cell of the result to an emit function as soon
+// as it is final.
+type Compactor struct {
+	emit    func(H3Index) error
+	res     int
+	last    H3Index
+	pending [MAX_H3_RES + 1][]H3Index
+}
+
+// NewCompactor returns a Compactor handing the cells of the result to emit,
+// in no particular order. An error returned by emit is returned by the Add or
+// Finalize call that emitted the cell.
+func NewCompactor(emit func(H3Index) error) *Compactor {
+	return &Compactor{emit: emit, res: -1}
+}
+
+// Add adds cell to the stream. H3_NULL is ignored.
+//
+// Return ErrInvalidCell if cell is not a valid cell, ErrResolutionMismatch if
+// it is not at the resolution of the earlier cells, ErrCompactDuplicate if it
+// equals the previous cell, ErrCompactUnsorted if it sorts before it, or the
+// error of emit.
+func (c *Compactor) Add(cell H3Index) error {
+	if cell == H3_NULL {
+		return nil
+	}
+	if err := validateCompactCell(cell); err != nil {
+		return err
+	}
+	res := H3_GET_RESOLUTION(cell)
+	if c.res < 0 {
+		c.res = res
+	} else if res != c.res {
+		return fmt.Errorf("%w: %s at %d and %s at %d",
+			ErrResolutionMismatch, c.last, c.res, cell, res)
+	}
+	if cell == c.last {
+		return fmt.Errorf("%w: %s", ErrCompactDuplicate, cell)
+	}
+	if cell < c.last {
+		return fmt.Errorf("%w: %s after %s", ErrCompactUnsorted, cell, c.last)
+	}
+	c.last = cell
+
+	return c.push(cell, res)
+}
+
+// Finalize emits the cells still waiting for siblings and resets the
+// compactor, so that it can take a new stream.
+//
+// Return the error of emit.
+func (c *Compactor) Finalize() error {
+	for res := MAX_H3_RES; res >= 0; res-- {
+		if err := c.flush(res); err != nil {
+			return err
+		}
+	}
+	c.res = -1
+	c.last = H3_NULL
+	return nil
+}
+
+// push adds cell at res to the group of its siblings, replacing the group by
+// its parent once it is complete. A cell with a different parent than the
+// group ends the group, as no more siblings can follow.
+func (c *Compactor) push(cell H3Index, res int) error {
+	if res == 0 {
+		return c.emit(cell)
+	}
+
+	parent := H3ToParent(cell, res-1)
+	group := c.pending[res]
+	if len(group) > 0 && H3ToParent(group[0], res-1) != parent {
+		if err := c.flush(res); err != nil {
+			return err
+		}
+	}
+	c.pending[res] = append(c.pending[res], cell)
+
+	complete := 7
+	if H3IsPentagon(parent) {
+		complete = 6
+	}
+	if len(c.pending[res]) < complete {
+		return nil
+	}
+	c.pending[res] = c.pending[res][:0]
+	return c.push(parent, res-1)
+}
+
+// flush emits the incomplete group of siblings at res.
+func (c *Compactor) flush(res int) error {
+	for _, cell := range c.pending[res] {
+		if err := c.emit(cell); err != nil {
+			return err
+		}
+	}
+	c.pending[res] = c.pending[res][:0]
+	return nil
+}
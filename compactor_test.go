@@ -0,0 +1,121 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// compactStream feeds cells to a Compactor and returns what it emits,
+// sorted.
+func compactStream(t *testing.T, cells []H3Index) []H3Index {
+	t.Helper()
+	var out []H3Index
+	c := NewCompactor(func(cell H3Index) error {
+		out = append(out, cell)
+		return nil
+	})
+	for _, cell := range cells {
+		if err := c.Add(cell); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Finalize(); err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(out)
+	return out
+}
+
+func TestCompactor(t *testing.T) {
+	rng := rand.New(rand.NewPCG(9, 10))
+	// whole roots, including a pentagon, and random parts of them
+	inputs := [][]H3Index{expandCoverage(coverageRoots())}
+	for _, n := range []int{1, 5, 20, 80} {
+		for range 10 {
+			inputs = append(inputs, expandCoverage(randomCoverage(rng, n)))
+		}
+	}
+
+	for _, cells := range inputs {
+		want, err := Compact(cells)
+		if err != nil {
+			t.Fatal(err)
+		}
+		slices.Sort(want)
+		if got := compactStream(t, cells); !slices.Equal(got, want) {
+			t.Fatalf("Compactor of %d cells = %v, want %v", len(cells), got, want)
+		}
+	}
+
+	// A finalized compactor takes a new stream, at another resolution too.
+	var out []H3Index
+	c := NewCompactor(func(cell H3Index) error {
+		out = append(out, cell)
+		return nil
+	})
+	roots := coverageRoots()
+	for _, cell := range []H3Index{roots[1], H3ToParent(roots[1], 0)} {
+		if err := c.Add(cell); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Finalize(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !slices.Equal(out, []H3Index{roots[1], H3ToParent(roots[1], 0)}) {
+		t.Errorf("Compactor over two streams emitted %v", out)
+	}
+}
+
+func TestCompactorErrors(t *testing.T) {
+	cells := expandCoverage(coverageRoots()[:1])[:3]
+	for _, tt := range []struct {
+		name  string
+		cells []H3Index
+		want  error
+	}{
+		{"unsorted", []H3Index{cells[1], cells[0]}, ErrCompactUnsorted},
+		{"duplicate", []H3Index{cells[0], cells[1], cells[1]}, ErrCompactDuplicate},
+		{"mixed resolutions", []H3Index{cells[0], H3ToParent(cells[2], 2)}, ErrResolutionMismatch},
+		{"invalid", []H3Index{cells[0], H3Index(0x7fffffffffffffff)}, ErrInvalidCell},
+	} {
+		c := NewCompactor(func(H3Index) error { return nil })
+		var err error
+		for _, cell := range tt.cells {
+			if err = c.Add(cell); err != nil {
+				break
+			}
+		}
+		if !errors.Is(err, tt.want) {
+			t.Errorf("Compactor of %s cells error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// The error of emit comes back from the call that emitted the cell.
+	errEmit := errors.New("emit failed")
+	c := NewCompactor(func(H3Index) error { return errEmit })
+	for _, cell := range []H3Index{H3_NULL, cells[0], cells[1]} {
+		if err := c.Add(cell); err != nil {
+			t.Fatalf("Add(%s) error = %v before anything was emitted", cell, err)
+		}
+	}
+	if err := c.Finalize(); !errors.Is(err, errEmit) {
+		t.Errorf("Finalize() error = %v, want %v", err, errEmit)
+	}
+}
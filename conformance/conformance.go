@@ -0,0 +1,418 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build h3conformance
+
+package conformance
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/isbang/h3go"
+	"github.com/isbang/h3go/h3test"
+	h3 "github.com/uber/h3-go/v3"
+)
+
+// Names of the checks run by Run.
+const (
+	CheckGeoToH3      = "GeoToH3"
+	CheckBoundary     = "H3ToGeoBoundary"
+	CheckKRing        = "KRing"
+	CheckCompact      = "Compact"
+	CheckPolyfill     = "Polyfill"
+	CheckAntimeridian = "Antimeridian"
+)
+
+// boundaryEpsilonDeg is the tolerance for boundary vertices, about 0.1 mm.
+const boundaryEpsilonDeg = 1e-9
+
+// Options configures Run.
+type Options struct {
+	// Seed of the input generator.
+	Seed uint64
+
+	// N is the number of inputs per check. Defaults to 1000.
+	N int
+
+	// MaxK is the largest k used for GridDisk. Defaults to 3.
+	MaxK int
+}
+
+// Divergence is an input on which h3go and the reference disagree.
+type Divergence struct {
+	Check string
+	Input string
+	Got   string // result of h3go
+	Want  string // result of the reference
+}
+
+// Report is the outcome of Run.
+type Report struct {
+	// Inputs is the number of inputs checked per check.
+	Inputs map[string]int
+
+	// Divergences lists the inputs on which the results differ, in the
+	// order they were found.
+	Divergences []Divergence
+}
+
+// WriteTo writes a human readable summary of the report to w.
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	cw := &countingWriter{w: bw}
+
+	failed := make(map[string]int)
+	for _, d := range r.Divergences {
+		failed[d.Check]++
+	}
+
+	checks := make([]string, 0, len(r.Inputs))
+	for check := range r.Inputs {
+		checks = append(checks, check)
+	}
+	sort.Strings(checks)
+	for _, check := range checks {
+		fmt.Fprintf(cw, "%-16s %6d inputs %6d divergences\n", check, r.Inputs[check], failed[check])
+	}
+	for _, d := range r.Divergences {
+		fmt.Fprintf(cw, "%s(%s):\n\tgot  %s\n\twant %s\n", d.Check, d.Input, d.Got, d.Want)
+	}
+
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// Run runs every check against the reference and returns the report.
+func Run(opts Options) *Report {
+	if opts.N <= 0 {
+		opts.N = 1000
+	}
+	if opts.MaxK <= 0 {
+		opts.MaxK = 3
+	}
+
+	r := &runner{
+		gen:    h3test.New(opts.Seed),
+		opts:   opts,
+		report: &Report{Inputs: make(map[string]int)},
+	}
+	r.checkGeoToH3()
+	r.checkBoundary()
+	r.checkKRing()
+	r.checkCompact()
+	r.checkPolyfill()
+	r.checkAntimeridian()
+	return r.report
+}
+
+type runner struct {
+	gen    *h3test.Gen
+	opts   Options
+	report *Report
+}
+
+func (r *runner) diverge(check, input string, got, want any) {
+	r.report.Divergences = append(r.report.Divergences, Divergence{
+		Check: check,
+		Input: input,
+		Got:   fmt.Sprint(got),
+		Want:  fmt.Sprint(want),
+	})
+}
+
+// checkGeoToH3 compares indexing of coordinates drawn over the whole sphere
+// and near the antimeridian and the poles.
+func (r *runner) checkGeoToH3() {
+	for i := 0; i < r.opts.N; i++ {
+		var g h3go.GeoCoord
+		switch i % 3 {
+		case 0:
+			g = r.gen.GeoCoord()
+		case 1:
+			g = r.gen.AntimeridianCoord()
+		default:
+			g = r.gen.PoleCoord()
+		}
+		res := r.gen.Res()
+
+		got := h3go.GeoToH3(&g, res)
+		want := h3go.H3Index(h3.FromGeo(h3.GeoCoord{Latitude: g.LatDegs(), Longitude: g.LonDegs()}, res))
+
+		r.report.Inputs[CheckGeoToH3]++
+		if got != want {
+			r.diverge(CheckGeoToH3, fmt.Sprintf("%.15f, %.15f, %d", g.LatDegs(), g.LonDegs(), res), got, want)
+		}
+	}
+}
+
+// checkBoundary compares cell boundaries vertex by vertex.
+func (r *runner) checkBoundary() {
+	for i := 0; i < r.opts.N; i++ {
+		cell := r.gen.AnyCell()
+		if i%4 == 0 {
+			cell = r.gen.PentagonAdjacent(r.gen.Res())
+		}
+
+		var gb h3go.GeoBoundary
+		h3go.H3ToGeoBoundary(cell, &gb)
+		got := boundaryDegs(&gb)
+
+		var want [][2]float64
+		for _, v := range h3.ToGeoBoundary(h3.H3Index(cell)) {
+			want = append(want, [2]float64{v.Latitude, v.Longitude})
+		}
+
+		r.report.Inputs[CheckBoundary]++
+		if !sameBoundary(got, want) {
+			r.diverge(CheckBoundary, cell.String(), got, want)
+		}
+	}
+}
+
+// checkKRing compares k-rings as sets, as their order is unspecified.
+func (r *runner) checkKRing() {
+	for i := 0; i < r.opts.N; i++ {
+		origin := r.gen.AnyCell()
+		if i%4 == 0 {
+			origin = r.gen.PentagonAdjacent(r.gen.Res())
+		}
+		k := i % (r.opts.MaxK + 1)
+
+		disk, err := h3go.GridDisk(origin, k)
+		if err != nil {
+			r.report.Inputs[CheckKRing]++
+			r.diverge(CheckKRing, fmt.Sprintf("%s, %d", origin, k), err, nil)
+			continue
+		}
+		got := sortedCells(disk)
+		var want []h3go.H3Index
+		for _, cell := range h3.KRing(h3.H3Index(origin), k) {
+			want = append(want, h3go.H3Index(cell))
+		}
+		want = sortedCells(want)
+
+		r.report.Inputs[CheckKRing]++
+		if !slices.Equal(got, want) {
+			r.diverge(CheckKRing, fmt.Sprintf("%s, %d", origin, k), got, want)
+		}
+	}
+}
+
+// checkCompact compares compaction of the grandchildren of a cell with some
+// of them removed, so that only part of the children compact.
+func (r *runner) checkCompact() {
+	for i := 0; i < r.opts.N; i++ {
+		res := r.gen.Res()
+		if res > h3go.MAX_H3_RES-2 {
+			res = h3go.MAX_H3_RES - 2
+		}
+		parent := r.gen.Cell(res)
+		children := parent.ToChildren(res + 2)
+
+		var set []h3go.H3Index
+		for j, child := range children {
+			if j%(2+i%7) != 0 {
+				set = append(set, child)
+			}
+		}
+
+		compacted, err := h3go.Compact(set)
+		got := sortedCells(compacted)
+
+		in := make([]h3.H3Index, len(set))
+		for j, cell := range set {
+			in[j] = h3.H3Index(cell)
+		}
+		var want []h3go.H3Index
+		for _, cell := range h3.Compact(in) {
+			want = append(want, h3go.H3Index(cell))
+		}
+		want = sortedCells(want)
+
+		r.report.Inputs[CheckCompact]++
+		if err != nil {
+			r.diverge(CheckCompact, parent.String(), err, want)
+		} else if !slices.Equal(got, want) {
+			r.diverge(CheckCompact, parent.String(), got, want)
+		}
+	}
+}
+
+// checkPolyfill compares the cells of polygons with a hole, a few cells
+// across, drawn over the sphere and across the antimeridian.
+func (r *runner) checkPolyfill() {
+	for i := 0; i < r.opts.N; i++ {
+		res := max(r.gen.Res(), 2)
+		radius := float64(1+i%8) * h3go.EdgeLengthKm(res) / h3go.EARTH_RADIUS_KM
+
+		var center h3go.GeoCoord
+		for {
+			if i%2 == 0 {
+				center = r.gen.GeoCoord()
+			} else {
+				center = r.gen.AntimeridianCoord()
+			}
+			if math.Abs(center.LatRads()) < 1.2 {
+				break
+			}
+		}
+		outer := r.gen.Loop(center, radius, 8+i%5)
+		hole := r.gen.Loop(center, radius/4, 8)
+
+		got, err := h3go.PolygonToCells(h3go.NewGeoPolygon(h3go.NewGeofence(outer), h3go.NewGeofence(hole)), res)
+
+		var want []h3go.H3Index
+		for _, cell := range h3.Polyfill(h3.GeoPolygon{
+			Geofence: referenceLoop(outer),
+			Holes:    [][]h3.GeoCoord{referenceLoop(hole)},
+		}, res) {
+			want = append(want, h3go.H3Index(cell))
+		}
+		want = sortedCells(want)
+
+		input := fmt.Sprintf("%.15f, %.15f, %g, %d", center.LatDegs(), center.LonDegs(), radius, res)
+		r.report.Inputs[CheckPolyfill]++
+		if err != nil {
+			r.diverge(CheckPolyfill, input, err, want)
+		} else if !slices.Equal(got, want) {
+			r.diverge(CheckPolyfill, input, got, want)
+		}
+	}
+}
+
+// antimeridianRings are outlines, in degrees of latitude and longitude,
+// of regions crossing the antimeridian.
+var antimeridianRings = []struct {
+	name  string
+	verts [][2]float64
+}{
+	{"fiji", [][2]float64{
+		{-16.1, 178.4}, {-15.7, 179.2}, {-16.1, 179.9}, {-16.6, -179.8},
+		{-17.0, -179.9}, {-16.9, 179.3}, {-17.3, 178.6}, {-16.8, 178.2},
+	}},
+	{"chukotka", [][2]float64{
+		{64.3, 173.0}, {65.5, 170.0}, {69.9, 170.5}, {70.1, 176.0},
+		{69.0, -178.0}, {67.0, -172.0}, {66.0, -169.8}, {65.0, -172.5},
+		{64.5, -175.0}, {65.0, 179.0},
+	}},
+}
+
+// checkAntimeridianRes is the finest resolution checkAntimeridian fills
+// the rings at.
+const checkAntimeridianRes = 6
+
+// checkAntimeridian compares the cells of fixed regions crossing the
+// antimeridian, given both with normalized longitudes and with longitudes
+// running past 180 degrees, which are normalized first, and checks that
+// their size estimate holds.
+func (r *runner) checkAntimeridian() {
+	for _, ring := range antimeridianRings {
+		wrapped := make([]h3go.GeoCoord, len(ring.verts))
+		unwrapped := make([]h3go.GeoCoord, len(ring.verts))
+		for i, v := range ring.verts {
+			wrapped[i] = h3go.NewGeoCoordDegs(v[0], v[1])
+			if v[1] < 0 {
+				v[1] += 360
+			}
+			unwrapped[i] = h3go.NewGeoCoordDegs(v[0], v[1])
+		}
+
+		for res := 0; res <= checkAntimeridianRes; res++ {
+			var want []h3go.H3Index
+			for _, cell := range h3.Polyfill(h3.GeoPolygon{Geofence: referenceLoop(wrapped)}, res) {
+				want = append(want, h3go.H3Index(cell))
+			}
+			want = sortedCells(want)
+
+			for _, input := range []struct {
+				name string
+				poly h3go.GeoPolygon
+			}{
+				{ring.name, h3go.NewGeoPolygon(h3go.NewGeofence(wrapped))},
+				{ring.name + " unwrapped", h3go.NewGeoPolygon(h3go.NormalizeGeofence(h3go.NewGeofence(unwrapped)))},
+			} {
+				got, err := h3go.PolygonToCells(input.poly, res)
+				desc := fmt.Sprintf("%s, %d", input.name, res)
+				r.report.Inputs[CheckAntimeridian]++
+				if err != nil {
+					r.diverge(CheckAntimeridian, desc, err, want)
+				} else if !slices.Equal(got, want) {
+					r.diverge(CheckAntimeridian, desc, got, want)
+				}
+
+				if size, _ := h3go.MaxPolygonToCellsSize(input.poly, res); size < len(want) {
+					r.diverge(CheckAntimeridian, desc+" size", size, len(want))
+				}
+			}
+		}
+	}
+}
+
+// referenceLoop converts a loop to the reference bindings.
+func referenceLoop(loop []h3go.GeoCoord) []h3.GeoCoord {
+	out := make([]h3.GeoCoord, len(loop))
+	for i, v := range loop {
+		out[i] = h3.GeoCoord{Latitude: v.LatDegs(), Longitude: v.LonDegs()}
+	}
+	return out
+}
+
+func boundaryDegs(gb *h3go.GeoBoundary) [][2]float64 {
+	var out [][2]float64
+	for _, v := range gb.Verts() {
+		out = append(out, [2]float64{v.LatDegs(), v.LonDegs()})
+	}
+	return out
+}
+
+func sameBoundary(a, b [][2]float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i][0]-b[i][0]) > boundaryEpsilonDeg ||
+			math.Abs(a[i][1]-b[i][1]) > boundaryEpsilonDeg {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedCells returns the non-null cells sorted in ascending order.
+func sortedCells(cells []h3go.H3Index) []h3go.H3Index {
+	out := make([]h3go.H3Index, 0, len(cells))
+	for _, cell := range cells {
+		if cell != h3go.H3_NULL {
+			out = append(out, cell)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
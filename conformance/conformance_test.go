@@ -0,0 +1,49 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build h3conformance
+
+package conformance_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/isbang/h3go/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	opts := conformance.Options{Seed: 1, N: 10000}
+	if testing.Short() {
+		opts.N = 1000
+	}
+
+	report := conformance.Run(opts)
+	for _, check := range []string{
+		conformance.CheckGeoToH3,
+		conformance.CheckBoundary,
+		conformance.CheckKRing,
+		conformance.CheckCompact,
+		conformance.CheckPolyfill,
+		conformance.CheckAntimeridian,
+	} {
+		if report.Inputs[check] == 0 {
+			t.Errorf("check %s ran on no inputs", check)
+		}
+	}
+	if len(report.Divergences) > 0 {
+		report.WriteTo(os.Stderr)
+		t.Errorf("%d divergences from the reference", len(report.Divergences))
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package conformance cross-checks h3go against the reference C library,
+// through the cgo bindings of github.com/uber/h3-go/v3, over generated
+// inputs, and reports every input on which the two disagree.
+//
+// The harness is a module of its own, which requires the bindings, so that
+// neither cgo nor the bindings become dependencies of h3go. It is only built
+// with the h3conformance build tag:
+//
+//	cd conformance
+//	go test -tags h3conformance ./...
+//
+// A typical test runs the checks and fails on any divergence:
+//
+//	report := conformance.Run(conformance.Options{Seed: 1, N: 10000})
+//	if len(report.Divergences) > 0 {
+//		report.WriteTo(os.Stderr)
+//		t.Fail()
+//	}
+package conformance
@@ -0,0 +1,10 @@
+module github.com/isbang/h3go/conformance
+
+go 1.23
+
+require (
+	github.com/isbang/h3go v0.0.0
+	github.com/uber/h3-go/v3 v3.7.1
+)
+
+replace github.com/isbang/h3go => ../
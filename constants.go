@@ -28,11 +28,13 @@ const (
 
 	// pi / 180
 	M_PI_180 = math.Pi / 180 // 0.0174532925199432957692369076848861271111
-	// pi * 180
-	M_180_PI = math.Pi * 180 // 57.29577951308232087679815481410517033240547
+	// 180 / pi
+	M_180_PI = 180 / math.Pi // 57.29577951308232087679815481410517033240547
 
 	// threshold epsilon
 	EPSILON = 0.0000000000000001
+	// difference between 1.0 and the next representable float64, as DBL_EPSILON
+	DBL_EPSILON = 2.220446049250313080847263336181640625e-16
 	// sqrt(3) / 2.0
 	M_SQRT3_2 = 0.8660254037844386467637231707529361834714
 	// sin(60')
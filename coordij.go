@@ -21,6 +21,21 @@ type CoordIJ struct {
 	j int // j component
 }
 
+// NewCoordIJ returns the IJ coordinates (i, j).
+func NewCoordIJ(i, j int) CoordIJ {
+	return CoordIJ{i: i, j: j}
+}
+
+// I returns the i component.
+func (ij CoordIJ) I() int {
+	return ij.i
+}
+
+// J returns the j component.
+func (ij CoordIJ) J() int {
+	return ij.j
+}
+
 // ToIJK transforms coordinates from the IJ coordinate system to the IJK+
 // coordinate system.
 func (ij *CoordIJ) ToIJK() CoordIJK {
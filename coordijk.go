@@ -128,8 +128,8 @@ func (ijk *CoordIJK) upAp7() {
 	i := ijk.i - ijk.k
 	j := ijk.j - ijk.k
 
-	ijk.i = int(math.Round(float64((3*i - j) / 7.0)))
-	ijk.j = int(math.Round(float64((i + 2*j) / 7.0)))
+	ijk.i = int(math.Round(float64(3*i-j) / 7))
+	ijk.j = int(math.Round(float64(i+2*j) / 7))
 	ijk.k = 0
 	_ijkNormalize(ijk)
 }
@@ -140,8 +140,8 @@ func (ijk *CoordIJK) upAp7r() {
 	i := ijk.i - ijk.k
 	j := ijk.j - ijk.k
 
-	ijk.i = int(math.Round(float64((2*i + j) / 7.0)))
-	ijk.j = int(math.Round(float64((3*j - i) / 7.0)))
+	ijk.i = int(math.Round(float64(2*i+j) / 7))
+	ijk.j = int(math.Round(float64(3*j-i) / 7))
 	ijk.k = 0
 	_ijkNormalize(ijk)
 }
@@ -602,8 +602,8 @@ func _upAp7(ijk *CoordIJK) {
 	i := ijk.i - ijk.k
 	j := ijk.j - ijk.k
 
-	ijk.i = int(math.Round(float64((3*i - j) / 7.0)))
-	ijk.j = int(math.Round(float64((i + 2*j) / 7.0)))
+	ijk.i = int(math.Round(float64(3*i-j) / 7))
+	ijk.j = int(math.Round(float64(i+2*j) / 7))
 	ijk.k = 0
 	_ijkNormalize(ijk)
 }
@@ -617,8 +617,8 @@ func _upAp7r(ijk *CoordIJK) {
 	i := ijk.i - ijk.k
 	j := ijk.j - ijk.k
 
-	ijk.i = int(math.Round(float64((2*i + j) / 7.0)))
-	ijk.j = int(math.Round(float64((3*j - i) / 7.0)))
+	ijk.i = int(math.Round(float64(2*i+j) / 7))
+	ijk.j = int(math.Round(float64(3*j-i) / 7))
 	ijk.k = 0
 	_ijkNormalize(ijk)
 }
@@ -0,0 +1,52 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import "testing"
+
+func TestUpAp7(t *testing.T) {
+	tests := []struct {
+		in, want, wantR CoordIJK
+	}{
+		{CoordIJK{0, 0, 0}, CoordIJK{0, 0, 0}, CoordIJK{0, 0, 0}},
+		{CoordIJK{2, 0, 0}, CoordIJK{1, 0, 0}, CoordIJK{1, 0, 0}},
+		{CoordIJK{0, 2, 0}, CoordIJK{0, 1, 0}, CoordIJK{0, 1, 0}},
+		{CoordIJK{3, 1, 0}, CoordIJK{1, 1, 0}, CoordIJK{1, 0, 0}},
+		{CoordIJK{7, 0, 0}, CoordIJK{3, 1, 0}, CoordIJK{3, 0, 1}},
+	}
+	for _, tt := range tests {
+		got := tt.in
+		_upAp7(&got)
+		if got != tt.want {
+			t.Errorf("_upAp7(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+		got = tt.in
+		got.upAp7()
+		if got != tt.want {
+			t.Errorf("upAp7(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+
+		got = tt.in
+		_upAp7r(&got)
+		if got != tt.wantR {
+			t.Errorf("_upAp7r(%v) = %v, want %v", tt.in, got, tt.wantR)
+		}
+		got = tt.in
+		got.upAp7r()
+		if got != tt.wantR {
+			t.Errorf("upAp7r(%v) = %v, want %v", tt.in, got, tt.wantR)
+		}
+	}
+}
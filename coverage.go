@@ -0,0 +1,483 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// coverageDigitsMask selects the base cell and indexing digits of an index.
+const coverageDigitsMask = H3_BC_MASK | (uint64(1)<<H3_BC_OFFSET - 1)
+
+// cellRange returns the range spanned by the descendants of h when indexes
+// are compared by base cell and digits only. lo has the unused digits of h
+// cleared and hi has them all set, so a cell d is h or a descendant of h
+// exactly when lo <= d.lo and d.hi <= hi.
+func cellRange(h H3Index) (lo, hi uint64) {
+	unused := uint64(1)<<(uint(MAX_H3_RES-H3_GET_RESOLUTION(h))*H3_PER_DIGIT_OFFSET) - 1
+	lo = uint64(h) & coverageDigitsMask &^ unused
+	return lo, lo | unused
+}
+
+// cellContains reports whether h is equal to or an ancestor of d.
+func cellContains(h, d H3Index) bool {
+	lo, hi := cellRange(h)
+	dlo, dhi := cellRange(d)
+	return lo <= dlo && dhi <= hi
+}
+
+// comparePreorder orders cells in pre-order: every cell sorts before its
+// descendants, which sort before the following sibling of the cell.
+func comparePreorder(a, b H3Index) int {
+	alo, _ := cellRange(a)
+	blo, _ := cellRange(b)
+	if c := cmp.Compare(alo, blo); c != 0 {
+		return c
+	}
+	return cmp.Compare(H3_GET_RESOLUTION(a), H3_GET_RESOLUTION(b))
+}
+
+// normalizeCoverage returns the canonical form of the area covered by cells:
+// duplicates and cells covered by their ancestors are dropped, and complete
+// groups of siblings are replaced by their parent. Two sets cover the same
+// area exactly when their canonical forms are equal. H3_NULL entries are
+// ignored.
+//
+// The result is sorted in pre-order, see comparePreorder.
+//
+// Return ErrInvalidCell if any cell is invalid.
+func normalizeCoverage(cells []H3Index) ([]H3Index, error) {
+	out, err := dropCovered(cells)
+	if err != nil {
+		return nil, err
+	}
+	return mergeSiblings(out), nil
+}
+
+// dropCovered returns cells without H3_NULL entries, duplicates and cells
+// covered by their ancestors, sorted in pre-order.
+//
+// Return ErrInvalidCell if any cell is invalid.
+func dropCovered(cells []H3Index) ([]H3Index, error) {
+	sorted := make([]H3Index, 0, len(cells))
+	for _, cell := range cells {
+		if cell == H3_NULL {
+			continue
+		}
+		if !cell.IsValid() {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCell, cell)
+		}
+		sorted = append(sorted, cell)
+	}
+	slices.SortFunc(sorted, comparePreorder)
+
+	// In pre-order a cell covered by an earlier cell is always covered by the
+	// last cell kept, as kept cells are disjoint.
+	out := sorted[:0]
+	for _, cell := range sorted {
+		if len(out) > 0 && cellContains(out[len(out)-1], cell) {
+			continue
+		}
+		out = append(out, cell)
+	}
+	return out, nil
+}
+
+// Canonicalize puts a set of cells into normal form: H3_NULL entries,
+// duplicates and cells whose ancestor is also in the set are dropped, and
+// the rest is sorted in ascending order. Unlike the coverage operations it
+// does not merge complete groups of siblings into their parent, so the
+// resolutions of the cells are preserved; use Compact for that.
+//
+// Return the canonical set, or ErrInvalidCell if any cell is invalid.
+func Canonicalize(cells []H3Index) ([]H3Index, error) {
+	out, err := dropCovered(cells)
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+// CoverageHash returns a 128 bit hash of the area covered by cells, for
+// comparing and caching versions of a coverage without transferring them.
+// Sets covering the same area, as reported by SameCoverage, have the same
+// hash however they are compacted. The hash is the first 16 bytes of the
+// SHA-256 digest of the compacted cells, sorted in ascending order and
+// written as big-endian 64 bit integers, so it is stable across processes,
+// platforms and versions of this package.
+//
+// Return the hash, or ErrInvalidCell if any cell is invalid.
+func CoverageHash(cells []H3Index) ([16]byte, error) {
+	var sum [16]byte
+	normal, err := normalizeCoverage(cells)
+	if err != nil {
+		return sum, err
+	}
+	slices.Sort(normal)
+
+	h := sha256.New()
+	var buf [8]byte
+	for _, cell := range normal {
+		binary.BigEndian.PutUint64(buf[:], uint64(cell))
+		h.Write(buf[:])
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+// CoverageHash64 returns the first 8 bytes of CoverageHash, read as a
+// big-endian integer.
+//
+// Return the hash, or ErrInvalidCell if any cell is invalid.
+func CoverageHash64(cells []H3Index) (uint64, error) {
+	sum, err := CoverageHash(cells)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(sum[:8]), nil
+}
+
+// mergeSiblings replaces complete groups of siblings in disjoint pre-order
+// sorted cells by their parent, finest resolution first so that merged
+// parents can complete groups at the next coarser resolution. A complete
+// group is contiguous in pre-order, starting with the center child.
+func mergeSiblings(cells []H3Index) []H3Index {
+	for res := MAX_H3_RES; res > 0; res-- {
+		out := cells[:0]
+		for i := 0; i < len(cells); i++ {
+			cell := cells[i]
+			if H3_GET_RESOLUTION(cell) == res && H3_GET_INDEX_DIGIT(cell, res) == CENTER_DIGIT {
+				parent := H3ToParent(cell, res-1)
+				if n := completeChildren(parent, cells[i:]); n > 0 {
+					out = append(out, parent)
+					i += n - 1
+					continue
+				}
+			}
+			out = append(out, cell)
+		}
+		cells = out
+	}
+	return cells
+}
+
+// completeChildren returns the number of children of parent if cells starts
+// with all of them in pre-order, or 0 otherwise.
+func completeChildren(parent H3Index, cells []H3Index) int {
+	n := 0
+	for digit := CENTER_DIGIT; digit < INVALID_DIGIT; digit++ {
+		if digit == K_AXES_DIGIT && H3IsPentagon(parent) {
+			continue
+		}
+		if n >= len(cells) || cells[n] != makeDirectChild(parent, digit) {
+			return 0
+		}
+		n++
+	}
+	return n
+}
+
+// searchCoverage returns the index of the first cell of the normalized cells
+// sorting after cell in pre-order, and whether cell is covered by cells. As
+// cells are disjoint, only the cell before that index may cover cell, and the
+// cells from that index up to the end of the range of cell are descendants of
+// cell.
+func searchCoverage(cells []H3Index, cell H3Index) (int, bool) {
+	i := sort.Search(len(cells), func(i int) bool { return comparePreorder(cells[i], cell) > 0 })
+	return i, i > 0 && cellContains(cells[i-1], cell)
+}
+
+// subtractCoverage returns the cells of a not covered by b, where both are
+// normalized. Cells of a are only split into children along the paths
+// leading to the cells of b within them, so the result is normalized too.
+func subtractCoverage(a, b []H3Index) []H3Index {
+	var out []H3Index
+	for _, cell := range a {
+		i, covered := searchCoverage(b, cell)
+		if covered {
+			continue
+		}
+
+		_, hi := cellRange(cell)
+		j := i
+		for j < len(b) {
+			if lo, _ := cellRange(b[j]); lo > hi {
+				break
+			}
+			j++
+		}
+
+		out = subtractDescendants(out, cell, b[i:j])
+	}
+	return out
+}
+
+// subtractDescendants appends the parts of cell not covered by descendants,
+// which are disjoint pre-order sorted descendants of cell, to out.
+func subtractDescendants(out []H3Index, cell H3Index, descendants []H3Index) []H3Index {
+	if len(descendants) == 0 {
+		return append(out, cell)
+	}
+	if descendants[0] == cell {
+		return out
+	}
+
+	for digit := CENTER_DIGIT; digit < INVALID_DIGIT; digit++ {
+		if digit == K_AXES_DIGIT && H3IsPentagon(cell) {
+			continue
+		}
+
+		child := makeDirectChild(cell, digit)
+		_, hi := cellRange(child)
+		n := 0
+		for n < len(descendants) {
+			if lo, _ := cellRange(descendants[n]); lo > hi {
+				break
+			}
+			n++
+		}
+
+		out = subtractDescendants(out, child, descendants[:n])
+		descendants = descendants[n:]
+	}
+	return out
+}
+
+// intersectCoverage returns the cells covered by both a and b, where both are
+// normalized, walking them together in pre-order. Wherever a cell of one set
+// contains cells of the other, the contained cells make up the intersection.
+func intersectCoverage(a, b []H3Index) []H3Index {
+	var out []H3Index
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case cellContains(a[i], b[j]):
+			out = append(out, b[j])
+			j++
+		case cellContains(b[j], a[i]):
+			out = append(out, a[i])
+			i++
+		case comparePreorder(a[i], b[j]) < 0:
+			i++
+		default:
+			j++
+		}
+	}
+	return mergeSiblings(out)
+}
+
+// SameCoverage reports whether the cells of a and b cover exactly the same
+// area. The sets may be compacted differently, mix resolutions, and contain
+// duplicates or cells covered by other cells of the set.
+//
+// Return false if either set contains an invalid cell.
+func SameCoverage(a, b []H3Index) bool {
+	na, nb, err := normalizeCoverages(a, b)
+	if err != nil {
+		return false
+	}
+	return slices.Equal(na, nb)
+}
+
+// CoverageUnion returns the area covered by a or b as a compacted set of
+// cells, sorted in ascending order. The sets may be compacted differently and
+// mix resolutions.
+//
+// Return ErrInvalidCell if either set contains an invalid cell.
+func CoverageUnion(a, b []H3Index) ([]H3Index, error) {
+	union := make([]H3Index, 0, len(a)+len(b))
+	union = append(union, a...)
+	union = append(union, b...)
+
+	out, err := normalizeCoverage(union)
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+// CoverageIntersection returns the area covered by both a and b as a
+// compacted set of cells, sorted in ascending order. Cells are never split:
+// where a cell of one set contains cells of the other, those finer cells are
+// the intersection.
+//
+// Return ErrInvalidCell if either set contains an invalid cell.
+func CoverageIntersection(a, b []H3Index) ([]H3Index, error) {
+	na, nb, err := normalizeCoverages(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	out := intersectCoverage(na, nb)
+	slices.Sort(out)
+	return out, nil
+}
+
+// CoverageDifference returns the area covered by a but not by b as a
+// compacted set of cells, sorted in ascending order. Cells of a are split
+// into children only where they partially overlap cells of b.
+//
+// Return ErrInvalidCell if either set contains an invalid cell.
+func CoverageDifference(a, b []H3Index) ([]H3Index, error) {
+	na, nb, err := normalizeCoverages(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	out := subtractCoverage(na, nb)
+	slices.Sort(out)
+	return out, nil
+}
+
+// CoverageSymmetricDifference returns the area covered by exactly one of a
+// and b as a compacted set of cells, sorted in ascending order. The result is
+// empty exactly when SameCoverage(a, b) is true.
+//
+// Return ErrInvalidCell if either set contains an invalid cell.
+func CoverageSymmetricDifference(a, b []H3Index) ([]H3Index, error) {
+	na, nb, err := normalizeCoverages(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := append(subtractCoverage(na, nb), subtractCoverage(nb, na)...)
+	// The two halves are disjoint, but may complete sibling groups together.
+	slices.SortFunc(diff, comparePreorder)
+	diff = mergeSiblings(diff)
+	slices.Sort(diff)
+	return diff, nil
+}
+
+// normalizeCoverages normalizes both a and b with normalizeCoverage.
+func normalizeCoverages(a, b []H3Index) ([]H3Index, []H3Index, error) {
+	na, err := normalizeCoverage(a)
+	if err != nil {
+		return nil, nil, err
+	}
+	nb, err := normalizeCoverage(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	return na, nb, nil
+}
+
+// CompactedSetsIntersect reports whether the cells of a and b overlap. The
+// sets may be compacted differently and mix resolutions; cells are compared
+// by ancestry after sorting in pre-order, so no cells are expanded and the
+// check runs in O(n log n) time.
+//
+// Return false if either set contains an invalid cell.
+func CompactedSetsIntersect(a, b []H3Index) bool {
+	sa, ok := sortedPreorder(a)
+	if !ok {
+		return false
+	}
+	sb, ok := sortedPreorder(b)
+	if !ok {
+		return false
+	}
+
+	// A cell sorting before another without containing it ends before it in
+	// pre-order, so it cannot overlap any of the following cells either.
+	for i, j := 0, 0; i < len(sa) && j < len(sb); {
+		switch {
+		case cellContains(sa[i], sb[j]), cellContains(sb[j], sa[i]):
+			return true
+		case comparePreorder(sa[i], sb[j]) < 0:
+			i++
+		default:
+			j++
+		}
+	}
+	return false
+}
+
+// sortedPreorder returns a copy of the valid cells sorted in pre-order,
+// ignoring H3_NULL entries, or false if any cell is invalid.
+func sortedPreorder(cells []H3Index) ([]H3Index, bool) {
+	sorted := make([]H3Index, 0, len(cells))
+	for _, cell := range cells {
+		if cell == H3_NULL {
+			continue
+		}
+		if !cell.IsValid() {
+			return nil, false
+		}
+		sorted = append(sorted, cell)
+	}
+	slices.SortFunc(sorted, comparePreorder)
+	return sorted, true
+}
+
+// CoverageContains reports whether the cells of outer cover the whole area of
+// the cells of inner. The sets may be compacted differently and mix
+// resolutions; a cell of inner may be covered by a coarser cell of outer, or
+// by a group of finer cells.
+//
+// Return false if either set contains an invalid cell.
+func CoverageContains(outer, inner []H3Index) bool {
+	no, err := normalizeCoverage(outer)
+	if err != nil {
+		return false
+	}
+
+	// A cell covered by finer cells only is covered by a single cell once the
+	// finer cells are normalized, as complete sibling groups are merged.
+	for _, cell := range inner {
+		if cell == H3_NULL {
+			continue
+		}
+		if !cell.IsValid() {
+			return false
+		}
+		if _, covered := searchCoverage(no, cell); !covered {
+			return false
+		}
+	}
+	return true
+}
+
+// SubtractCoverage returns the coverage of base without the area of remove.
+//
+// Unlike CoverageDifference, the compaction of base is preserved: cells of
+// base not overlapping remove are returned as they are and in their original
+// order, and only cells partially overlapping remove are split, into the
+// coarsest children not overlapping it. H3_NULL entries are ignored.
+//
+// Return ErrInvalidCell if either set contains an invalid cell.
+func SubtractCoverage(base, remove []H3Index) ([]H3Index, error) {
+	nr, err := normalizeCoverage(remove)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]H3Index, 0, len(base))
+	for _, cell := range base {
+		if cell == H3_NULL {
+			continue
+		}
+		if !cell.IsValid() {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCell, cell)
+		}
+		out = append(out, subtractCoverage([]H3Index{cell}, nr)...)
+	}
+	return out, nil
+}
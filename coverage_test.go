@@ -0,0 +1,431 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// coverageRes is the resolution random coverages are expanded to when they
+// are compared with the naive set operations.
+const coverageRes = 4
+
+// coverageRoots returns the resolution 1 cells random coverages are drawn
+// from: the center child of a pentagon base cell, which is a pentagon, and
+// one of its hexagon siblings.
+func coverageRoots() []H3Index {
+	pentagons := make([]H3Index, PentagonIndexCount())
+	GetPentagonIndexes(1, &pentagons)
+	parent := H3ToParent(pentagons[0], 0)
+	return []H3Index{pentagons[0], makeDirectChild(parent, J_AXES_DIGIT)}
+}
+
+// randomCoverage returns n random cells between the resolution of the roots
+// and coverageRes, which may overlap and repeat.
+func randomCoverage(rng *rand.Rand, n int) []H3Index {
+	roots := coverageRoots()
+	cells := make([]H3Index, 0, n)
+	for len(cells) < n {
+		h := roots[rng.IntN(len(roots))]
+		res := H3_GET_RESOLUTION(h) + rng.IntN(coverageRes-H3_GET_RESOLUTION(h)+1)
+		for H3_GET_RESOLUTION(h) < res {
+			h = makeDirectChild(h, Direction(rng.IntN(int(INVALID_DIGIT))))
+		}
+		if H3IsValid(h) {
+			cells = append(cells, h)
+		}
+	}
+	return cells
+}
+
+// reshapeCoverage returns cells covering the same area as cells, compacted
+// differently: some cells are split into their children, repeated, or
+// accompanied by one of their descendants, and the order is shuffled.
+func reshapeCoverage(rng *rand.Rand, cells []H3Index) []H3Index {
+	var out []H3Index
+	for _, cell := range cells {
+		res := H3_GET_RESOLUTION(cell)
+		switch rng.IntN(4) {
+		case 0:
+			if res < coverageRes {
+				H3ToChildren(cell, res+1, &out)
+				continue
+			}
+		case 1:
+			out = append(out, cell)
+		case 2:
+			if res < coverageRes {
+				out = append(out, makeDirectChild(cell, CENTER_DIGIT))
+			}
+		}
+		out = append(out, cell)
+	}
+	rng.Shuffle(len(out), func(i, j int) { out[i], out[j] = out[j], out[i] })
+	return out
+}
+
+// expandCoverage returns the descendants of cells at coverageRes, sorted and
+// without duplicates.
+func expandCoverage(cells []H3Index) []H3Index {
+	var out []H3Index
+	for _, cell := range cells {
+		H3ToChildren(cell, coverageRes, &out)
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// naiveSetOp returns the cells of the sorted sets a and b kept by keep,
+// which is given whether a cell is in a and whether it is in b.
+func naiveSetOp(a, b []H3Index, keep func(inA, inB bool) bool) []H3Index {
+	var out []H3Index
+	for _, cell := range slices.Concat(a, b) {
+		_, inA := slices.BinarySearch(a, cell)
+		_, inB := slices.BinarySearch(b, cell)
+		if keep(inA, inB) {
+			out = append(out, cell)
+		}
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// checkCompacted fails the test unless cells is sorted in ascending order
+// and compacted, covering the area of want at coverageRes.
+func checkCompacted(t *testing.T, name string, cells, want []H3Index) {
+	t.Helper()
+	if !slices.IsSorted(cells) {
+		t.Errorf("%s is not sorted", name)
+	}
+	compacted, err := Compact(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(compacted)
+	if !slices.Equal(cells, compacted) {
+		t.Errorf("%s = %v, want %v", name, cells, compacted)
+	}
+}
+
+func TestSameCoverage(t *testing.T) {
+	pentagon := coverageRoots()[0]
+	var children []H3Index
+	H3ToChildren(pentagon, 2, &children)
+	if len(children) != 6 {
+		t.Fatalf("pentagon %s has %d children, want 6", pentagon, len(children))
+	}
+
+	var split []H3Index // one child replaced by its own children
+	H3ToChildren(children[1], 3, &split)
+	split = append(split, children[0])
+	split = append(split, children[2:]...)
+
+	for _, tt := range []struct {
+		name string
+		a, b []H3Index
+		want bool
+	}{
+		{"children of a pentagon", []H3Index{pentagon}, children, true},
+		{"mixed resolutions", []H3Index{pentagon}, split, true},
+		{"covered and repeated cells", []H3Index{pentagon}, append([]H3Index{children[3], pentagon}, children[3]), true},
+		{"missing child", []H3Index{pentagon}, children[1:], false},
+		{"missing grandchild", children, split[1:], false},
+		{"null entries", []H3Index{H3_NULL, pentagon}, children, true},
+		{"invalid cell", []H3Index{pentagon}, append([]H3Index{H3Index(0x7fffffffffffffff)}, children...), false},
+	} {
+		if got := SameCoverage(tt.a, tt.b); got != tt.want {
+			t.Errorf("SameCoverage for %s = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < 200; i++ {
+		a := randomCoverage(rng, 1+rng.IntN(20))
+		b := reshapeCoverage(rng, a)
+		if !SameCoverage(a, b) {
+			t.Fatalf("SameCoverage(%v, %v) = false for a reshaped set", a, b)
+		}
+
+		c := randomCoverage(rng, 1+rng.IntN(20))
+		want := slices.Equal(expandCoverage(a), expandCoverage(c))
+		if got := SameCoverage(a, c); got != want {
+			t.Fatalf("SameCoverage(%v, %v) = %t, want %t", a, c, got, want)
+		}
+	}
+}
+
+func TestCoverageSymmetricDifference(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	for i := 0; i < 200; i++ {
+		a := randomCoverage(rng, 1+rng.IntN(20))
+		b := randomCoverage(rng, 1+rng.IntN(20))
+		if i%4 == 0 {
+			b = reshapeCoverage(rng, a)
+		}
+
+		got, err := CoverageSymmetricDifference(a, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := naiveSetOp(expandCoverage(a), expandCoverage(b), func(inA, inB bool) bool { return inA != inB })
+		checkCompacted(t, "CoverageSymmetricDifference", got, want)
+		if (len(got) == 0) != SameCoverage(a, b) {
+			t.Fatalf("CoverageSymmetricDifference(%v, %v) = %v, disagreeing with SameCoverage", a, b, got)
+		}
+	}
+}
+
+func TestCoverageSetOperations(t *testing.T) {
+	ops := []struct {
+		name  string
+		op    func(a, b []H3Index) ([]H3Index, error)
+		naive func(inA, inB bool) bool
+	}{
+		{"CoverageUnion", CoverageUnion, func(inA, inB bool) bool { return inA || inB }},
+		{"CoverageIntersection", CoverageIntersection, func(inA, inB bool) bool { return inA && inB }},
+		{"CoverageDifference", CoverageDifference, func(inA, inB bool) bool { return inA && !inB }},
+	}
+
+	rng := rand.New(rand.NewPCG(5, 6))
+	for i := 0; i < 200; i++ {
+		a := randomCoverage(rng, 1+rng.IntN(20))
+		b := randomCoverage(rng, 1+rng.IntN(20))
+		if i%4 == 0 {
+			b = reshapeCoverage(rng, a)
+		}
+		ea, eb := expandCoverage(a), expandCoverage(b)
+
+		for _, op := range ops {
+			got, err := op.op(a, b)
+			if err != nil {
+				t.Fatalf("%s: %v", op.name, err)
+			}
+			checkCompacted(t, op.name, got, naiveSetOp(ea, eb, op.naive))
+		}
+	}
+
+	pentagon := coverageRoots()[0]
+	invalid := []H3Index{pentagon, H3Index(0x7fffffffffffffff)}
+	for _, op := range ops {
+		if _, err := op.op([]H3Index{pentagon}, invalid); !errors.Is(err, ErrInvalidCell) {
+			t.Errorf("%s with an invalid cell error = %v, want ErrInvalidCell", op.name, err)
+		}
+	}
+}
+
+func TestCompactedSetsIntersect(t *testing.T) {
+	rng := rand.New(rand.NewPCG(7, 8))
+	overlaps := 0
+	for i := 0; i < 300; i++ {
+		a := randomCoverage(rng, 1+rng.IntN(6))
+		b := randomCoverage(rng, 1+rng.IntN(6))
+
+		want := len(naiveSetOp(expandCoverage(a), expandCoverage(b), func(inA, inB bool) bool { return inA && inB })) > 0
+		if want {
+			overlaps++
+		}
+		if got := CompactedSetsIntersect(a, b); got != want {
+			t.Fatalf("CompactedSetsIntersect(%v, %v) = %t, want %t", a, b, got, want)
+		}
+		if got := CompactedSetsIntersect(b, a); got != want {
+			t.Fatalf("CompactedSetsIntersect(%v, %v) = %t, want %t", b, a, got, want)
+		}
+	}
+	if overlaps == 0 || overlaps == 300 {
+		t.Errorf("%d of 300 random pairs overlap, want some of both", overlaps)
+	}
+
+	pentagon := coverageRoots()[0]
+	if CompactedSetsIntersect([]H3Index{pentagon}, []H3Index{pentagon, H3Index(0x7fffffffffffffff)}) {
+		t.Error("CompactedSetsIntersect with an invalid cell = true, want false")
+	}
+}
+
+func TestCoverageContains(t *testing.T) {
+	rng := rand.New(rand.NewPCG(9, 10))
+	contained := 0
+	for i := 0; i < 300; i++ {
+		outer := randomCoverage(rng, 1+rng.IntN(20))
+		inner := randomCoverage(rng, 1+rng.IntN(3))
+		if i%3 == 0 {
+			// Finer cells of outer, which are covered by construction.
+			inner = expandCoverage(outer)[:1+rng.IntN(3)]
+		}
+
+		eo, ei := expandCoverage(outer), expandCoverage(inner)
+		want := len(naiveSetOp(ei, eo, func(inA, inB bool) bool { return inA && !inB })) == 0
+		if want {
+			contained++
+		}
+		if got := CoverageContains(outer, inner); got != want {
+			t.Fatalf("CoverageContains(%v, %v) = %t, want %t", outer, inner, got, want)
+		}
+		if got := CoverageContains(reshapeCoverage(rng, outer), inner); got != want {
+			t.Fatalf("CoverageContains of reshaped %v, %v = %t, want %t", outer, inner, got, want)
+		}
+	}
+	if contained == 0 || contained == 300 {
+		t.Errorf("%d of 300 random pairs are contained, want some of both", contained)
+	}
+
+	// A pentagon is covered by its six children, not by five of them.
+	pentagon := coverageRoots()[0]
+	var children []H3Index
+	H3ToChildren(pentagon, 2, &children)
+	if !CoverageContains(children, []H3Index{pentagon}) {
+		t.Errorf("CoverageContains(%v, %s) = false, want true", children, pentagon)
+	}
+	if CoverageContains(children[1:], []H3Index{pentagon}) {
+		t.Errorf("CoverageContains(%v, %s) = true, want false", children[1:], pentagon)
+	}
+}
+
+func TestSubtractCoverage(t *testing.T) {
+	rng := rand.New(rand.NewPCG(11, 12))
+	for i := 0; i < 200; i++ {
+		base := randomCoverage(rng, 1+rng.IntN(20))
+		remove := randomCoverage(rng, 1+rng.IntN(10))
+
+		got, err := SubtractCoverage(base, remove)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := naiveSetOp(expandCoverage(base), expandCoverage(remove), func(inA, inB bool) bool { return inA && !inB })
+		if eg := expandCoverage(got); !slices.Equal(eg, want) {
+			t.Fatalf("SubtractCoverage(%v, %v) = %v, covering %d cells, want %d", base, remove, got, len(eg), len(want))
+		}
+
+		// Each cell of base is handled on its own and in order, and cells
+		// clear of remove are kept as they are.
+		var pieces []H3Index
+		for _, cell := range base {
+			piece, err := SubtractCoverage([]H3Index{cell}, remove)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !CompactedSetsIntersect([]H3Index{cell}, remove) && !slices.Equal(piece, []H3Index{cell}) {
+				t.Fatalf("SubtractCoverage(%s, %v) = %v, want it unchanged", cell, remove, piece)
+			}
+			pieces = append(pieces, piece...)
+		}
+		if !slices.Equal(got, pieces) {
+			t.Fatalf("SubtractCoverage(%v, %v) = %v, want %v", base, remove, got, pieces)
+		}
+	}
+
+	// Removing one child of a pentagon leaves its five other children.
+	pentagon := coverageRoots()[0]
+	var children []H3Index
+	H3ToChildren(pentagon, 2, &children)
+	got, err := SubtractCoverage([]H3Index{pentagon}, children[2:3])
+	if want := slices.Delete(slices.Clone(children), 2, 3); err != nil || !slices.Equal(got, want) {
+		t.Errorf("SubtractCoverage(%s, %s) = %v, %v, want %v", pentagon, children[2], got, err, want)
+	}
+
+	invalid := H3Index(0x7fffffffffffffff)
+	if _, err := SubtractCoverage([]H3Index{pentagon, invalid}, children); !errors.Is(err, ErrInvalidCell) {
+		t.Errorf("SubtractCoverage with an invalid base cell error = %v, want ErrInvalidCell", err)
+	}
+	if _, err := SubtractCoverage([]H3Index{pentagon}, []H3Index{invalid}); !errors.Is(err, ErrInvalidCell) {
+		t.Errorf("SubtractCoverage with an invalid removed cell error = %v, want ErrInvalidCell", err)
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	rng := rand.New(rand.NewPCG(13, 14))
+	for i := 0; i < 200; i++ {
+		cells := randomCoverage(rng, 1+rng.IntN(20))
+		cells = append(cells, H3_NULL, cells[0])
+
+		got, err := Canonicalize(cells)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.IsSorted(got) || len(slices.Compact(slices.Clone(got))) != len(got) {
+			t.Fatalf("Canonicalize(%v) = %v, not sorted or repeating cells", cells, got)
+		}
+		for _, a := range got {
+			for _, b := range got {
+				if a != b && cellContains(a, b) {
+					t.Fatalf("Canonicalize(%v) kept %s and its ancestor %s", cells, b, a)
+				}
+			}
+			if !slices.Contains(cells, a) {
+				t.Fatalf("Canonicalize(%v) produced %s, which is not in the set", cells, a)
+			}
+		}
+		if !SameCoverage(got, cells) {
+			t.Fatalf("Canonicalize(%v) = %v, covering a different area", cells, got)
+		}
+
+		if again, err := Canonicalize(reshapeCoverage(rng, got)); err != nil || !SameCoverage(again, got) {
+			t.Fatalf("Canonicalize of reshaped %v = %v, %v", got, again, err)
+		}
+	}
+
+	// Unlike the coverage operations, the children of a pentagon are not
+	// merged into it.
+	var children []H3Index
+	H3ToChildren(coverageRoots()[0], 2, &children)
+	if got, err := Canonicalize(children); err != nil || !slices.Equal(got, children) {
+		t.Errorf("Canonicalize(%v) = %v, %v, want it unchanged", children, got, err)
+	}
+	if _, err := Canonicalize([]H3Index{H3Index(0x7fffffffffffffff)}); !errors.Is(err, ErrInvalidCell) {
+		t.Errorf("Canonicalize of an invalid cell error = %v, want ErrInvalidCell", err)
+	}
+}
+
+func TestCoverageHash(t *testing.T) {
+	rng := rand.New(rand.NewPCG(15, 16))
+	seen := make(map[[16]byte][]H3Index)
+	for i := 0; i < 200; i++ {
+		cells := randomCoverage(rng, 1+rng.IntN(20))
+		sum, err := CoverageHash(cells)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if again, err := CoverageHash(reshapeCoverage(rng, cells)); err != nil || again != sum {
+			t.Fatalf("CoverageHash of reshaped %v = %x, %v, want %x", cells, again, err, sum)
+		}
+		if other, ok := seen[sum]; ok && !SameCoverage(other, cells) {
+			t.Fatalf("CoverageHash(%v) = CoverageHash(%v), but the coverages differ", cells, other)
+		}
+		seen[sum] = cells
+
+		h64, err := CoverageHash64(cells)
+		if err != nil || h64 != binary.BigEndian.Uint64(sum[:8]) {
+			t.Fatalf("CoverageHash64(%v) = %x, %v, want the first 8 bytes of %x", cells, h64, err, sum)
+		}
+	}
+
+	// A pentagon and its six children cover the same area.
+	pentagon := coverageRoots()[0]
+	var children []H3Index
+	H3ToChildren(pentagon, 2, &children)
+	a, _ := CoverageHash([]H3Index{pentagon})
+	b, _ := CoverageHash(children)
+	c, _ := CoverageHash(children[1:])
+	if a != b || a == c {
+		t.Errorf("CoverageHash of a pentagon, its children and five of them = %x, %x, %x", a, b, c)
+	}
+	if _, err := CoverageHash([]H3Index{H3Index(0x7fffffffffffffff)}); !errors.Is(err, ErrInvalidCell) {
+		t.Errorf("CoverageHash of an invalid cell error = %v, want ErrInvalidCell", err)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// CSVOptions configures the columns written by WriteCellsCSV.
+type CSVOptions struct {
+	// Header writes a first row with the column names.
+	Header bool
+
+	// Boundary adds a column with the cell boundary as a WKT POLYGON in
+	// degrees.
+	Boundary bool
+}
+
+// WriteCellsCSV writes one row per cell to w with the columns h3, lat, lng and
+// res, plus boundary_wkt if opts.Boundary is set. Coordinates are the cell
+// center in degrees.
+//
+// Return ErrInvalidCell if any cell is invalid, or the first write error.
+func WriteCellsCSV(w io.Writer, cells []H3Index, opts CSVOptions) error {
+	cw := csv.NewWriter(w)
+
+	numCols := 4
+	if opts.Boundary {
+		numCols++
+	}
+	row := make([]string, numCols)
+
+	if opts.Header {
+		row[0], row[1], row[2], row[3] = "h3", "lat", "lng", "res"
+		if opts.Boundary {
+			row[4] = "boundary_wkt"
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	var center GeoCoord
+	var gb GeoBoundary
+	for _, cell := range cells {
+		if !cell.IsValid() {
+			return fmt.Errorf("%w: %s", ErrInvalidCell, cell)
+		}
+
+		H3ToGeo(cell, &center)
+		row[0] = cell.String()
+		row[1] = strconv.FormatFloat(RadsToDegs(center.lat), 'f', -1, 64)
+		row[2] = strconv.FormatFloat(RadsToDegs(center.lon), 'f', -1, 64)
+		row[3] = strconv.Itoa(cell.GetResolution())
+		if opts.Boundary {
+			H3ToGeoBoundary(cell, &gb)
+			row[4] = string(appendWKTPolygon(nil, &gb))
+		}
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// appendWKTPolygon appends the boundary as a closed WKT POLYGON in degrees to
+// buf.
+func appendWKTPolygon(buf []byte, gb *GeoBoundary) []byte {
+	buf = append(buf, "POLYGON(("...)
+	for i := 0; i <= gb.numVerts; i++ {
+		if i > 0 {
+			buf = append(buf, ", "...)
+		}
+		v := &gb.verts[i%gb.numVerts]
+		buf = strconv.AppendFloat(buf, RadsToDegs(v.lon), 'f', -1, 64)
+		buf = append(buf, ' ')
+		buf = strconv.AppendFloat(buf, RadsToDegs(v.lat), 'f', -1, 64)
+	}
+	return append(buf, "))"...)
+}
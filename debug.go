@@ -0,0 +1,126 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Markers used by DebugLocalGrid.
+const (
+	debugMarkerOrigin    = 'O'
+	debugMarkerHighlight = '*'
+	debugMarkerPentagon  = 'P'
+	debugMarkerHexagon   = '.'
+	debugMarkerDeleted   = 'x'
+	debugMarkerFailed    = '?'
+)
+
+// DebugLocalGrid renders the local IJ neighborhood of origin within k steps as
+// text, for diagnosing localIJ and pentagon distortion issues.
+//
+// Rows are lines of constant j, with j increasing upwards, and cells are
+// offset so that the rendering resembles the hexagonal grid. Each cell is
+// drawn as one of the following markers:
+//
+//	O  the origin
+//	P  a pentagon
+//	.  a hexagon
+//	x  a deleted subsequence next to a pentagon, which has no index
+//	?  coordinates which could not be unfolded into an index
+func DebugLocalGrid(origin H3Index, k int) string {
+	return DebugLocalGridHighlight(origin, k, H3_NULL)
+}
+
+// DebugLocalGridHighlight is like DebugLocalGrid, but additionally marks the
+// cell highlight with '*'. The highlight marker takes precedence over all
+// others.
+func DebugLocalGridHighlight(origin H3Index, k int, highlight H3Index) string {
+	var sb strings.Builder
+
+	var originIj CoordIJ
+	if k < 0 || !origin.IsValid() {
+		fmt.Fprintf(&sb, "origin %s: invalid\n", origin)
+		return sb.String()
+	}
+	if failed := ExperimentalH3ToLocalIj(origin, origin, &originIj); failed != 0 {
+		fmt.Fprintf(&sb, "origin %s: no local coordinates (code %d)\n", origin, failed)
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "origin %s res %d base cell %d ij (%d, %d)",
+		origin, H3_GET_RESOLUTION(origin), H3_GET_BASE_CELL(origin), originIj.i, originIj.j)
+	if highlight != H3_NULL {
+		var highlightIj CoordIJ
+		if ExperimentalH3ToLocalIj(origin, highlight, &highlightIj) == 0 {
+			fmt.Fprintf(&sb, " highlight %s ij (%d, %d)", highlight, highlightIj.i, highlightIj.j)
+		} else {
+			fmt.Fprintf(&sb, " highlight %s not in local grid", highlight)
+		}
+	}
+	sb.WriteByte('\n')
+
+	// In IJ coordinates the j axis is 120 degrees from the i axis, so a cell
+	// at offset (di, dj) is drawn at column 2*di - dj, which lies within
+	// [-2k, 2k] for all cells within k steps.
+	width := 4*k + 1
+	line := make([]byte, width)
+	var zero CoordIJK
+	for dj := k; dj >= -k; dj-- {
+		for x := range line {
+			line[x] = ' '
+		}
+
+		for di := -k; di <= k; di++ {
+			var offset CoordIJK
+			ijToIjk(&CoordIJ{i: di, j: dj}, &offset)
+			if ijkDistance(&zero, &offset) > k {
+				continue
+			}
+
+			ij := CoordIJ{i: originIj.i + di, j: originIj.j + dj}
+			line[2*di-dj+2*k] = debugLocalGridMarker(origin, &ij, highlight)
+		}
+
+		fmt.Fprintf(&sb, "%+4d  %s\n", dj, strings.TrimRight(string(line), " "))
+	}
+
+	return sb.String()
+}
+
+// debugLocalGridMarker returns the marker for the cell at ij relative to
+// origin.
+func debugLocalGridMarker(origin H3Index, ij *CoordIJ, highlight H3Index) byte {
+	var cell H3Index
+	switch ExperimentalLocalIjToH3(origin, ij, &cell) {
+	case 0:
+	case 3, 4:
+		return debugMarkerDeleted
+	default:
+		return debugMarkerFailed
+	}
+
+	switch {
+	case highlight != H3_NULL && cell == highlight:
+		return debugMarkerHighlight
+	case cell == origin:
+		return debugMarkerOrigin
+	case cell.IsPentagon():
+		return debugMarkerPentagon
+	default:
+		return debugMarkerHexagon
+	}
+}
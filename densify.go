@@ -0,0 +1,61 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import "math"
+
+// DensifyGreatCircle splits the great circle arc from a to b into equal
+// segments no longer than maxSegmentM meters.
+//
+// Return the points along the arc, starting with a and ending with b. If
+// maxSegmentM is not a positive finite number, or either point has a
+// non-finite component, only a and b are returned. Antipodal points are
+// joined along an arbitrary one of the great circles through both.
+func DensifyGreatCircle(a, b GeoCoord, maxSegmentM float64) []GeoCoord {
+	if !(maxSegmentM > 0) || !isFinite(maxSegmentM) ||
+		!isFinite(a.lat) || !isFinite(a.lon) ||
+		!isFinite(b.lat) || !isFinite(b.lon) {
+		return []GeoCoord{a, b}
+	}
+
+	distRads := arcLengthRads(&a, &b)
+	n := int(math.Ceil(distRads * EARTH_RADIUS_KM * 1000 / maxSegmentM))
+	if n <= 1 {
+		return []GeoCoord{a, b}
+	}
+
+	az := _geoAzimuthRads(&a, &b)
+	out := make([]GeoCoord, n+1)
+	out[0] = a
+	for i := 1; i < n; i++ {
+		_geoAzDistanceRads(&a, az, distRads*float64(i)/float64(n), &out[i])
+	}
+	out[n] = b
+	return out
+}
+
+// arcLengthRads returns the great circle distance in radians between a and b.
+// Unlike the haversine formula of PointDistRads it stays well defined for
+// antipodal points.
+func arcLengthRads(a, b *GeoCoord) float64 {
+	var va, vb Vec3d
+	_geoToVec3d(a, &va)
+	_geoToVec3d(b, &vb)
+	cross := math.Sqrt(_square(va.y*vb.z-va.z*vb.y) +
+		_square(va.z*vb.x-va.x*vb.z) +
+		_square(va.x*vb.y-va.y*vb.x))
+	dot := va.x*vb.x + va.y*vb.y + va.z*vb.z
+	return math.Atan2(cross, dot)
+}
@@ -19,5 +19,30 @@ import "errors"
 var (
 	ErrCompactDuplicate     = errors.New("compact duplicated")
 	ErrCompactLoopExceeded  = errors.New("compact loop exceeded")
+	ErrCompactUnsorted      = errors.New("compact input not sorted")
 	ErrUncompactResExceeded = errors.New("uncompact resolution exceeded")
+	ErrInvalidCell          = errors.New("invalid cell index")
+	ErrInvalidIndex         = errors.New("invalid index")
+	ErrInvalidResolution    = errors.New("invalid resolution")
+	ErrInvalidGeoCoord      = errors.New("invalid coordinate")
+	ErrVerifyFailed         = errors.New("verification failed")
+	ErrInvalidFaceIJK       = errors.New("invalid face ijk coordinates")
+	ErrLocalIJFailed        = errors.New("local ij coordinates unavailable")
+	ErrResolutionMismatch   = errors.New("resolution mismatch")
+	ErrGridPathFailed       = errors.New("grid path unavailable")
+	ErrInvalidBaseCell      = errors.New("invalid base cell")
+	ErrInvalidDirection     = errors.New("invalid direction")
+	ErrInvalidK             = errors.New("invalid k")
+	ErrPentagonDistortion   = errors.New("pentagon distortion encountered")
+	ErrInvalidSector        = errors.New("invalid sector")
+	ErrNotNeighbors         = errors.New("cells are not neighbors")
+	ErrInvalidCost          = errors.New("invalid cost")
+	ErrNoPath               = errors.New("no path")
+	ErrInvalidDistance      = errors.New("invalid distance")
+	ErrInvalidAggregation   = errors.New("invalid aggregation")
+	ErrInvalidTolerance     = errors.New("invalid tolerance")
+	ErrInvalidThreshold     = errors.New("invalid threshold")
+	ErrInvalidContainment   = errors.New("invalid containment mode")
+	ErrInvalidPolygon       = errors.New("invalid polygon")
+	ErrBudgetExceeded       = errors.New("cell budget exceeded")
 )
@@ -0,0 +1,107 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import "sync/atomic"
+
+// Event identifies a notable event inside the library, worth counting to
+// watch the performance and data quality of an H3-heavy service.
+type Event int
+
+const (
+	// A k-ring traversal hit a pentagon and fell back to the slow recursive
+	// algorithm, or GridDistance searched the grid because the local
+	// coordinates could not be computed.
+	EventPentagonFallback Event = iota
+	// Local IJ coordinates could not be computed for an index.
+	EventLocalIJFailure
+	// H3Line or GridPathCells split a line it could not draw in one piece.
+	EventLineSplit
+	// Compact went through one resolution level of its input.
+	EventCompactPass
+	// A polygon traversal examined a cell.
+	EventPolyfillCellVisited
+
+	numEvents
+)
+
+var eventNames = [numEvents]string{
+	EventPentagonFallback:    "pentagon_fallback",
+	EventLocalIJFailure:      "local_ij_failure",
+	EventLineSplit:           "line_split",
+	EventCompactPass:         "compact_pass",
+	EventPolyfillCellVisited: "polyfill_cell_visited",
+}
+
+// String returns the snake case name of the event.
+func (e Event) String() string {
+	if e < 0 || e >= numEvents {
+		return "unknown"
+	}
+	return eventNames[e]
+}
+
+var (
+	eventCounts [numEvents]atomic.Uint64
+	eventHook   atomic.Pointer[func(Event, int)]
+)
+
+// SetEventHook installs hook to be called synchronously, from the goroutine
+// that caused it, every time an event occurs, with the number of occurrences
+// being reported at once. hook must be safe for concurrent use and should
+// return quickly. A nil hook removes the installed one.
+func SetEventHook(hook func(ev Event, n int)) {
+	if hook == nil {
+		eventHook.Store(nil)
+		return
+	}
+	eventHook.Store(&hook)
+}
+
+// EventCount returns the number of times ev has occurred since the program
+// started or the counters were last reset.
+func EventCount(ev Event) uint64 {
+	if ev < 0 || ev >= numEvents {
+		return 0
+	}
+	return eventCounts[ev].Load()
+}
+
+// EventCounts returns the current value of every event counter, keyed by
+// event name. It can be published with the expvar package:
+//
+//	expvar.Publish("h3", expvar.Func(func() any { return h3go.EventCounts() }))
+func EventCounts() map[string]uint64 {
+	counts := make(map[string]uint64, numEvents)
+	for ev := Event(0); ev < numEvents; ev++ {
+		counts[ev.String()] = eventCounts[ev].Load()
+	}
+	return counts
+}
+
+// ResetEventCounts sets every event counter back to zero.
+func ResetEventCounts() {
+	for ev := range eventCounts {
+		eventCounts[ev].Store(0)
+	}
+}
+
+// recordEvent counts n occurrences of ev and reports them to the hook.
+func recordEvent(ev Event, n int) {
+	eventCounts[ev].Add(uint64(n))
+	if hook := eventHook.Load(); hook != nil {
+		(*hook)(ev, n)
+	}
+}
@@ -420,35 +420,8 @@ func _hex2dToGeo(v *Vec2d, face int, res int, substrate bool, g *GeoCoord) {
 
 	theta := math.Atan2(v.y, v.x)
 
-	// scale for current resolution length u
-	for i := 0; i < res; i++ {
-		r /= M_SQRT7
-	}
-
-	// scale accordingly if this is a substrate grid
-	if substrate {
-		r /= 3.0
-		if isResClassIII(res) {
-			r /= M_SQRT7
-		}
-	}
-
-	r *= RES0_U_GNOMONIC
-
-	// perform inverse gnomonic scaling of r
-	r = math.Atan(r)
-
-	// adjust theta for Class III
-	// if a substrate grid, then it's already been adjusted for Class III
-	if !substrate && isResClassIII(res) {
-		theta = _posAngleRads(theta + M_AP7_ROT_RADS)
-	}
-
-	// find theta as an azimuth
-	theta = _posAngleRads(faceAxesAzRadsCII[face][0] - theta)
-
 	// now find the point at (r,theta) from the face center
-	_geoAzDistanceRads(&faceCenterGeo[face], theta, r, g)
+	hex2dPolarToGeo(face, res, substrate, r, theta, g)
 }
 
 // _faceIjkToGeo determines the center point in spherical coordinates of a cell
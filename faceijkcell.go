@@ -0,0 +1,71 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import "fmt"
+
+// CellToFaceIJK determines the icosahedron face and the ijk coordinates on
+// that face's coordinate system of a cell, at the cell's resolution. The face
+// is the cell's home face, the one its center lies on.
+//
+// Return the face number and the normalized ijk coordinates, or
+// ErrInvalidCell if cell is not a valid cell index.
+func CellToFaceIJK(cell H3Index) (face int, i, j, k int, err error) {
+	if !H3IsValid(cell) {
+		return 0, 0, 0, 0, fmt.Errorf("%w: %s", ErrInvalidCell, cell)
+	}
+
+	var fijk FaceIJK
+	_h3ToFaceIjk(cell, &fijk)
+	return fijk.face, fijk.coord.i, fijk.coord.j, fijk.coord.k, nil
+}
+
+// maximum magnitude of an ijk component accepted by FaceIJKToCell; anything
+// larger is far off the face at every resolution and would overflow the
+// aperture 7 arithmetic
+const maxFaceIJKCoord = 1 << 40
+
+// FaceIJKToCell determines the cell at the given resolution whose ijk
+// coordinates on the given face's coordinate system are (i, j, k). The
+// coordinates need not be normalized, and may fall in the overage area past
+// the face's edges, in which case the cell belongs to a neighboring face.
+//
+// Return the cell, ErrInvalidResolution if res is out of range, or
+// ErrInvalidFaceIJK if the face is out of range or the coordinates do not
+// address a cell.
+func FaceIJKToCell(face, i, j, k, res int) (H3Index, error) {
+	if res < 0 || res > MAX_H3_RES {
+		return H3_NULL, fmt.Errorf("%w: %d", ErrInvalidResolution, res)
+	}
+	if face < 0 || face >= NUM_ICOSA_FACES {
+		return H3_NULL, fmt.Errorf("%w: face %d", ErrInvalidFaceIJK, face)
+	}
+	for _, c := range [3]int{i, j, k} {
+		if c > maxFaceIJKCoord || c < -maxFaceIJKCoord {
+			return H3_NULL, fmt.Errorf("%w: coordinate %d out of range",
+				ErrInvalidFaceIJK, c)
+		}
+	}
+
+	fijk := FaceIJK{face: face, coord: CoordIJK{i: i, j: j, k: k}}
+	_ijkNormalize(&fijk.coord)
+
+	cell := _faceIjkToH3(&fijk, res)
+	if cell == H3_NULL || !H3IsValid(cell) {
+		return H3_NULL, fmt.Errorf("%w: face %d (%d, %d, %d) at resolution %d",
+			ErrInvalidFaceIJK, face, i, j, k, res)
+	}
+	return cell, nil
+}
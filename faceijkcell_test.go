@@ -0,0 +1,74 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestFaceIJKToCellRoundTrip(t *testing.T) {
+	var cells []H3Index
+	for res := 0; res <= MAX_H3_RES; res++ {
+		pentagons := make([]H3Index, PentagonIndexCount())
+		GetPentagonIndexes(res, &pentagons)
+		cells = append(cells, pentagons...)
+	}
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < 2000; i++ {
+		g := NewGeoCoordDegs(RadsToDegs(math.Asin(2*rng.Float64()-1)), 360*rng.Float64()-180)
+		cells = append(cells, GeoToH3(&g, rng.IntN(MAX_H3_RES+1)))
+	}
+
+	for _, cell := range cells {
+		face, i, j, k, err := CellToFaceIJK(cell)
+		if err != nil {
+			t.Fatalf("CellToFaceIJK(%s): %v", cell, err)
+		}
+		res := H3_GET_RESOLUTION(cell)
+		if got, err := FaceIJKToCell(face, i, j, k, res); err != nil || got != cell {
+			t.Fatalf("FaceIJKToCell(%d, %d, %d, %d, %d) = %s, %v, want %s", face, i, j, k, res, got, err, cell)
+		}
+		// Adding the same amount to every component does not move the cell.
+		if got, err := FaceIJKToCell(face, i+3, j+3, k+3, res); err != nil || got != cell {
+			t.Fatalf("FaceIJKToCell(%d, %d, %d, %d, %d) = %s, %v, want %s", face, i+3, j+3, k+3, res, got, err, cell)
+		}
+	}
+}
+
+func TestFaceIJKToCellErrors(t *testing.T) {
+	for _, tt := range []struct {
+		face, i, j, k, res int
+		want               error
+	}{
+		{0, 0, 0, 0, -1, ErrInvalidResolution},
+		{0, 0, 0, 0, MAX_H3_RES + 1, ErrInvalidResolution},
+		{-1, 0, 0, 0, 0, ErrInvalidFaceIJK},
+		{NUM_ICOSA_FACES, 0, 0, 0, 0, ErrInvalidFaceIJK},
+		{0, maxFaceIJKCoord + 1, 0, 0, 5, ErrInvalidFaceIJK},
+		{0, 0, -maxFaceIJKCoord - 1, 0, 5, ErrInvalidFaceIJK},
+	} {
+		if _, err := FaceIJKToCell(tt.face, tt.i, tt.j, tt.k, tt.res); !errors.Is(err, tt.want) {
+			t.Errorf("FaceIJKToCell(%d, %d, %d, %d, %d) error = %v, want %v",
+				tt.face, tt.i, tt.j, tt.k, tt.res, err, tt.want)
+		}
+	}
+
+	if _, _, _, _, err := CellToFaceIJK(H3Index(0x7fffffffffffffff)); !errors.Is(err, ErrInvalidCell) {
+		t.Errorf("CellToFaceIJK of an invalid cell error = %v, want ErrInvalidCell", err)
+	}
+}
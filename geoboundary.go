@@ -23,3 +23,13 @@ type GeoBoundary struct {
 	numVerts int                            // number of vertices
 	verts    [MAX_CELL_BNDRY_VERTS]GeoCoord // vertices in ccw order
 }
+
+// NumVerts returns the number of vertices of the boundary.
+func (gb *GeoBoundary) NumVerts() int {
+	return gb.numVerts
+}
+
+// Verts returns the vertices of the boundary in counter-clockwise order.
+func (gb *GeoBoundary) Verts() []GeoCoord {
+	return gb.verts[:gb.numVerts]
+}
@@ -14,7 +14,10 @@
 
 package h3go
 
-import "math"
+import (
+	"fmt"
+	"math"
+)
 
 const (
 	// epsilon of ~0.1mm in degrees
@@ -30,6 +33,28 @@ type GeoCoord struct {
 	lon float64 // longitude in radians
 }
 
+// NewGeoCoordDegs returns spherical coordinates from decimal degrees.
+func NewGeoCoordDegs(latDegs, lonDegs float64) GeoCoord {
+	return GeoCoord{lat: DegsToRads(latDegs), lon: DegsToRads(lonDegs)}
+}
+
+// NewGeoCoordRads returns spherical coordinates from radians.
+func NewGeoCoordRads(latRads, lonRads float64) GeoCoord {
+	return GeoCoord{lat: latRads, lon: lonRads}
+}
+
+// LatRads returns the latitude in radians.
+func (p GeoCoord) LatRads() float64 { return p.lat }
+
+// LonRads returns the longitude in radians.
+func (p GeoCoord) LonRads() float64 { return p.lon }
+
+// LatDegs returns the latitude in decimal degrees.
+func (p GeoCoord) LatDegs() float64 { return RadsToDegs(p.lat) }
+
+// LonDegs returns the longitude in decimal degrees.
+func (p GeoCoord) LonDegs() float64 { return RadsToDegs(p.lon) }
+
 // _posAngleRads normalizes radians to a value between 0.0 and two PI.
 //
 // Return The normalized radians value.
@@ -353,37 +378,47 @@ func EdgeLengthM(res int) float64 {
 	return lens[res]
 }
 
+// numCells holds the number of cells, including the 12 pentagons, at each
+// resolution. The table comes from the recurrence:
+//
+//	num_cells(0) = 122
+//	num_cells(i+1) = (num_cells(i)-12)*7 + 12*6
+var numCells = [MAX_H3_RES + 1]int64{
+	122,
+	842,
+	5882,
+	41162,
+	288122,
+	2016842,
+	14117882,
+	98825162,
+	691776122,
+	4842432842,
+	33897029882,
+	237279209162,
+	1660954464122,
+	11626681248842,
+	81386768741882,
+	569707381193162,
+}
+
 // NumHexagons returns number of unique valid H3Indexes at given resolution.
+// It panics if res is out of range.
+//
+// Deprecated: Use NumCells instead.
 func NumHexagons(res int) int64 {
-	/**
-	 * Note: this *actually* returns the number of *cells*
-	 * (which includes the 12 pentagons) at each resolution.
-	 *
-	 * This table comes from the recurrence:
-	 *
-	 *  num_cells(0) = 122
-	 *  num_cells(i+1) = (num_cells(i)-12)*7 + 12*6
-	 *
-	 */
-	var nums = [...]int64{
-		122,
-		842,
-		5882,
-		41162,
-		288122,
-		2016842,
-		14117882,
-		98825162,
-		691776122,
-		4842432842,
-		33897029882,
-		237279209162,
-		1660954464122,
-		11626681248842,
-		81386768741882,
-		569707381193162,
+	return numCells[res]
+}
+
+// NumCells returns the number of cells at res, pentagons included. The
+// counts exceed the range of an int32 from resolution 9 on.
+//
+// Return the count, or ErrInvalidResolution if res is out of range.
+func NumCells(res int) (int64, error) {
+	if res < 0 || res > MAX_H3_RES {
+		return 0, fmt.Errorf("%w: %d", ErrInvalidResolution, res)
 	}
-	return nums[res]
+	return numCells[res], nil
 }
 
 // triangleEdgeLengthsToArea calculates surface area in radians^2 of spherical
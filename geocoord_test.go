@@ -0,0 +1,31 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRadsToDegs(t *testing.T) {
+	if got := RadsToDegs(math.Pi); math.Abs(got-180) > 1e-12 {
+		t.Errorf("RadsToDegs(pi) = %v, want 180", got)
+	}
+	for _, degs := range []float64{-180, -37.5, 0, 1, 90, 123.456} {
+		if got := RadsToDegs(DegsToRads(degs)); math.Abs(got-degs) > 1e-12 {
+			t.Errorf("RadsToDegs(DegsToRads(%v)) = %v", degs, got)
+		}
+	}
+}
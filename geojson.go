@@ -0,0 +1,219 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strconv"
+)
+
+// StreamCellsGeoJSON writes the cells yielded by seq to w as a GeoJSON
+// FeatureCollection. Every cell becomes a Polygon feature whose id is the
+// string form of the index.
+//
+// Features are encoded and flushed one at a time, so memory usage does not
+// grow with the number of cells. props may be nil; otherwise it is called once
+// per cell and its result is written as the feature properties.
+//
+// Return an error if a cell is invalid, if props cannot be encoded or if
+// writing to w fails. The output is incomplete in that case.
+func StreamCellsGeoJSON(w io.Writer, seq iter.Seq[H3Index], props func(H3Index) map[string]any) error {
+	bw := bufio.NewWriter(w)
+
+	if _, err := bw.WriteString(`{"type":"FeatureCollection","features":[`); err != nil {
+		return err
+	}
+
+	var buf []byte
+	var err error
+	first := true
+	for cell := range seq {
+		if !cell.IsValid() {
+			err = fmt.Errorf("%w: %s", ErrInvalidCell, cell)
+			break
+		}
+
+		buf = buf[:0]
+		if !first {
+			buf = append(buf, ',')
+		}
+		first = false
+
+		if buf, err = appendCellFeature(buf, cell, props); err != nil {
+			break
+		}
+		if _, err = bw.Write(buf); err != nil {
+			break
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := bw.WriteString("]}\n"); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// appendCellFeature appends the GeoJSON Feature of a cell to buf.
+func appendCellFeature(buf []byte, cell H3Index, props func(H3Index) map[string]any) ([]byte, error) {
+	buf = append(buf, `{"type":"Feature","id":"`...)
+	buf = strconv.AppendUint(buf, uint64(cell), 16)
+	buf = append(buf, `","geometry":`...)
+
+	var gb GeoBoundary
+	H3ToGeoBoundary(cell, &gb)
+	buf = appendGeoJSONPolygon(buf, &gb)
+
+	buf = append(buf, `,"properties":`...)
+	if props == nil {
+		buf = append(buf, "null"...)
+	} else {
+		p, err := json.Marshal(props(cell))
+		if err != nil {
+			return buf, err
+		}
+		buf = append(buf, p...)
+	}
+
+	return append(buf, '}'), nil
+}
+
+// appendGeoJSONPolygon appends a GeoJSON Polygon geometry of the boundary to
+// buf. The ring is closed by repeating the first vertex, and coordinates are
+// written as [lng, lat] pairs in degrees.
+func appendGeoJSONPolygon(buf []byte, gb *GeoBoundary) []byte {
+	buf = append(buf, `{"type":"Polygon","coordinates":[[`...)
+	for i := 0; i <= gb.numVerts; i++ {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendGeoJSONPosition(buf, &gb.verts[i%gb.numVerts])
+	}
+	return append(buf, "]]}"...)
+}
+
+// appendGeoJSONPosition appends a [lng, lat] position in degrees to buf.
+func appendGeoJSONPosition(buf []byte, g *GeoCoord) []byte {
+	buf = append(buf, '[')
+	buf = strconv.AppendFloat(buf, RadsToDegs(g.lon), 'f', -1, 64)
+	buf = append(buf, ',')
+	buf = strconv.AppendFloat(buf, RadsToDegs(g.lat), 'f', -1, 64)
+	return append(buf, ']')
+}
+
+// MultiPolygonOptions configures CellsToMultiPolygonWithOptions. The zero
+// value gives the output of CellsToMultiPolygon.
+type MultiPolygonOptions struct {
+	// EnforceWinding reverses any exterior ring that is not counter-clockwise
+	// and any hole that is not clockwise, following the right-hand rule of
+	// RFC 7946.
+	EnforceWinding bool
+
+	// CloseRings repeats the first vertex of every ring at its end, as
+	// GeoJSON requires. The polygon functions accept closed rings, the
+	// repeated vertex adding an edge of zero length.
+	CloseRings bool
+}
+
+// CellsToMultiPolygonWithOptions outlines a set of cells as
+// CellsToMultiPolygon does, adjusting the rings according to opts. With both
+// options set the rings can be handed to GeoJSON consumers as they are, see
+// AppendGeoJSONMultiPolygon.
+//
+// Return the polygons, or the errors of CellsToMultiPolygon.
+func CellsToMultiPolygonWithOptions(cells []H3Index, opts MultiPolygonOptions) ([]GeoPolygon, error) {
+	polys, err := CellsToMultiPolygon(cells)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range polys {
+		poly := &polys[i]
+		if opts.EnforceWinding {
+			if isClockwiseGeofence(&poly.geofence) {
+				reverseGeofence(&poly.geofence)
+			}
+			for j := 0; j < poly.numHoles; j++ {
+				if !isClockwiseGeofence(&poly.holes[j]) {
+					reverseGeofence(&poly.holes[j])
+				}
+			}
+		}
+		if opts.CloseRings {
+			closeGeofence(&poly.geofence)
+			for j := 0; j < poly.numHoles; j++ {
+				closeGeofence(&poly.holes[j])
+			}
+		}
+	}
+	return polys, nil
+}
+
+// reverseGeofence reverses the order of the vertices of geofence in place.
+func reverseGeofence(geofence *Geofence) {
+	slices.Reverse(geofence.verts[:geofence.numVerts])
+}
+
+// closeGeofence appends the first vertex of geofence to its end, unless it is
+// already there.
+func closeGeofence(geofence *Geofence) {
+	if geofence.numVerts == 0 || geofence.verts[0] == geofence.verts[geofence.numVerts-1] {
+		return
+	}
+	geofence.verts = append(geofence.verts[:geofence.numVerts], geofence.verts[0])
+	geofence.numVerts++
+}
+
+// AppendGeoJSONMultiPolygon appends a GeoJSON MultiPolygon geometry of polys
+// to buf. Rings are written as they are, with coordinates as [lng, lat]
+// pairs in degrees, so they should be closed and wound as GeoJSON requires,
+// see MultiPolygonOptions.
+//
+// Return the extended buffer.
+func AppendGeoJSONMultiPolygon(buf []byte, polys []GeoPolygon) []byte {
+	buf = append(buf, `{"type":"MultiPolygon","coordinates":[`...)
+	for i := range polys {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, '[')
+		buf = appendGeoJSONRing(buf, &polys[i].geofence)
+		for j := 0; j < polys[i].numHoles; j++ {
+			buf = append(buf, ',')
+			buf = appendGeoJSONRing(buf, &polys[i].holes[j])
+		}
+		buf = append(buf, ']')
+	}
+	return append(buf, "]}"...)
+}
+
+// appendGeoJSONRing appends the positions of a ring to buf.
+func appendGeoJSONRing(buf []byte, geofence *Geofence) []byte {
+	buf = append(buf, '[')
+	for i := 0; i < geofence.numVerts; i++ {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendGeoJSONPosition(buf, &geofence.verts[i])
+	}
+	return append(buf, ']')
+}
@@ -1,3 +1,3 @@
 module github.com/isbang/h3go
 
-go 1.17
+go 1.23
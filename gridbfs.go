@@ -0,0 +1,271 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import "slices"
+
+// appendNeighbors appends the cells adjacent to cell to dst, skipping the
+// deleted direction of pentagons.
+func appendNeighbors(dst []H3Index, cell H3Index) []H3Index {
+	for _, neighbor := range kRing(cell, 1) {
+		if neighbor != H3_NULL && neighbor != cell {
+			dst = append(dst, neighbor)
+		}
+	}
+	return dst
+}
+
+// DistanceTransform returns, for every cell within maxK grid steps of the
+// cells of set, its grid distance to the nearest cell of set. Members of set
+// have distance 0.
+//
+// The distances are computed with a single breadth-first search started from
+// all members at once, so the cost is proportional to the size of the result
+// rather than to the number of members times the size of a k-ring.
+func DistanceTransform(set CellSet, maxK int) map[H3Index]int {
+	dist := make(map[H3Index]int, len(set))
+	frontier := make([]H3Index, 0, len(set))
+	for cell := range set {
+		dist[cell] = 0
+		frontier = append(frontier, cell)
+	}
+
+	var next, neighbors []H3Index
+	for d := 1; d <= maxK && len(frontier) > 0; d++ {
+		next = next[:0]
+		for _, cell := range frontier {
+			neighbors = appendNeighbors(neighbors[:0], cell)
+			for _, neighbor := range neighbors {
+				if _, ok := dist[neighbor]; ok {
+					continue
+				}
+				dist[neighbor] = d
+				next = append(next, neighbor)
+			}
+		}
+		frontier, next = next, frontier
+	}
+
+	return dist
+}
+
+// AssignToNearestSeed partitions region by assigning each of its cells to the
+// seed closest to it by grid distance, walking only through cells of region.
+// Cells equally close to several seeds go to the seed with the smallest
+// index, so the result does not depend on the order of seeds. Cells which
+// cannot be reached from any seed are left out, as are seeds not in region.
+func AssignToNearestSeed(seeds []H3Index, region CellSet) map[H3Index]H3Index {
+	owner := make(map[H3Index]H3Index, len(region))
+	frontier := make([]H3Index, 0, len(seeds))
+	for _, seed := range seeds {
+		if !region.Has(seed) {
+			continue
+		}
+		if _, ok := owner[seed]; !ok {
+			frontier = append(frontier, seed)
+		}
+		owner[seed] = seed
+	}
+
+	// Each cell takes the smallest owner among its neighbors in the previous
+	// ring, which is the smallest among the seeds at the same distance.
+	var neighbors []H3Index
+	for len(frontier) > 0 {
+		reached := make(map[H3Index]H3Index)
+		for _, cell := range frontier {
+			seed := owner[cell]
+			neighbors = appendNeighbors(neighbors[:0], cell)
+			for _, neighbor := range neighbors {
+				if !region.Has(neighbor) {
+					continue
+				}
+				if _, ok := owner[neighbor]; ok {
+					continue
+				}
+				if prev, ok := reached[neighbor]; !ok || seed < prev {
+					reached[neighbor] = seed
+				}
+			}
+		}
+
+		frontier = frontier[:0]
+		for cell, seed := range reached {
+			owner[cell] = seed
+			frontier = append(frontier, cell)
+		}
+	}
+
+	return owner
+}
+
+// ClusterCells groups cells with DBSCAN, using grid distance as the metric.
+//
+// The neighborhood of a cell is the set of cells within k grid steps of it,
+// including itself. Cells with at least minPts cells in their neighborhood are
+// core cells; clusters are formed by core cells reachable from each other
+// through neighborhoods, together with the non-core cells in their
+// neighborhoods. Cells belonging to no cluster are noise and are left out.
+//
+// Duplicates are ignored. Each cluster is sorted in ascending order, and
+// clusters are ordered by their smallest core cell, so the result does not
+// depend on the order of cells.
+func ClusterCells(cells []H3Index, k int, minPts int) [][]H3Index {
+	set := NewCellSet(cells...)
+	sorted := set.Cells()
+
+	neighborhood := func(cell H3Index) []H3Index {
+		var out []H3Index
+		for _, c := range kRing(cell, k) {
+			if c != H3_NULL && set.Has(c) {
+				out = append(out, c)
+			}
+		}
+		return out
+	}
+
+	const unvisited, noise = 0, -1
+	label := make(map[H3Index]int, len(sorted))
+
+	var clusters [][]H3Index
+	for _, cell := range sorted {
+		if label[cell] != unvisited {
+			continue
+		}
+
+		neighbors := neighborhood(cell)
+		if len(neighbors) < minPts {
+			label[cell] = noise
+			continue
+		}
+
+		id := len(clusters) + 1
+		label[cell] = id
+		cluster := []H3Index{cell}
+
+		queue := neighbors
+		for len(queue) > 0 {
+			c := queue[0]
+			queue = queue[1:]
+
+			switch label[c] {
+			case noise:
+				// Border cell, reachable but not core.
+				label[c] = id
+				cluster = append(cluster, c)
+				continue
+			case unvisited:
+				label[c] = id
+				cluster = append(cluster, c)
+			default:
+				continue
+			}
+
+			if n := neighborhood(c); len(n) >= minPts {
+				queue = append(queue, n...)
+			}
+		}
+
+		slices.Sort(cluster)
+		clusters = append(clusters, cluster)
+	}
+
+	return clusters
+}
+
+// ConnectedComponents splits set into its connected components, the groups
+// of cells reachable from each other through neighboring cells of set. Each
+// component is sorted in ascending order, and components are ordered by their
+// smallest cell.
+func ConnectedComponents(set CellSet) [][]H3Index {
+	visited := make(CellSet, len(set))
+	var components [][]H3Index
+	for _, cell := range set.Cells() {
+		if visited.Has(cell) {
+			continue
+		}
+		component := floodFill(set, cell, visited)
+		slices.Sort(component)
+		components = append(components, component)
+	}
+	return components
+}
+
+// FloodFill returns the cells of set reachable from seed through neighboring
+// cells of set, including seed itself, sorted in ascending order. Cells of a
+// coverage that a flood fill from its outer border does not reach enclose
+// holes.
+//
+// Return nil if seed is not in set.
+func FloodFill(set CellSet, seed H3Index) []H3Index {
+	if !set.Has(seed) {
+		return nil
+	}
+	out := floodFill(set, seed, NewCellSet())
+	slices.Sort(out)
+	return out
+}
+
+// gridDistanceSearch finds the grid distance from origin to target with a
+// breadth-first search, giving up after maxK steps. Unlike the local IJ
+// coordinates it is not affected by pentagon distortion, but its cost grows
+// with the square of the distance.
+//
+// Return the distance, or -1 if target is not within maxK steps.
+func gridDistanceSearch(origin, target H3Index, maxK int) int {
+	if origin == target {
+		return 0
+	}
+
+	seen := NewCellSet(origin)
+	frontier := []H3Index{origin}
+	var next, neighbors []H3Index
+	for d := 1; d <= maxK && len(frontier) > 0; d++ {
+		next = next[:0]
+		for _, cell := range frontier {
+			neighbors = appendNeighbors(neighbors[:0], cell)
+			for _, neighbor := range neighbors {
+				if neighbor == target {
+					return d
+				}
+				if seen.Has(neighbor) {
+					continue
+				}
+				seen.Add(neighbor)
+				next = append(next, neighbor)
+			}
+		}
+		frontier, next = next, frontier
+	}
+
+	return -1
+}
+
+// floodFill collects the cells of set reachable from seed through neighboring
+// cells of set, skipping and extending visited. seed must be in set.
+func floodFill(set CellSet, seed H3Index, visited CellSet) []H3Index {
+	visited.Add(seed)
+	out := []H3Index{seed}
+	var neighbors []H3Index
+	for i := 0; i < len(out); i++ {
+		neighbors = appendNeighbors(neighbors[:0], out[i])
+		for _, neighbor := range neighbors {
+			if set.Has(neighbor) && !visited.Has(neighbor) {
+				visited.Add(neighbor)
+				out = append(out, neighbor)
+			}
+		}
+	}
+	return out
+}
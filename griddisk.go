@@ -0,0 +1,470 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"slices"
+)
+
+// MaxGridDiskSize returns the maximum number of cells within k grid steps of
+// an origin, which is the exact number unless the disk contains a pentagon.
+//
+// Return the size, or ErrInvalidK if k is negative or so large that the size
+// overflows an int.
+func MaxGridDiskSize(k int) (int, error) {
+	if k < 0 {
+		return 0, fmt.Errorf("%w: %d is negative", ErrInvalidK, k)
+	}
+	if k > (math.MaxInt-1)/3/(k+1) {
+		return 0, fmt.Errorf("%w: %d is too large", ErrInvalidK, k)
+	}
+	return maxKringSize(k), nil
+}
+
+// MaxGridRingSize returns the number of cells exactly k grid steps from an
+// origin, 6 * k or 1 for k = 0, which is exact unless the ring runs into a
+// pentagon.
+//
+// Return the size, or ErrInvalidK if k is negative or so large that the size
+// overflows an int.
+func MaxGridRingSize(k int) (int, error) {
+	if k < 0 {
+		return 0, fmt.Errorf("%w: %d is negative", ErrInvalidK, k)
+	}
+	if k > math.MaxInt/6 {
+		return 0, fmt.Errorf("%w: %d is too large", ErrInvalidK, k)
+	}
+	return max(6*k, 1), nil
+}
+
+// Ratio of the largest distance from a cell center to one of its vertices to
+// the average edge length of its resolution, measured over all cells at
+// resolutions 0 to 4 and stable beyond.
+const MAX_CIRCUMRADIUS_RATIO = 1.27
+
+// RadiusToK finds the smallest k for which the grid disk of radius k around
+// any cell at res is guaranteed to cover the circle of radiusM meters around
+// any point of that cell.
+//
+// The bound assumes the worst case throughout: the point lies on a vertex of
+// the largest possible cell, so the circle reaches up to the radius plus a
+// circumradius away from the cell center, and neighboring cell centers are
+// no further apart than their closest spacing at res, which is found between
+// a pentagon and its neighbors. On a hexagonal grid with center spacing s,
+// the disk of radius k covers everything within (k + 1/3) * s * sqrt(3)/2 of
+// its center. Away from pentagons the result therefore overestimates the k
+// needed by about a quarter.
+//
+// Return k, ErrInvalidResolution if res is out of range, ErrInvalidDistance
+// if radiusM is negative or not finite, or ErrInvalidK if the resulting k is
+// too large for a grid disk.
+func RadiusToK(radiusM float64, res int) (int, error) {
+	if res < 0 || res > MAX_H3_RES {
+		return 0, fmt.Errorf("%w: %d", ErrInvalidResolution, res)
+	}
+	if radiusM < 0 || !isFinite(radiusM) {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidDistance, radiusM)
+	}
+
+	reachRads := radiusM/(EARTH_RADIUS_KM*1000) +
+		MAX_CIRCUMRADIUS_RATIO*EdgeLengthKm(res)/EARTH_RADIUS_KM
+	steps := math.Ceil(reachRads/(M_SQRT3_2*minNeighborSpacingRads[res]) - 1.0/3)
+	if steps > math.MaxInt32 {
+		return 0, fmt.Errorf("%w: radius %v m needs more than %d", ErrInvalidK, radiusM, math.MaxInt32)
+	}
+
+	k := int(steps)
+	if _, err := MaxGridDiskSize(k); err != nil {
+		return 0, err
+	}
+	return k, nil
+}
+
+// minNeighborSpacingRads is the smallest great circle distance in radians
+// between the centers of neighboring cells at each resolution, which lies
+// between a pentagon and its neighbors.
+var minNeighborSpacingRads = [MAX_H3_RES + 1]float64{
+	0.28749431167088468, 0.10025541450083755, 0.035374254963852943,
+	0.01355127147973058, 0.0049410356899449266, 0.0019203904752949058,
+	0.00070359146174849062, 0.00027402595527014674, 0.00010046679605638332,
+	3.9140128120169701e-05, 1.4351455365628753e-05, 5.5913155166010589e-06,
+	2.050188643319801e-06, 7.987566786885938e-07, 2.9288369890252904e-07,
+	1.1410804205525053e-07,
+}
+
+// GridDisk produces the cells within k grid steps of the origin cell,
+// including the origin itself, in no particular order.
+//
+// The result is sized exactly: the holes the k-ring traversal leaves when
+// pentagonal distortion forces the slow traversal are removed, so every
+// element is a cell.
+//
+// Return the cells, ErrInvalidCell if origin is not a valid cell, or
+// ErrInvalidK if k is out of range.
+func GridDisk(origin H3Index, k int) ([]H3Index, error) {
+	if err := validateGridOrigin(origin, k); err != nil {
+		return nil, err
+	}
+
+	return compactNulls(kRing(origin, k)), nil
+}
+
+// AppendGridDisk appends the cells within k grid steps of the origin cell to
+// dst, as GridDisk does, growing dst at most once. Reusing dst across calls
+// avoids allocating a disk for every origin in hot loops; only the slow
+// traversal near pentagons still allocates scratch space.
+//
+// Return the extended slice, ErrInvalidCell if origin is not a valid cell, or
+// ErrInvalidK if k is out of range, in which case dst is left unchanged.
+func AppendGridDisk(dst []H3Index, origin H3Index, k int) ([]H3Index, error) {
+	if err := validateGridOrigin(origin, k); err != nil {
+		return dst, err
+	}
+
+	size := maxKringSize(k)
+	start := len(dst)
+	dst = slices.Grow(dst, size)
+	disk := dst[start : start+size]
+	if hexRange(origin, k, disk) != HEX_RANGE_SUCCESS {
+		recordEvent(EventPentagonFallback, 1)
+		clear(disk)
+		_kRingInternal(origin, k, disk, make([]int, size), size, 0)
+	}
+	return dst[:start+len(compactNulls(disk))], nil
+}
+
+// GridDiskDistances produces the cells within k grid steps of the origin
+// cell, as GridDisk does, along with the grid distance of each from the
+// origin. The two slices are parallel and sized exactly.
+//
+// Return the cells and their distances, ErrInvalidCell if origin is not a
+// valid cell, or ErrInvalidK if k is out of range.
+func GridDiskDistances(origin H3Index, k int) ([]H3Index, []int, error) {
+	if err := validateGridOrigin(origin, k); err != nil {
+		return nil, nil, err
+	}
+
+	cells, distances := kRingDistances(origin, k)
+	n := 0
+	for i, cell := range cells {
+		if cell != H3_NULL {
+			cells[n] = cell
+			distances[n] = distances[i]
+			n++
+		}
+	}
+	return cells[:n], distances[:n], nil
+}
+
+// GridDiskUnsafe produces the cells within k grid steps of the origin cell
+// using the fast hex range traversal, which cannot cross pentagons. It
+// suits workloads where the origins are known to be far from pentagons.
+//
+// Return the cells in order of increasing distance from the origin,
+// ErrInvalidCell if origin is not a valid cell, ErrInvalidK if k is out of
+// range, or ErrPentagonDistortion if the traversal encountered a pentagon.
+func GridDiskUnsafe(origin H3Index, k int) ([]H3Index, error) {
+	cells, _, err := gridDiskUnsafe(origin, k, false)
+	return cells, err
+}
+
+// GridDiskDistancesUnsafe produces the cells within k grid steps of the
+// origin cell and their grid distances from it, as GridDiskUnsafe does.
+//
+// Return the cells in order of increasing distance from the origin and the
+// parallel distances, ErrInvalidCell if origin is not a valid cell,
+// ErrInvalidK if k is out of range, or ErrPentagonDistortion if the
+// traversal encountered a pentagon.
+func GridDiskDistancesUnsafe(origin H3Index, k int) ([]H3Index, []int, error) {
+	return gridDiskUnsafe(origin, k, true)
+}
+
+// gridDiskUnsafe runs hexRangeDistances, computing the distances only if
+// withDistances is set.
+func gridDiskUnsafe(origin H3Index, k int, withDistances bool) ([]H3Index, []int, error) {
+	if err := validateGridOrigin(origin, k); err != nil {
+		return nil, nil, err
+	}
+
+	size := maxKringSize(k)
+	out := make([]H3Index, size)
+	var distances []int
+	if withDistances {
+		distances = make([]int, size)
+	}
+	if hexRangeDistances(origin, k, out, distances) != HEX_RANGE_SUCCESS {
+		return nil, nil, fmt.Errorf("%w: disk %d around %s", ErrPentagonDistortion, k, origin)
+	}
+	return out, distances, nil
+}
+
+// GridDisksUnsafe produces the disks of radius k around every origin with
+// the fast traversal, as GridDiskUnsafe does, in a single allocation.
+//
+// Return the disks one after another, each MaxGridDiskSize(k) cells long and
+// in the order of origins, ErrInvalidCell if an origin is not a valid cell,
+// ErrInvalidK if k is out of range, or ErrPentagonDistortion if any
+// traversal encountered a pentagon.
+func GridDisksUnsafe(origins []H3Index, k int) ([]H3Index, error) {
+	for _, origin := range origins {
+		if err := validateGridOrigin(origin, k); err != nil {
+			return nil, err
+		}
+	}
+
+	size := maxKringSize(k)
+	out := make([]H3Index, len(origins)*size)
+	for i, origin := range origins {
+		if hexRange(origin, k, out[i*size:(i+1)*size]) != HEX_RANGE_SUCCESS {
+			return nil, fmt.Errorf("%w: disk %d around %s", ErrPentagonDistortion, k, origin)
+		}
+	}
+	return out, nil
+}
+
+// GridRingUnsafe produces the hollow ring of cells exactly k grid steps from
+// the origin cell, in order around the ring, using the fast traversal which
+// cannot cross pentagons.
+//
+// Return the 6 * k cells of the ring (the origin alone if k is 0),
+// ErrInvalidCell if origin is not a valid cell, ErrInvalidK if k is out of
+// range, or ErrPentagonDistortion if the traversal encountered a pentagon.
+func GridRingUnsafe(origin H3Index, k int) ([]H3Index, error) {
+	if err := validateGridOrigin(origin, k); err != nil {
+		return nil, err
+	}
+
+	out := make([]H3Index, max(6*k, 1))
+	if hexRing(origin, k, out) != HEX_RANGE_SUCCESS {
+		return nil, fmt.Errorf("%w: ring %d around %s", ErrPentagonDistortion, k, origin)
+	}
+	return out, nil
+}
+
+// GridRing produces the hollow ring of cells exactly k grid steps from the
+// origin cell. It tries the fast traversal of GridRingUnsafe first, and when
+// a pentagon gets in the way falls back to filtering the disk of GridDisk by
+// distance, in which case the cells are in no particular order and there may
+// be fewer than 6 * k of them.
+//
+// Return the cells of the ring, ErrInvalidCell if origin is not a valid cell,
+// or ErrInvalidK if k is out of range.
+func GridRing(origin H3Index, k int) ([]H3Index, error) {
+	ring, err := GridRingUnsafe(origin, k)
+	if !errors.Is(err, ErrPentagonDistortion) {
+		return ring, err
+	}
+
+	recordEvent(EventPentagonFallback, 1)
+	cells, distances := kRingDistances(origin, k)
+	ring = make([]H3Index, 0, 6*k)
+	for i, cell := range cells {
+		if cell != H3_NULL && distances[i] == k {
+			ring = append(ring, cell)
+		}
+	}
+	return ring, nil
+}
+
+// GridDisks produces the disks of radius k around every origin in one call,
+// reusing the same scratch buffers for each traversal. The disks are
+// appended one after another in the order of origins, without the holes
+// the k-ring traversal leaves near pentagons. If dedupe is set, cells
+// already produced for an earlier origin are left out, which turns the
+// result into the expansion of the whole origin set by k.
+//
+// Return the cells, ErrInvalidCell if an origin is not a valid cell, or
+// ErrInvalidK if k is out of range.
+func GridDisks(origins []H3Index, k int, dedupe bool) ([]H3Index, error) {
+	for _, origin := range origins {
+		if err := validateGridOrigin(origin, k); err != nil {
+			return nil, err
+		}
+	}
+
+	size := maxKringSize(k)
+	scratch := make([]H3Index, size)
+	distances := make([]int, size)
+
+	var out []H3Index
+	var seen CellSet
+	if dedupe {
+		seen = NewCellSet()
+	} else {
+		out = make([]H3Index, 0, len(origins)*size)
+	}
+
+	for _, origin := range origins {
+		if hexRangeDistances(origin, k, scratch, distances) != HEX_RANGE_SUCCESS {
+			recordEvent(EventPentagonFallback, 1)
+			clear(scratch)
+			clear(distances)
+			_kRingInternal(origin, k, scratch, distances, size, 0)
+		}
+
+		for _, cell := range scratch {
+			if cell == H3_NULL {
+				continue
+			}
+			if dedupe {
+				if seen.Has(cell) {
+					continue
+				}
+				seen.Add(cell)
+			}
+			out = append(out, cell)
+		}
+	}
+	return out, nil
+}
+
+// GridDiskIter returns an iterator over the cells within k grid steps of the
+// origin cell, in order of increasing distance. Away from pentagons only one
+// ring is held in memory at a time, so very large disks can be streamed
+// without allocating them. Once a ring runs into a pentagon, the remaining
+// rings are found with a breadth-first search, which does have to remember
+// the cells it has seen.
+//
+// The iterator is empty if origin is not a valid cell or k is out of range.
+func GridDiskIter(origin H3Index, k int) iter.Seq[H3Index] {
+	return func(yield func(H3Index) bool) {
+		if validateGridOrigin(origin, k) != nil {
+			return
+		}
+		yieldRings(origin, 0, k, yield)
+	}
+}
+
+// GridDonut produces the cells whose grid distance from the origin cell is
+// between k1 and k2 inclusive, in order of increasing distance. Only the
+// rings in that range are materialized, so a thin band far from the origin
+// costs memory in proportion to the band rather than to the disk of radius
+// k2. The rings are traversed as GridDiskIter does, falling back to a
+// breadth-first search, which does remember every cell up to k2, once a
+// pentagon gets in the way.
+//
+// Return the cells, ErrInvalidCell if origin is not a valid cell, or
+// ErrInvalidK if k1 or k2 is out of range or k1 is greater than k2.
+func GridDonut(origin H3Index, k1, k2 int) ([]H3Index, error) {
+	if err := validateGridOrigin(origin, k2); err != nil {
+		return nil, err
+	}
+	if k1 < 0 || k1 > k2 {
+		return nil, fmt.Errorf("%w: %d..%d", ErrInvalidK, k1, k2)
+	}
+
+	size, _ := MaxGridDiskSize(k2)
+	if k1 > 0 {
+		inner, _ := MaxGridDiskSize(k1 - 1)
+		size -= inner
+	}
+
+	out := make([]H3Index, 0, size)
+	yieldRings(origin, k1, k2, func(cell H3Index) bool {
+		out = append(out, cell)
+		return true
+	})
+	return out, nil
+}
+
+// yieldRings yields the cells whose grid distance from origin is between
+// kMin and kMax inclusive, ring by ring with the fast traversal, and with a
+// breadth-first search from the first ring the fast traversal fails on.
+//
+// Return false if yield asked to stop.
+func yieldRings(origin H3Index, kMin, kMax int, yield func(H3Index) bool) bool {
+	if kMin == 0 {
+		if !yield(origin) {
+			return false
+		}
+		kMin = 1
+	}
+
+	var ring []H3Index
+	for r := kMin; r <= kMax; r++ {
+		ring = slices.Grow(ring[:0], 6*r)[:6*r]
+		if hexRing(origin, r, ring) != HEX_RANGE_SUCCESS {
+			recordEvent(EventPentagonFallback, 1)
+			return yieldRingsSearch(origin, r, kMax, yield)
+		}
+		for _, cell := range ring {
+			if !yield(cell) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// yieldRingsSearch yields the cells whose grid distance from origin is
+// between kMin and kMax inclusive, found with a breadth-first search.
+//
+// Return false if yield asked to stop.
+func yieldRingsSearch(origin H3Index, kMin, kMax int, yield func(H3Index) bool) bool {
+	if kMin == 0 && !yield(origin) {
+		return false
+	}
+
+	seen := NewCellSet(origin)
+	frontier := []H3Index{origin}
+	var next, neighbors []H3Index
+	for d := 1; d <= kMax && len(frontier) > 0; d++ {
+		next = next[:0]
+		for _, cell := range frontier {
+			neighbors = appendNeighbors(neighbors[:0], cell)
+			for _, neighbor := range neighbors {
+				if seen.Has(neighbor) {
+					continue
+				}
+				seen.Add(neighbor)
+				next = append(next, neighbor)
+				if d >= kMin && !yield(neighbor) {
+					return false
+				}
+			}
+		}
+		frontier, next = next, frontier
+	}
+	return true
+}
+
+// validateGridOrigin checks the arguments shared by the grid traversal
+// functions.
+func validateGridOrigin(origin H3Index, k int) error {
+	if !H3IsValid(origin) {
+		return fmt.Errorf("%w: %s", ErrInvalidCell, origin)
+	}
+	if _, err := MaxGridDiskSize(k); err != nil {
+		return err
+	}
+	return nil
+}
+
+// compactNulls removes the H3_NULL entries of cells in place, preserving the
+// order of the rest.
+func compactNulls(cells []H3Index) []H3Index {
+	n := 0
+	for _, cell := range cells {
+		if cell != H3_NULL {
+			cells[n] = cell
+			n++
+		}
+	}
+	return cells[:n]
+}
@@ -0,0 +1,248 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// gridTestOrigins returns origins for the grid disk tests: the pentagons at
+// resolution 2, a neighbor of each, and cells at random points.
+func gridTestOrigins() []H3Index {
+	origins := make([]H3Index, PentagonIndexCount())
+	GetPentagonIndexes(2, &origins)
+	for _, pentagon := range origins[:PentagonIndexCount()] {
+		origins = append(origins, appendNeighbors(nil, pentagon)[0])
+	}
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < 12; i++ {
+		g := NewGeoCoordDegs(RadsToDegs(math.Asin(2*rng.Float64()-1)), 360*rng.Float64()-180)
+		origins = append(origins, GeoToH3(&g, 2+rng.IntN(6)))
+	}
+	return origins
+}
+
+// diskDistances returns the grid distance from origin of every cell within
+// k steps, found with the k-ring traversal.
+func diskDistances(origin H3Index, k int) map[H3Index]int {
+	cells, distances := kRingDistances(origin, k)
+	want := make(map[H3Index]int, len(cells))
+	for i, cell := range cells {
+		if cell != H3_NULL {
+			want[cell] = distances[i]
+		}
+	}
+	return want
+}
+
+func TestGridDiskIter(t *testing.T) {
+	for _, origin := range gridTestOrigins() {
+		for _, k := range []int{0, 1, 2, 5} {
+			want := diskDistances(origin, k)
+			seen := make(map[H3Index]bool)
+			last := 0
+			for cell := range GridDiskIter(origin, k) {
+				d, ok := want[cell]
+				switch {
+				case !ok:
+					t.Fatalf("GridDiskIter(%s, %d) yielded %s, which is not in the disk", origin, k, cell)
+				case seen[cell]:
+					t.Fatalf("GridDiskIter(%s, %d) yielded %s twice", origin, k, cell)
+				case d < last:
+					t.Fatalf("GridDiskIter(%s, %d) yielded %s at distance %d after distance %d", origin, k, cell, d, last)
+				}
+				seen[cell] = true
+				last = d
+			}
+			if len(seen) != len(want) {
+				t.Fatalf("GridDiskIter(%s, %d) yielded %d cells, want %d", origin, k, len(seen), len(want))
+			}
+		}
+	}
+
+	// Breaking out of the loop stops the traversal, also past a pentagon.
+	pentagons := make([]H3Index, PentagonIndexCount())
+	GetPentagonIndexes(2, &pentagons)
+	n := 0
+	for range GridDiskIter(pentagons[0], 3) {
+		n++
+		if n == 4 {
+			break
+		}
+	}
+	if n != 4 {
+		t.Errorf("GridDiskIter stopped after %d cells, want 4", n)
+	}
+
+	for range GridDiskIter(H3Index(0x7fffffffffffffff), 1) {
+		t.Fatal("GridDiskIter of an invalid origin yielded a cell")
+	}
+	for range GridDiskIter(pentagons[0], -1) {
+		t.Fatal("GridDiskIter with a negative k yielded a cell")
+	}
+}
+
+func TestGridDonut(t *testing.T) {
+	for _, origin := range gridTestOrigins() {
+		for _, band := range [][2]int{{0, 0}, {0, 3}, {1, 1}, {2, 4}, {4, 4}} {
+			k1, k2 := band[0], band[1]
+			got, err := GridDonut(origin, k1, k2)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			distances := diskDistances(origin, k2)
+			want := 0
+			for _, d := range distances {
+				if d >= k1 {
+					want++
+				}
+			}
+			seen := make(map[H3Index]bool)
+			last := k1
+			for _, cell := range got {
+				d, ok := distances[cell]
+				switch {
+				case !ok || d < k1:
+					t.Fatalf("GridDonut(%s, %d, %d) produced %s, which is not in the band", origin, k1, k2, cell)
+				case seen[cell]:
+					t.Fatalf("GridDonut(%s, %d, %d) produced %s twice", origin, k1, k2, cell)
+				case d < last:
+					t.Fatalf("GridDonut(%s, %d, %d) produced %s out of order", origin, k1, k2, cell)
+				}
+				seen[cell] = true
+				last = d
+			}
+			if len(got) != want {
+				t.Fatalf("GridDonut(%s, %d, %d) produced %d cells, want %d", origin, k1, k2, len(got), want)
+			}
+		}
+	}
+
+	origin := gridTestOrigins()[0]
+	for _, band := range [][2]int{{-1, 2}, {3, 2}, {0, -1}} {
+		if _, err := GridDonut(origin, band[0], band[1]); !errors.Is(err, ErrInvalidK) {
+			t.Errorf("GridDonut(%s, %d, %d) error = %v, want ErrInvalidK", origin, band[0], band[1], err)
+		}
+	}
+	if _, err := GridDonut(H3Index(0x7fffffffffffffff), 0, 1); !errors.Is(err, ErrInvalidCell) {
+		t.Errorf("GridDonut of an invalid origin error = %v, want ErrInvalidCell", err)
+	}
+}
+
+func TestRadiusToKBounds(t *testing.T) {
+	// The circumradius bound holds for every cell at the resolutions it was
+	// measured at.
+	for res := 0; res <= 4; res++ {
+		worst := 0.0
+		for cell := range AllCellsAtRes(res) {
+			var center GeoCoord
+			H3ToGeo(cell, &center)
+			var gb GeoBoundary
+			H3ToGeoBoundary(cell, &gb)
+			for i := 0; i < gb.numVerts; i++ {
+				worst = math.Max(worst, arcLengthRads(&center, &gb.verts[i]))
+			}
+		}
+		if ratio := worst * EARTH_RADIUS_KM / EdgeLengthKm(res); ratio > MAX_CIRCUMRADIUS_RATIO {
+			t.Errorf("circumradius ratio at res %d = %v, above MAX_CIRCUMRADIUS_RATIO", res, ratio)
+		}
+	}
+
+	// The spacing table holds the spacing between pentagons and their
+	// neighbors, and no other neighbors are closer.
+	for res := 0; res <= MAX_H3_RES; res++ {
+		pentagons := make([]H3Index, PentagonIndexCount())
+		GetPentagonIndexes(res, &pentagons)
+		spacing := math.Inf(1)
+		for _, pentagon := range pentagons {
+			spacing = math.Min(spacing, minSpacingAround(pentagon))
+		}
+		if math.Abs(spacing-minNeighborSpacingRads[res]) > 1e-12*spacing {
+			t.Errorf("pentagon neighbor spacing at res %d = %v, want %v", res, spacing, minNeighborSpacingRads[res])
+		}
+	}
+	for res := 0; res <= 3; res++ {
+		for cell := range AllCellsAtRes(res) {
+			if spacing := minSpacingAround(cell); spacing < minNeighborSpacingRads[res] {
+				t.Fatalf("neighbors of %s are %v apart, closer than at the pentagons", cell, spacing)
+			}
+		}
+	}
+}
+
+// minSpacingAround returns the smallest distance in radians between the
+// center of cell and the centers of its neighbors.
+func minSpacingAround(cell H3Index) float64 {
+	var center, neighborCenter GeoCoord
+	H3ToGeo(cell, &center)
+	spacing := math.Inf(1)
+	for _, neighbor := range appendNeighbors(nil, cell) {
+		H3ToGeo(neighbor, &neighborCenter)
+		spacing = math.Min(spacing, arcLengthRads(&center, &neighborCenter))
+	}
+	return spacing
+}
+
+func TestRadiusToK(t *testing.T) {
+	// Every point of the circle around a point of the origin cell falls in
+	// the disk of radius k.
+	rng := rand.New(rand.NewPCG(3, 4))
+	for _, origin := range gridTestOrigins() {
+		res := H3_GET_RESOLUTION(origin)
+		var gb GeoBoundary
+		H3ToGeoBoundary(origin, &gb)
+		vertex := gb.verts[rng.IntN(gb.numVerts)]
+
+		for _, radiusM := range []float64{0, 0.5 * EdgeLengthKm(res) * 1000, 3 * EdgeLengthKm(res) * 1000} {
+			k, err := RadiusToK(radiusM, res)
+			if err != nil {
+				t.Fatal(err)
+			}
+			disk, err := GridDisk(origin, k)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 36; i++ {
+				var p GeoCoord
+				_geoAzDistanceRads(&vertex, float64(i)*10*M_PI_180, radiusM/(EARTH_RADIUS_KM*1000), &p)
+				if cell := GeoToH3(&p, res); !slices.Contains(disk, cell) {
+					t.Fatalf("RadiusToK(%v, %d) = %d, but %s around %s is further", radiusM, res, k, cell, origin)
+				}
+			}
+		}
+	}
+
+	for _, tt := range []struct {
+		radiusM float64
+		res     int
+		want    error
+	}{
+		{1, -1, ErrInvalidResolution},
+		{1, MAX_H3_RES + 1, ErrInvalidResolution},
+		{-1, 5, ErrInvalidDistance},
+		{math.NaN(), 5, ErrInvalidDistance},
+		{math.Inf(1), 5, ErrInvalidDistance},
+		{1e15, 15, ErrInvalidK},
+	} {
+		if _, err := RadiusToK(tt.radiusM, tt.res); !errors.Is(err, tt.want) {
+			t.Errorf("RadiusToK(%v, %d) error = %v, want %v", tt.radiusM, tt.res, err, tt.want)
+		}
+	}
+}
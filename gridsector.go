@@ -0,0 +1,115 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"fmt"
+	"math"
+)
+
+// GridDiskSector produces the cells within k grid steps of the origin cell
+// whose centers lie inside the angular sector of width widthDeg centered on
+// the bearing headingDeg, both in degrees with bearings measured clockwise
+// from north at the origin center. The origin itself is always included. A
+// width of 360 or more selects the whole disk.
+//
+// The sector is applied while the disk is expanded, so the traversal only
+// visits cells that overlap the sector, plus their immediate neighbors,
+// rather than the whole disk. Cells overlapping the sector without having
+// their center inside it are walked through but not returned, which keeps
+// narrow sectors connected. The cells come out in order of increasing
+// distance.
+//
+// Return the cells, ErrInvalidCell if origin is not a valid cell, ErrInvalidK
+// if k is out of range, or ErrInvalidSector if the heading is not finite or
+// the width is negative or NaN.
+func GridDiskSector(origin H3Index, k int, headingDeg, widthDeg float64) ([]H3Index, error) {
+	if err := validateGridOrigin(origin, k); err != nil {
+		return nil, err
+	}
+	if !isFinite(headingDeg) || math.IsNaN(widthDeg) || widthDeg < 0 {
+		return nil, fmt.Errorf("%w: heading %v, width %v", ErrInvalidSector, headingDeg, widthDeg)
+	}
+
+	s := sector{
+		heading:   headingDeg * M_PI_180,
+		halfWidth: math.Min(widthDeg*M_PI_180/2, M_PI),
+	}
+	H3ToGeo(origin, &s.apex)
+
+	out := []H3Index{origin}
+	seen := NewCellSet(origin)
+	frontier := []H3Index{origin}
+	var next, neighbors []H3Index
+	for d := 1; d <= k && len(frontier) > 0; d++ {
+		next = next[:0]
+		for _, cell := range frontier {
+			neighbors = appendNeighbors(neighbors[:0], cell)
+			for _, neighbor := range neighbors {
+				if seen.Has(neighbor) {
+					continue
+				}
+				seen.Add(neighbor)
+
+				inside, overlaps := s.classify(neighbor)
+				if inside {
+					out = append(out, neighbor)
+				}
+				if overlaps {
+					next = append(next, neighbor)
+				}
+			}
+		}
+		frontier, next = next, frontier
+	}
+	return out, nil
+}
+
+// sector is an angular sector on the sphere with its apex at a point.
+type sector struct {
+	apex      GeoCoord // apex of the sector
+	heading   float64  // bearing of the sector's center line, in radians
+	halfWidth float64  // half of the sector's opening angle, in radians
+}
+
+// classify determines how a cell relates to the sector.
+//
+// Return whether the center of the cell lies inside the sector, and whether
+// any part of the cell may overlap it. The latter is conservative: the cell
+// is treated as the smallest cap around its center containing its boundary.
+func (s *sector) classify(cell H3Index) (inside, overlaps bool) {
+	var center GeoCoord
+	H3ToGeo(cell, &center)
+
+	diff := math.Abs(math.Remainder(_geoAzimuthRads(&s.apex, &center)-s.heading, 2*M_PI))
+	if diff <= s.halfWidth {
+		return true, true
+	}
+
+	var gb GeoBoundary
+	H3ToGeoBoundary(cell, &gb)
+	radius := 0.0
+	for i := 0; i < gb.numVerts; i++ {
+		radius = math.Max(radius, arcLengthRads(&center, &gb.verts[i]))
+	}
+
+	// angle under which the cap around the cell is seen from the apex
+	dist := arcLengthRads(&s.apex, &center)
+	sinRatio := math.Sin(radius) / math.Sin(dist)
+	if radius >= dist || sinRatio >= 1 {
+		return false, true
+	}
+	return false, diff <= s.halfWidth+math.Asin(sinRatio)
+}
@@ -0,0 +1,72 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestGridDiskSector(t *testing.T) {
+	sectors := [][2]float64{{0, 0}, {0, 10}, {45, 60}, {200, 90}, {-30, 180}, {90, 359}, {0, 360}}
+	for _, origin := range gridTestOrigins() {
+		var apex GeoCoord
+		H3ToGeo(origin, &apex)
+		for _, k := range []int{1, 4} {
+			distances := diskDistances(origin, k)
+			for _, sector := range sectors {
+				heading, width := sector[0], sector[1]
+				got, err := GridDiskSector(origin, k, heading, width)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// The disk, filtered by the bearing of the cell centers.
+				want := []H3Index{origin}
+				for cell := range distances {
+					var center GeoCoord
+					H3ToGeo(cell, &center)
+					diff := math.Abs(math.Remainder(_geoAzimuthRads(&apex, &center)-heading*M_PI_180, 2*M_PI))
+					if cell != origin && diff <= math.Min(width*M_PI_180/2, M_PI) {
+						want = append(want, cell)
+					}
+				}
+
+				for i := 1; i < len(got); i++ {
+					if distances[got[i]] < distances[got[i-1]] {
+						t.Fatalf("GridDiskSector(%s, %d, %v, %v) is not ordered by distance", origin, k, heading, width)
+					}
+				}
+				slices.Sort(got)
+				slices.Sort(want)
+				if !slices.Equal(got, want) {
+					t.Fatalf("GridDiskSector(%s, %d, %v, %v) = %v, want %v", origin, k, heading, width, got, want)
+				}
+			}
+		}
+	}
+
+	origin := gridTestOrigins()[0]
+	for _, sector := range [][2]float64{{math.NaN(), 10}, {math.Inf(1), 10}, {0, -1}, {0, math.NaN()}} {
+		if _, err := GridDiskSector(origin, 2, sector[0], sector[1]); !errors.Is(err, ErrInvalidSector) {
+			t.Errorf("GridDiskSector with heading %v and width %v error = %v, want ErrInvalidSector", sector[0], sector[1], err)
+		}
+	}
+	if _, err := GridDiskSector(origin, -1, 0, 10); !errors.Is(err, ErrInvalidK) {
+		t.Errorf("GridDiskSector with k -1 error = %v, want ErrInvalidK", err)
+	}
+}
@@ -15,7 +15,13 @@
 package h3go
 
 import (
+	"cmp"
+	"context"
+	"fmt"
+	"iter"
 	"math"
+	"math/bits"
+	"slices"
 	"strconv"
 )
 
@@ -472,6 +478,51 @@ func (h3 H3Index) ToParent(parentRes int) H3Index {
 	return parentH
 }
 
+// Ancestors returns an iterator over the parents of the cell at every
+// coarser resolution, from the direct parent down to resolution 0. It yields
+// nothing for a cell at resolution 0.
+func (h3 H3Index) Ancestors() iter.Seq[H3Index] {
+	return func(yield func(H3Index) bool) {
+		for res := H3_GET_RESOLUTION(h3) - 1; res >= 0; res-- {
+			if !yield(H3ToParent(h3, res)) {
+				return
+			}
+		}
+	}
+}
+
+// Contains reports whether other is h3 itself or one of its descendants. The
+// base cells and digits of both are compared with bit masks, without
+// computing any parents. Only cells take part: directed edges and other
+// modes neither contain nor are contained.
+func (h3 H3Index) Contains(other H3Index) bool {
+	if H3_GET_MODE(h3) != H3_HEXAGON_MODE || H3_GET_MODE(other) != H3_HEXAGON_MODE {
+		return false
+	}
+	return cellContains(h3, other)
+}
+
+// CommonPrefixLength returns the number of leading indexing digits cells a
+// and b share after their base cell, which is the resolution of their finest
+// common ancestor. Cells sorted by index are grouped by their prefixes, which
+// makes the length useful to shard by locality or to compress a sorted run of
+// cells.
+//
+// Return -1 if a and b have different base cells, or if either is not in
+// cell mode.
+func CommonPrefixLength(a, b H3Index) int {
+	if H3_GET_MODE(a) != H3_HEXAGON_MODE || H3_GET_MODE(b) != H3_HEXAGON_MODE ||
+		H3_GET_BASE_CELL(a) != H3_GET_BASE_CELL(b) {
+		return -1
+	}
+
+	// The digits sit below the base cell from resolution 1 down, so the
+	// leading zeros of their difference count the shared digits.
+	diff := (uint64(a) ^ uint64(b)) & (uint64(1)<<H3_BC_OFFSET - 1)
+	shared := (bits.LeadingZeros64(diff) - (H3_NUM_BITS - H3_BC_OFFSET)) / H3_PER_DIGIT_OFFSET
+	return min(shared, H3_GET_RESOLUTION(a), H3_GET_RESOLUTION(b))
+}
+
 // _isValidChildRes determines whether one resolution is a valid child
 // resolution of another. Each resolution is considered a valid child resolution
 // of itself.
@@ -497,6 +548,34 @@ func MaxH3ToChildrenSize(h H3Index, childRes int) int {
 	return _ipow(7, childRes-parentRes)
 }
 
+// CellToChildrenSize returns the exact number of children of h at childRes.
+// A pentagon has only 6 children, of which one is a pentagon again, so under
+// a pentagon n resolutions up there are 1 + 5 * (7^n - 1) / 6 children
+// instead of the 7^n that MaxH3ToChildrenSize allows for.
+//
+// Return the count, ErrInvalidCell if h is not a valid cell, or
+// ErrInvalidResolution if childRes is coarser than h or out of range.
+func CellToChildrenSize(h H3Index, childRes int) (int, error) {
+	if !H3IsValid(h) {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidCell, h)
+	}
+	if !_isValidChildRes(H3_GET_RESOLUTION(h), childRes) {
+		return 0, fmt.Errorf("%w: %d", ErrInvalidResolution, childRes)
+	}
+	return childrenSize(h, childRes), nil
+}
+
+// childrenSize returns the number of children of the valid cell h at the
+// valid child resolution childRes.
+func childrenSize(h H3Index, childRes int) int {
+	n := _ipow(7, childRes-H3_GET_RESOLUTION(h))
+	if H3IsPentagon(h) {
+		// the center child and 5 * (1 + 7 + ... + 7^(n-1)) hexagons
+		return 1 + 5*(n-1)/6
+	}
+	return n
+}
+
 // makeDirectChild takes an index and immediately returns the immediate child
 // index based on the specified cell number. Bit operations only, could generate
 // invalid indexes if not careful (deleted cell under a pentagon).
@@ -540,9 +619,35 @@ func H3ToChildren(h H3Index, childRes int, children *[]H3Index) {
 //
 // TODO: enhance algorithm
 func (h3 H3Index) ToChildren(childRes int) []H3Index {
-	buffer := make([]H3Index, 0, MaxH3ToChildrenSize(h3, childRes))
-	H3ToChildren(h3, childRes, &buffer)
-	return buffer
+	return AppendChildren(nil, h3, childRes)
+}
+
+// AppendChildren appends the children of h at childRes to dst, as
+// ToChildren does, growing dst at most once. Reusing dst across calls avoids
+// allocating a slice for every cell in hot loops.
+//
+// Return the extended slice, or dst unchanged if childRes is coarser than h
+// or out of range.
+func AppendChildren(dst []H3Index, h H3Index, childRes int) []H3Index {
+	if size, err := CellToChildrenSize(h, childRes); err == nil {
+		dst = slices.Grow(dst, size)
+	}
+	H3ToChildren(h, childRes, &dst)
+	return dst
+}
+
+// Siblings returns the children of the parent of the cell, the cell itself
+// included, in ascending order. There are 7 of them, or 6 when the parent is
+// a pentagon, whose deleted k-axes child is skipped.
+//
+// Return nil if the cell is invalid or at resolution 0, where it has no
+// parent.
+func (h3 H3Index) Siblings() []H3Index {
+	res := H3_GET_RESOLUTION(h3)
+	if !H3IsValid(h3) || res == 0 {
+		return nil
+	}
+	return H3ToParent(h3, res-1).ToChildren(res)
 }
 
 // H3ToCenterChild produces the center child index for a given H3 index at
@@ -589,118 +694,290 @@ func (h3 H3Index) ToCenterChild(childRes int) H3Index {
 	return child
 }
 
+// CellRange returns the smallest and the largest descendant of h at res in
+// numeric order. Every descendant of h at res lies between the two and no
+// other cell at res does, so a store sorted by index can find all cells at
+// res within h, such as points indexed at resolution 15, with a single range
+// scan: WHERE cell BETWEEN first AND last.
+//
+// Return H3_NULL twice if h is not a valid cell or res is coarser than h or
+// out of range.
+func CellRange(h H3Index, res int) (first, last H3Index) {
+	if !H3IsValid(h) || !_isValidChildRes(H3_GET_RESOLUTION(h), res) {
+		return H3_NULL, H3_NULL
+	}
+
+	first, last = h, h
+	H3_SET_RESOLUTION(&first, res)
+	H3_SET_RESOLUTION(&last, res)
+	for r := H3_GET_RESOLUTION(h) + 1; r <= res; r++ {
+		H3_SET_INDEX_DIGIT(&first, r, CENTER_DIGIT)
+		H3_SET_INDEX_DIGIT(&last, r, IJ_AXES_DIGIT)
+	}
+	return first, last
+}
+
 // Compact takes a set of hexagons all at the same resolution and compresses
 // them by pruning full child branches to the parent level. This is also done
 // for all parents recursively to get the minimum number of hex addresses that
 // perfectly cover the defined space.
 //
-// Return an error code on bad input data.
-func Compact(h3Set []H3Index) ([]H3Index, error) {
-	if len(h3Set) == 0 {
-		return nil, nil
+// H3_NULL entries are skipped. Only cells can be compacted: directed edges
+// are rejected rather than compacted by their origin cells, since two edges
+// of the same cell would collide. To compact a set of edges, compact their
+// origin cells and keep the edges grouped by origin.
+//
+// The cells are sorted, and each pass scans the runs of siblings for
+// complete groups, so the result is the same for any order of the input and
+// comes out sorted in ascending order.
+//
+// The options WithMaxCells and WithContext bound the result and let the
+// work be cancelled between passes; other options are ignored.
+//
+// Return ErrInvalidCell if the set contains an index which is not a valid
+// cell, ErrResolutionMismatch if the cells are not all at the same
+// resolution, ErrCompactDuplicate on duplicated input, ErrBudgetExceeded if
+// there are more compacted cells than allowed, or ctx.Err() if the context
+// was done.
+func Compact(h3Set []H3Index, opts ...Option) ([]H3Index, error) {
+	o := newOptions(opts)
+	cells, err := compactContext(o.context(), h3Set)
+	if err != nil {
+		return nil, err
 	}
+	return o.checkMaxCells(cells)
+}
 
-	res := H3_GET_RESOLUTION(h3Set[0])
-	if res == 0 {
-		compacted := make([]H3Index, len(h3Set))
-		copy(compacted, h3Set)
-		return compacted, nil
+// compactContext is Compact, giving up between passes once ctx is done.
+//
+// Return ctx.Err() if ctx was done, or the errors of Compact.
+func compactContext(ctx context.Context, h3Set []H3Index) ([]H3Index, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	remaining, err := sortedCompactInput(h3Set)
+	if err != nil || len(remaining) == 0 {
+		return nil, err
 	}
 
-	result := make([]H3Index, 0, len(h3Set))
-	remaining := make([]H3Index, len(h3Set))
-	copy(remaining, h3Set)
-
-	for len(remaining) > 0 {
-		if len(remaining) < 6 {
-			// cannot compact more. append and break
-			result = append(result, remaining...)
-			break
+	result := make([]H3Index, 0, len(remaining))
+	for res := H3_GET_RESOLUTION(remaining[0]); res > 0 && len(remaining) >= 6; res-- {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
+		recordEvent(EventCompactPass, 1)
+
+		// Runs of complete siblings are replaced in place by their parent,
+		// which keeps remaining sorted for the next pass.
+		parents := remaining[:0]
+		for i := 0; i < len(remaining); {
+			parent := H3ToParent(remaining[i], res-1)
+			j := i + 1
+			for j < len(remaining) && H3ToParent(remaining[j], res-1) == parent {
+				j++
+			}
 
-		// map[cell]count
-		compactable := make(map[H3Index]int, len(remaining))
-
-		res := H3_GET_RESOLUTION(remaining[0])
-		parentRes := res - 1
-
-		// count parent cells
-		for _, cell := range remaining {
-			parent := H3ToParent(cell, parentRes)
-			isPentagon := H3IsPentagon(parent)
-			if _, ok := compactable[parent]; ok {
-				compactable[parent]++
-				if compactable[parent] > 7 {
-					return nil, ErrCompactDuplicate
-				}
-			} else if isPentagon {
-				// set 2 if cell is pentagon. it helps checking if dragonball is completed.
-				compactable[parent] = 2
+			complete := 7
+			if H3IsPentagon(parent) {
+				complete = 6
+			}
+			if j-i == complete {
+				parents = append(parents, parent)
 			} else {
-				compactable[parent] = 1
+				result = append(result, remaining[i:j]...)
 			}
+			i = j
 		}
+		remaining = parents
+	}
+	result = append(result, remaining...)
+
+	slices.Sort(result)
+	return result, nil
+}
 
-		// append uncompactable cells into result and cleanup remaining
-		for i, cell := range remaining {
-			parent := H3ToParent(cell, parentRes)
-			if compactable[parent] < 7 {
-				result = append(result, cell)
+// Coarsen compacts a set of hexagons all at the same resolution like
+// Compact, except that a group of siblings is replaced by its parent as soon
+// as at least threshold of the 7 siblings, or 6 under a pentagon, are
+// present. The parent then also covers the missing siblings, so the result
+// is an approximation which covers the whole set and more, and may be much
+// smaller. Replaced parents count as present siblings at the next coarser
+// resolution. A threshold of 1 gives the same result as Compact.
+//
+// Return ErrInvalidThreshold if threshold is not above 0 and at most 1, or
+// the errors of Compact.
+func Coarsen(h3Set []H3Index, threshold float64) ([]H3Index, error) {
+	if !(threshold > 0 && threshold <= 1) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidThreshold, threshold)
+	}
+	remaining, err := sortedCompactInput(h3Set)
+	if err != nil || len(remaining) == 0 {
+		return nil, err
+	}
+
+	// Siblings needed to replace a group of 7 or 6.
+	hexNeed := int(math.Ceil(threshold * 7))
+	pentNeed := int(math.Ceil(threshold * 6))
+
+	result := make([]H3Index, 0, len(remaining))
+	for res := H3_GET_RESOLUTION(remaining[0]); res > 0 && len(remaining) > 0; res-- {
+		parents := remaining[:0]
+		for i := 0; i < len(remaining); {
+			parent := H3ToParent(remaining[i], res-1)
+			j := i + 1
+			for j < len(remaining) && H3ToParent(remaining[j], res-1) == parent {
+				j++
 			}
-			remaining[i] = 0
-		}
-		remaining = remaining[:0]
 
-		// move compactable cells to remaining
-		for cell, count := range compactable {
-			if count == 7 {
-				remaining = append(remaining, cell)
+			need := hexNeed
+			if H3IsPentagon(parent) {
+				need = pentNeed
+			}
+			if j-i >= need {
+				parents = append(parents, parent)
+			} else {
+				result = append(result, remaining[i:j]...)
 			}
+			i = j
+		}
+		remaining = parents
+	}
+	result = append(result, remaining...)
+
+	// A parent replacing a group also covers what was kept of its missing
+	// siblings' incomplete groups, which are dropped.
+	covering := NewCellSet(result...)
+	out := result[:0]
+	for _, cell := range result {
+		if res := H3_GET_RESOLUTION(cell); res == 0 || !covering.Contains(H3ToParent(cell, res-1)) {
+			out = append(out, cell)
 		}
 	}
 
-	return result, nil
+	slices.Sort(out)
+	return out, nil
+}
+
+// sortedCompactInput returns the cells of h3Set without H3_NULL entries,
+// sorted so that the children of each parent form one run, in the same order
+// as their parents.
+//
+// Return ErrInvalidCell, ErrResolutionMismatch or ErrCompactDuplicate as
+// Compact does.
+func sortedCompactInput(h3Set []H3Index) ([]H3Index, error) {
+	cells := make([]H3Index, 0, len(h3Set))
+	for _, cell := range h3Set {
+		if cell == H3_NULL {
+			continue
+		}
+		if err := validateCompactCell(cell); err != nil {
+			return nil, err
+		}
+		if len(cells) > 0 &&
+			H3_GET_RESOLUTION(cell) != H3_GET_RESOLUTION(cells[0]) {
+			return nil, fmt.Errorf("%w: %s at %d and %s at %d",
+				ErrResolutionMismatch, cells[0], H3_GET_RESOLUTION(cells[0]),
+				cell, H3_GET_RESOLUTION(cell))
+		}
+		cells = append(cells, cell)
+	}
+
+	// Sorting also puts duplicates next to each other.
+	slices.Sort(cells)
+	for i := 1; i < len(cells); i++ {
+		if cells[i] == cells[i-1] {
+			return nil, fmt.Errorf("%w: %s", ErrCompactDuplicate, cells[i])
+		}
+	}
+	return cells, nil
+}
+
+// validateCompactCell checks that cell is a valid cell, as required by Compact
+// and Uncompact.
+func validateCompactCell(cell H3Index) error {
+	if H3_GET_MODE(cell) == H3_UNIEDGE_MODE {
+		return fmt.Errorf("%w: %s is a directed edge", ErrInvalidCell, cell)
+	}
+	if !H3IsValid(cell) {
+		return fmt.Errorf("%w: %s", ErrInvalidCell, cell)
+	}
+	return nil
 }
 
 // Uncompact takes a compressed set of hexagons and expands back to the original
-// set of hexagons.
+// set of hexagons. H3_NULL entries are skipped. The result is sorted in
+// ascending order whatever the order of the input, like that of Compact.
 //
-// Return ErrUncompactResExceeded if any hexagon is smaller than the output
-// resolution.
+// Return ErrInvalidCell if the set contains an index which is not a valid
+// cell, such as a directed edge, or ErrUncompactResExceeded if any hexagon is
+// smaller than the output resolution.
 func Uncompact(compactedSet []H3Index, res int) ([]H3Index, error) {
-	maxSize, err := MaxUncompactSize(compactedSet, res)
-	if err != nil {
-		return nil, err
-	}
+	return AppendUncompact(nil, compactedSet, res)
+}
 
-	h3Set := make([]H3Index, 0, maxSize)
+// AppendUncompact appends the expansion of compactedSet at res to dst, as
+// Uncompact does, growing dst at most once. Only the appended cells are
+// sorted.
+//
+// Return the extended slice, or the errors of Uncompact, in which case dst
+// is left unchanged.
+func AppendUncompact(dst []H3Index, compactedSet []H3Index, res int) ([]H3Index, error) {
+	if _, err := MaxUncompactSize(compactedSet, res); err != nil {
+		return dst, err
+	}
 
+	// the set is valid, so the exact size can be allocated up front
+	size := 0
 	for _, cell := range compactedSet {
+		if cell != 0 {
+			size += childrenSize(cell, res)
+		}
+	}
+	start := len(dst)
+	dst = slices.Grow(dst, size)
+
+	// Children come out in ascending order, so expanding the cells in the
+	// order of their first child sorts the result, unless some of them
+	// overlap.
+	cells := slices.Clone(compactedSet)
+	slices.SortFunc(cells, func(a, b H3Index) int {
+		fa, _ := CellRange(a, res)
+		fb, _ := CellRange(b, res)
+		return cmp.Compare(fa, fb)
+	})
+	for _, cell := range cells {
 		if cell == 0 {
 			continue
 		}
 
 		if cell.GetResolution() == res {
-			h3Set = append(h3Set, cell)
+			dst = append(dst, cell)
 		} else {
-			h3Set = append(h3Set, cell.ToChildren(res)...)
+			H3ToChildren(cell, res, &dst)
 		}
 	}
+	if out := dst[start:]; !slices.IsSorted(out) {
+		slices.Sort(out)
+	}
 
-	return h3Set, nil
+	return dst, nil
 }
 
 // MaxUncompactSize takes a compacted set of hexagons are provides an
 // upper-bound estimate of the size of the uncompacted set of hexagons.
 //
-// Return The number of hexagons to allocate memory for, or a negative number
-// if an error occurs.
+// Return The number of hexagons to allocate memory for, ErrInvalidCell if the
+// set contains an index which is not a valid cell, or
+// ErrUncompactResExceeded if any hexagon is smaller than res.
 func MaxUncompactSize(compactedSet []H3Index, res int) (int, error) {
 	maxNumHexagons := 0
 	for i := 0; i < len(compactedSet); i++ {
 		if compactedSet[i] == 0 {
 			continue
 		}
+		if err := validateCompactCell(compactedSet[i]); err != nil {
+			return 0, err
+		}
 		currentRes := H3_GET_RESOLUTION(compactedSet[i])
 		if !_isValidChildRes(currentRes, res) {
 			// Nonsensical. Abort.
@@ -760,7 +1037,7 @@ func (h3 H3Index) IsPentagon() bool {
 // H3Index.
 func _h3LeadingNonZeroDigit(h H3Index) Direction {
 	for r := 1; r <= H3_GET_RESOLUTION(h); r++ {
-		if H3_GET_INDEX_DIGIT(h, r) > 1 {
+		if H3_GET_INDEX_DIGIT(h, r) != CENTER_DIGIT {
 			return H3_GET_INDEX_DIGIT(h, r)
 		}
 	}
@@ -940,7 +1217,7 @@ func GeoToH3(g *GeoCoord, res int) H3Index {
 		return H3_NULL
 	}
 
-	if !math.IsInf(g.lat, 0) || !math.IsInf(g.lon, 0) {
+	if !isFinite(g.lat) || !isFinite(g.lon) {
 		return H3_NULL
 	}
 
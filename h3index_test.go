@@ -0,0 +1,146 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestGeoToH3(t *testing.T) {
+	g := GeoCoord{lat: DegsToRads(37.3615593), lon: DegsToRads(-122.0553238)}
+	if got, want := GeoToH3(&g, 5), H3Index(0x85283473fffffff); got != want {
+		t.Errorf("GeoToH3(%v, 5) = %s, want %s", g, got, want)
+	}
+
+	for _, g := range []GeoCoord{
+		{lat: math.NaN(), lon: 0},
+		{lat: 0, lon: math.Inf(1)},
+		{lat: math.Inf(-1), lon: math.NaN()},
+	} {
+		if got := GeoToH3(&g, 5); got != H3_NULL {
+			t.Errorf("GeoToH3(%v, 5) = %s, want H3_NULL", g, got)
+		}
+	}
+}
+
+func BenchmarkGeoToH3(b *testing.B) {
+	// A grid of points over the whole globe, so that every face and both
+	// resolution classes are exercised.
+	var points []GeoCoord
+	for lat := -85.0; lat <= 85; lat += 10 {
+		for lon := -175.0; lon <= 175; lon += 10 {
+			points = append(points, NewGeoCoordDegs(lat, lon))
+		}
+	}
+	for _, res := range []int{5, 9, 15} {
+		b.Run(fmt.Sprintf("res%d", res), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				GeoToH3(&points[i%len(points)], res)
+			}
+		})
+	}
+}
+
+// naiveCoarsen coarsens the part of the sorted cells at res under cell top
+// down.
+//
+// Return the cells covering it, and whether they are cell alone.
+func naiveCoarsen(cells []H3Index, cell H3Index, res int, threshold float64) ([]H3Index, bool) {
+	if H3_GET_RESOLUTION(cell) == res {
+		if _, found := slices.BinarySearch(cells, cell); found {
+			return []H3Index{cell}, true
+		}
+		return nil, false
+	}
+	var children, out []H3Index
+	H3ToChildren(cell, H3_GET_RESOLUTION(cell)+1, &children)
+	present := 0
+	for _, child := range children {
+		covering, whole := naiveCoarsen(cells, child, res, threshold)
+		if whole {
+			present++
+		}
+		out = append(out, covering...)
+	}
+	if float64(present) >= threshold*float64(len(children)) {
+		return []H3Index{cell}, true
+	}
+	return out, false
+}
+
+func TestCoarsen(t *testing.T) {
+	rng := rand.New(rand.NewPCG(17, 18))
+	base := H3ToParent(coverageRoots()[0], 0)
+	for range 50 {
+		cells := expandCoverage(randomCoverage(rng, 1+rng.IntN(40)))
+		for _, threshold := range []float64{0.1, 0.5, 5.0 / 7, 0.8, 1} {
+			got, err := Coarsen(cells, threshold)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, _ := naiveCoarsen(cells, base, coverageRes, threshold)
+			slices.Sort(want)
+			if !slices.Equal(got, want) {
+				t.Fatalf("Coarsen(%v) = %v, want %v", threshold, got, want)
+			}
+			if threshold == 1 {
+				checkCompacted(t, "Coarsen(1)", got, cells)
+			}
+		}
+	}
+
+	// 5 of 6 children of a pentagon are enough at 0.8, 5 of 7 of a hexagon
+	// are not.
+	for _, root := range coverageRoots() {
+		var children []H3Index
+		H3ToChildren(root, 2, &children)
+		got, err := Coarsen(children[:5], 0.8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if whole := slices.Equal(got, []H3Index{root}); whole != H3IsPentagon(root) {
+			t.Errorf("Coarsen(%v, 0.8) = %v", children[:5], got)
+		}
+	}
+}
+
+func TestCoarsenErrors(t *testing.T) {
+	cell := coverageRoots()[1]
+	for _, threshold := range []float64{0, -1, 1.5, math.NaN()} {
+		if _, err := Coarsen([]H3Index{cell}, threshold); !errors.Is(err, ErrInvalidThreshold) {
+			t.Errorf("Coarsen(%v) error = %v, want %v", threshold, err, ErrInvalidThreshold)
+		}
+	}
+	for _, tt := range []struct {
+		cells []H3Index
+		want  error
+	}{
+		{[]H3Index{cell, H3Index(0x7fffffffffffffff)}, ErrInvalidCell},
+		{[]H3Index{cell, H3ToParent(cell, 0)}, ErrResolutionMismatch},
+		{[]H3Index{cell, cell}, ErrCompactDuplicate},
+	} {
+		if _, err := Coarsen(tt.cells, 0.5); !errors.Is(err, tt.want) {
+			t.Errorf("Coarsen(%v) error = %v, want %v", tt.cells, err, tt.want)
+		}
+	}
+	if got, err := Coarsen([]H3Index{H3_NULL}, 0.5); err != nil || len(got) != 0 {
+		t.Errorf("Coarsen of H3_NULL = %v, %v, want nothing", got, err)
+	}
+}
@@ -0,0 +1,267 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package h3test provides generators of H3 test data for property based tests
+// and fuzzing: valid and invalid indexes, neighbor pairs, cells around
+// pentagons, and coordinates near the antimeridian and the poles.
+//
+// Generators are deterministic for a given seed, so failing cases can be
+// reproduced.
+package h3test
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"github.com/isbang/h3go"
+)
+
+const (
+	numBaseCells = 122
+	maxRes       = 15
+
+	// nearDistance is the maximum distance in radians of coordinates from the
+	// antimeridian or a pole, about 1 km.
+	nearDistance = 1.6e-4
+)
+
+// Gen generates H3 test data from a deterministic pseudo random source.
+type Gen struct {
+	r *rand.Rand
+}
+
+// New returns a generator seeded with seed.
+func New(seed uint64) *Gen {
+	return &Gen{r: rand.New(rand.NewPCG(seed, seed))}
+}
+
+// Res returns a valid resolution.
+func (g *Gen) Res() int {
+	return g.r.IntN(maxRes + 1)
+}
+
+// Cell returns a valid cell at res, drawn uniformly over base cells and
+// digits. It panics if res is out of range.
+func (g *Gen) Cell(res int) h3go.H3Index {
+	if res < 0 || res > maxRes {
+		panic("h3test: resolution out of range")
+	}
+
+	p := h3go.IndexParts{
+		Mode:     h3go.H3_HEXAGON_MODE,
+		Res:      res,
+		BaseCell: g.r.IntN(numBaseCells),
+	}
+	pentagon := isPentagonBaseCell(p.BaseCell)
+
+	leading := true
+	for r := 0; r < maxRes; r++ {
+		if r >= res {
+			p.Digits[r] = h3go.INVALID_DIGIT
+			continue
+		}
+
+		digit := h3go.Direction(g.r.IntN(h3go.NUM_DIGITS))
+		// The K axis subsequence of pentagons is deleted.
+		for leading && pentagon && digit == h3go.K_AXES_DIGIT {
+			digit = h3go.Direction(g.r.IntN(h3go.NUM_DIGITS))
+		}
+		if digit != h3go.CENTER_DIGIT {
+			leading = false
+		}
+		p.Digits[r] = digit
+	}
+
+	return mustCompose(p)
+}
+
+// AnyCell returns a valid cell at a random resolution.
+func (g *Gen) AnyCell() h3go.H3Index {
+	return g.Cell(g.Res())
+}
+
+// InvalidIndex returns an index which is neither a valid cell nor a valid
+// unidirectional edge, made by corrupting one field of a valid cell: the high
+// bit, the mode, the reserved bits, the base cell, a used or an unused digit,
+// or the deleted subsequence of a pentagon.
+func (g *Gen) InvalidIndex() h3go.H3Index {
+	for {
+		cell := g.AnyCell()
+		p, _ := h3go.Decompose(cell)
+
+		switch g.r.IntN(7) {
+		case 0:
+			return cell | 1<<63
+		case 1:
+			modes := []int{0, 3, 4, 15}
+			p.Mode = modes[g.r.IntN(len(modes))]
+		case 2:
+			p.Reserved = 1 + g.r.IntN(7)
+		case 3:
+			p.BaseCell = numBaseCells + g.r.IntN(128-numBaseCells)
+			return forceParts(p)
+		case 4:
+			if p.Res == 0 {
+				continue
+			}
+			p.Digits[g.r.IntN(p.Res)] = h3go.INVALID_DIGIT
+		case 5:
+			if p.Res == maxRes {
+				continue
+			}
+			p.Digits[p.Res+g.r.IntN(maxRes-p.Res)] = h3go.Direction(g.r.IntN(h3go.NUM_DIGITS))
+		case 6:
+			pentagon := g.Pentagon(1 + g.r.IntN(maxRes))
+			p, _ = h3go.Decompose(pentagon)
+			lead := g.r.IntN(p.Res)
+			for r := 0; r < lead; r++ {
+				p.Digits[r] = h3go.CENTER_DIGIT
+			}
+			p.Digits[lead] = h3go.K_AXES_DIGIT
+		}
+		return forceParts(p)
+	}
+}
+
+// NeighborPair returns two distinct adjacent cells at res.
+func (g *Gen) NeighborPair(res int) (h3go.H3Index, h3go.H3Index) {
+	origin := g.Cell(res)
+	neighbors := ringCells(origin, 1)
+	return origin, neighbors[g.r.IntN(len(neighbors))]
+}
+
+// Pentagon returns one of the 12 pentagons at res.
+func (g *Gen) Pentagon(res int) h3go.H3Index {
+	pentagons := make([]h3go.H3Index, h3go.PentagonIndexCount())
+	h3go.GetPentagonIndexes(res, &pentagons)
+	return pentagons[g.r.IntN(len(pentagons))]
+}
+
+// PentagonAdjacent returns a hexagon at res within two grid steps of a
+// pentagon, where pentagon distortion affects most algorithms. At resolution
+// 0 it returns a neighbor of a pentagon base cell.
+func (g *Gen) PentagonAdjacent(res int) h3go.H3Index {
+	k := 2
+	if res == 0 {
+		k = 1
+	}
+	cells := ringCells(g.Pentagon(res), k)
+	return cells[g.r.IntN(len(cells))]
+}
+
+// GeoCoord returns a coordinate drawn uniformly over the sphere.
+func (g *Gen) GeoCoord() h3go.GeoCoord {
+	lat := math.Asin(2*g.r.Float64() - 1)
+	lon := (2*g.r.Float64() - 1) * math.Pi
+	return h3go.NewGeoCoordRads(lat, lon)
+}
+
+// AntimeridianCoord returns a coordinate within about 1 km of the
+// antimeridian on either side, sometimes exactly on it.
+func (g *Gen) AntimeridianCoord() h3go.GeoCoord {
+	lat := math.Asin(2*g.r.Float64() - 1)
+	lon := math.Pi
+	if g.r.IntN(8) != 0 {
+		lon -= g.r.Float64() * nearDistance
+	}
+	if g.r.IntN(2) == 0 {
+		lon = -lon
+	}
+	return h3go.NewGeoCoordRads(lat, lon)
+}
+
+// PoleCoord returns a coordinate within about 1 km of either pole, sometimes
+// exactly on it.
+func (g *Gen) PoleCoord() h3go.GeoCoord {
+	lat := math.Pi / 2
+	if g.r.IntN(8) != 0 {
+		lat -= g.r.Float64() * nearDistance
+	}
+	if g.r.IntN(2) == 0 {
+		lat = -lat
+	}
+	lon := (2*g.r.Float64() - 1) * math.Pi
+	return h3go.NewGeoCoordRads(lat, lon)
+}
+
+// Loop returns a polygon loop of n vertices around center, at irregular
+// distances between half of and all of radiusRads. The vertices go around
+// center in order, so the loop is simple for n of 8 or more, and a loop of
+// at most a quarter of radiusRads around the same center fits inside it as
+// a hole. Loops around a coordinate from AntimeridianCoord cross the
+// antimeridian; center must be further than radiusRads from either pole.
+func (g *Gen) Loop(center h3go.GeoCoord, radiusRads float64, n int) []h3go.GeoCoord {
+	lat0, lon0 := center.LatRads(), center.LonRads()
+	loop := make([]h3go.GeoCoord, n)
+	for i := range loop {
+		az := 2 * math.Pi * (float64(i) + 0.8*g.r.Float64()) / float64(n)
+		d := radiusRads * (0.5 + 0.5*g.r.Float64())
+		lat := lat0 + d*math.Sin(az)
+		lon := lon0 + d*math.Cos(az)/math.Cos(lat0)
+		if lon > math.Pi {
+			lon -= 2 * math.Pi
+		} else if lon < -math.Pi {
+			lon += 2 * math.Pi
+		}
+		loop[i] = h3go.NewGeoCoordRads(lat, lon)
+	}
+	return loop
+}
+
+// isPentagonBaseCell reports whether baseCell is a pentagon.
+func isPentagonBaseCell(baseCell int) bool {
+	p := h3go.IndexParts{Mode: h3go.H3_HEXAGON_MODE, BaseCell: baseCell}
+	for r := range p.Digits {
+		p.Digits[r] = h3go.INVALID_DIGIT
+	}
+	return mustCompose(p).IsPentagon()
+}
+
+// ringCells returns the cells within k grid steps of origin, excluding
+// origin.
+func ringCells(origin h3go.H3Index, k int) []h3go.H3Index {
+	disk, err := h3go.GridDisk(origin, k)
+	if err != nil {
+		panic("h3test: " + err.Error())
+	}
+	var out []h3go.H3Index
+	for _, cell := range disk {
+		if cell != origin {
+			out = append(out, cell)
+		}
+	}
+	return out
+}
+
+// mustCompose composes valid parts, panicking on invalid ones.
+func mustCompose(p h3go.IndexParts) h3go.H3Index {
+	h, err := h3go.Compose(p)
+	if err != nil {
+		panic("h3test: " + err.Error())
+	}
+	return h
+}
+
+// forceParts sets the bit fields of an index from p without validation.
+func forceParts(p h3go.IndexParts) h3go.H3Index {
+	h := h3go.H3_INIT
+	h.SetMode(p.Mode)
+	h.SetResolution(p.Res)
+	h.SetBaseCell(p.BaseCell)
+	h.SetReservedBits(p.Reserved)
+	for r := 1; r <= maxRes; r++ {
+		h.SetIndexDigit(r, p.Digits[r-1])
+	}
+	return h
+}
@@ -14,6 +14,8 @@
 
 package h3go
 
+import "fmt"
+
 // H3IndexesAreNeighbors returns whether or not the provided H3Indexes are
 // neighbors.
 func H3IndexesAreNeighbors(origin H3Index, destination H3Index) bool {
@@ -62,7 +64,7 @@ func H3IndexesAreNeighbors(origin H3Index, destination H3Index) bool {
 	}
 
 	// Otherwise, we have to determine the neighbor relationship the "hard" way.
-	neighborRing := KRing(origin, 1)
+	neighborRing := kRing(origin, 1)
 	for i := 0; i < 7; i++ {
 		if neighborRing[i] == destination {
 			return true
@@ -82,34 +84,90 @@ func GetH3UnidirectionalEdge(origin H3Index, destination H3Index) H3Index {
 	}
 
 	// Otherwise, determine the IJK direction from the origin to the destination
+	direction := directionForNeighbor(origin, destination)
+	if direction == INVALID_DIGIT {
+		// This should be impossible, return H3_NULL in this case;
+		return H3_NULL // LCOV_EXCL_LINE
+	}
+
 	output := origin
 	H3_SET_MODE(&output, H3_UNIEDGE_MODE)
+	H3_SET_RESERVED_BITS(&output, int(direction))
+	return output
+}
 
-	isPentagon := H3IsPentagon(origin)
+// DirectionForNeighbor determines the direction in which destination lies
+// from origin, the inverse of stepping from a cell to its neighbor in a
+// direction.
+//
+// Return the direction, ErrInvalidCell if either cell is not a valid cell,
+// or ErrNotNeighbors if the cells are not neighbors.
+func DirectionForNeighbor(origin, destination H3Index) (Direction, error) {
+	if !H3IsValid(origin) {
+		return INVALID_DIGIT, fmt.Errorf("%w: %s", ErrInvalidCell, origin)
+	}
+	if !H3IsValid(destination) {
+		return INVALID_DIGIT, fmt.Errorf("%w: %s", ErrInvalidCell, destination)
+	}
+	if !H3IndexesAreNeighbors(origin, destination) {
+		return INVALID_DIGIT, fmt.Errorf("%w: %s and %s", ErrNotNeighbors, origin, destination)
+	}
+
+	direction := directionForNeighbor(origin, destination)
+	if direction == INVALID_DIGIT {
+		return INVALID_DIGIT, fmt.Errorf("%w: %s and %s", ErrNotNeighbors, origin, destination)
+	}
+	return direction, nil
+}
+
+// directionForNeighbor determines the direction in which destination lies
+// from origin.
+//
+// Cells differing only in their finest digit are siblings, and the direction
+// between them can be read off the digit tables of h3NeighborRotations; it is
+// confirmed with a single step. Otherwise each neighbor is checked in turn.
+//
+// Return the direction, or INVALID_DIGIT if destination is not a neighbor of
+// origin.
+func directionForNeighbor(origin, destination H3Index) Direction {
+	res := H3_GET_RESOLUTION(origin)
+	resDigit := (MAX_H3_RES - res) * H3_PER_DIGIT_OFFSET
+	if res > 0 && uint64(origin^destination)&^(H3_DIGIT_MASK<<resDigit) == 0 {
+		originDigit := H3_GET_INDEX_DIGIT(origin, res)
+		destinationDigit := H3_GET_INDEX_DIGIT(destination, res)
+		newDigit, adjustment := &NEW_DIGIT_III, &NEW_ADJUSTMENT_III
+		if isResClassIII(res) {
+			newDigit, adjustment = &NEW_DIGIT_II, &NEW_ADJUSTMENT_II
+		}
+		if originDigit < INVALID_DIGIT && destinationDigit < INVALID_DIGIT {
+			for direction := K_AXES_DIGIT; direction < INVALID_DIGIT; direction++ {
+				if newDigit[originDigit][direction] != destinationDigit ||
+					adjustment[originDigit][direction] != CENTER_DIGIT {
+					continue
+				}
+				rotations := 0
+				if h3NeighborRotations(origin, direction, &rotations) == destination {
+					return direction
+				}
+				break
+			}
+		}
+	}
 
 	// Checks each neighbor, in order, to determine which direction the
 	// destination neighbor is located. Skips CENTER_DIGIT since that
 	// would be this index.
-	var neighbor H3Index
-	// Excluding from branch coverage as we never hit the end condition
-	// LCOV_EXCL_BR_START
 	direction := K_AXES_DIGIT
-	if isPentagon {
+	if H3IsPentagon(origin) {
 		direction = J_AXES_DIGIT
 	}
-
-	for ; direction < Direction(NUM_DIGITS); direction++ {
-		// LCOV_EXCL_BR_STOP
+	for ; direction < INVALID_DIGIT; direction++ {
 		rotations := 0
-		neighbor = h3NeighborRotations(origin, direction, &rotations)
-		if neighbor == destination {
-			H3_SET_RESERVED_BITS(&output, int(direction))
-			return output
+		if h3NeighborRotations(origin, direction, &rotations) == destination {
+			return direction
 		}
 	}
-
-	// This should be impossible, return H3_NULL in this case;
-	return H3_NULL // LCOV_EXCL_LINE
+	return INVALID_DIGIT
 }
 
 // GetOriginH3IndexFromUnidirectionalEdge returns the origin hexagon from the
@@ -149,12 +207,14 @@ func H3UnidirectionalEdgeIsValid(edge H3Index) bool {
 		return false
 	}
 
+	// The origin is validated first, as the pentagon lookup needs a base cell
+	// in range.
 	origin := GetOriginH3IndexFromUnidirectionalEdge(edge)
-	if H3IsPentagon(origin) && neighborDirection == int(K_AXES_DIGIT) {
+	if !H3IsValid(origin) {
 		return false
 	}
 
-	return H3IsValid(origin)
+	return !(H3IsPentagon(origin) && neighborDirection == int(K_AXES_DIGIT))
 }
 
 // GetH3IndexesFromUnidirectionalEdge returns the origin, destination pair of
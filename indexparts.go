@@ -0,0 +1,134 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import "fmt"
+
+// IndexParts is the structured form of the bit fields of an H3Index.
+//
+// Digits holds the indexing digits of resolutions 1 to 15 at positions 0 to
+// 14. Digits finer than Res are INVALID_DIGIT in a valid index. Reserved is
+// zero for cells and holds the edge direction for unidirectional edges.
+type IndexParts struct {
+	Mode     int
+	Res      int
+	BaseCell int
+	Digits   [MAX_H3_RES]Direction
+	Reserved int
+}
+
+// Decompose splits h into its bit fields.
+//
+// The parts are returned even if h is not a valid cell or unidirectional
+// edge, in which case the error describes the problem.
+//
+// Return ErrInvalidIndex if h is not a valid cell or unidirectional edge.
+func Decompose(h H3Index) (IndexParts, error) {
+	p := IndexParts{
+		Mode:     H3_GET_MODE(h),
+		Res:      H3_GET_RESOLUTION(h),
+		BaseCell: H3_GET_BASE_CELL(h),
+		Reserved: H3_GET_RESERVED_BITS(h),
+	}
+	for r := 1; r <= MAX_H3_RES; r++ {
+		p.Digits[r-1] = H3_GET_INDEX_DIGIT(h, r)
+	}
+
+	if H3_GET_HIGH_BIT(h) != 0 {
+		return p, fmt.Errorf("%w: %s: high bit is set", ErrInvalidIndex, h)
+	}
+	return p, validateIndex(h)
+}
+
+// Compose assembles an index from its bit fields. It is the inverse of
+// Decompose for valid indexes.
+//
+// Return ErrInvalidIndex if a field does not fit its bits, or if the result
+// is not a valid cell or unidirectional edge.
+func Compose(p IndexParts) (H3Index, error) {
+	switch {
+	case p.Mode < 0 || p.Mode > 15:
+		return H3_NULL, fmt.Errorf("%w: mode %d out of range", ErrInvalidIndex, p.Mode)
+	case p.Res < 0 || p.Res > MAX_H3_RES:
+		return H3_NULL, fmt.Errorf("%w: resolution %d out of range", ErrInvalidIndex, p.Res)
+	case p.BaseCell < 0 || p.BaseCell >= NUM_BASE_CELLS:
+		return H3_NULL, fmt.Errorf("%w: base cell %d out of range", ErrInvalidIndex, p.BaseCell)
+	case p.Reserved < 0 || p.Reserved > 7:
+		return H3_NULL, fmt.Errorf("%w: reserved bits %d out of range", ErrInvalidIndex, p.Reserved)
+	}
+
+	h := H3_INIT
+	H3_SET_MODE(&h, p.Mode)
+	H3_SET_RESOLUTION(&h, p.Res)
+	H3_SET_BASE_CELL(&h, p.BaseCell)
+	H3_SET_RESERVED_BITS(&h, p.Reserved)
+	for r := 1; r <= MAX_H3_RES; r++ {
+		digit := p.Digits[r-1]
+		if digit < CENTER_DIGIT || digit > INVALID_DIGIT {
+			return H3_NULL, fmt.Errorf("%w: digit %d at resolution %d out of range", ErrInvalidIndex, digit, r)
+		}
+		H3_SET_INDEX_DIGIT(&h, r, digit)
+	}
+
+	if err := validateIndex(h); err != nil {
+		return H3_NULL, err
+	}
+	return h, nil
+}
+
+// validateIndex checks that h is a valid cell or unidirectional edge,
+// describing the first problem found otherwise.
+func validateIndex(h H3Index) error {
+	switch H3_GET_MODE(h) {
+	case H3_HEXAGON_MODE:
+		if H3_GET_RESERVED_BITS(h) != 0 {
+			return fmt.Errorf("%w: %s: reserved bits set on a cell", ErrInvalidIndex, h)
+		}
+	case H3_UNIEDGE_MODE:
+		if H3UnidirectionalEdgeIsValid(h) {
+			return nil
+		}
+		return fmt.Errorf("%w: %s: invalid unidirectional edge", ErrInvalidIndex, h)
+	default:
+		return fmt.Errorf("%w: %s: unknown mode %d", ErrInvalidIndex, h, H3_GET_MODE(h))
+	}
+
+	baseCell := H3_GET_BASE_CELL(h)
+	if baseCell >= NUM_BASE_CELLS {
+		return fmt.Errorf("%w: %s: base cell %d out of range", ErrInvalidIndex, h, baseCell)
+	}
+
+	res := H3_GET_RESOLUTION(h)
+	if res > MAX_H3_RES {
+		return fmt.Errorf("%w: %s: resolution %d out of range", ErrInvalidIndex, h, res)
+	}
+
+	for r := 1; r <= res; r++ {
+		if H3_GET_INDEX_DIGIT(h, r) == INVALID_DIGIT {
+			return fmt.Errorf("%w: %s: invalid digit at resolution %d", ErrInvalidIndex, h, r)
+		}
+	}
+	for r := res + 1; r <= MAX_H3_RES; r++ {
+		if H3_GET_INDEX_DIGIT(h, r) != INVALID_DIGIT {
+			return fmt.Errorf("%w: %s: digit set at unused resolution %d", ErrInvalidIndex, h, r)
+		}
+	}
+
+	if _isBaseCellPentagon(baseCell) && _h3LeadingNonZeroDigit(h) == K_AXES_DIGIT {
+		return fmt.Errorf("%w: %s: deleted K axis subsequence of a pentagon", ErrInvalidIndex, h)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestDecomposeRandom(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < 200000; i++ {
+		h := H3Index(rng.Uint64())
+		if i%2 == 0 {
+			// Mostly well formed: a cell or edge mode, a resolution, a base
+			// cell and digits in range, with an occasional bit flipped.
+			h = H3_INIT
+			H3_SET_MODE(&h, 1+rng.IntN(2))
+			res := rng.IntN(MAX_H3_RES + 1)
+			H3_SET_RESOLUTION(&h, res)
+			H3_SET_BASE_CELL(&h, rng.IntN(NUM_BASE_CELLS+2))
+			H3_SET_RESERVED_BITS(&h, rng.IntN(8))
+			for r := 1; r <= res; r++ {
+				H3_SET_INDEX_DIGIT(&h, r, Direction(rng.IntN(int(NUM_DIGITS))))
+			}
+			if rng.IntN(4) == 0 {
+				h ^= 1 << rng.IntN(64)
+			}
+		}
+
+		valid := H3IsValid(h) || H3UnidirectionalEdgeIsValid(h)
+		p, err := Decompose(h)
+		if (err == nil) != valid {
+			t.Fatalf("Decompose(%#x) error = %v, want valid %t", uint64(h), err, valid)
+		}
+		if err != nil {
+			continue
+		}
+		if got, err := Compose(p); err != nil || got != h {
+			t.Fatalf("Compose(Decompose(%#x)) = %#x, %v", uint64(h), uint64(got), err)
+		}
+	}
+}
+
+func TestComposeRandom(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	for i := 0; i < 100000; i++ {
+		p := IndexParts{
+			Mode:     rng.IntN(4),
+			Res:      rng.IntN(MAX_H3_RES + 2),
+			BaseCell: rng.IntN(NUM_BASE_CELLS + 2),
+			Reserved: rng.IntN(8),
+		}
+		for r := range p.Digits {
+			p.Digits[r] = INVALID_DIGIT
+			if r < p.Res {
+				p.Digits[r] = Direction(rng.IntN(int(NUM_DIGITS)))
+			}
+		}
+
+		h, err := Compose(p)
+		if err != nil {
+			continue
+		}
+		if got, err := Decompose(h); err != nil || got != p {
+			t.Fatalf("Decompose(Compose(%+v)) = %+v, %v", p, got, err)
+		}
+	}
+}
+
+func TestDecomposeEdgeBaseCellOutOfRange(t *testing.T) {
+	h := H3Index(0x16afee034ccd5d87)
+	if _, err := Decompose(h); err == nil {
+		t.Errorf("Decompose(%#x) succeeded", uint64(h))
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+)
+
+// Isochrone grows outward from the seed cells, accumulating the cost of
+// every cell entered, and finds all cells that can be reached within budget
+// together with the cheapest cost of reaching them. Seeds are reached at cost
+// 0. A nil cost gives every cell a cost of 1, which makes the result the
+// cells within budget grid steps of the seeds.
+//
+// The expansion is Dijkstra's algorithm over the grid, so the cost of each
+// cell is evaluated at most once and only for cells next to the reached
+// region. Cells of cost 0 do not use up any of the budget, so a cost
+// function returning 0 over a large area makes the expansion cover all of
+// it; give cells that should stop the expansion a cost of +Inf.
+//
+// Return the cost of reaching each reached cell, ErrInvalidCell if a seed is
+// not a valid cell, ErrResolutionMismatch if the seeds are at different
+// resolutions, or ErrInvalidCost if budget or a cell's cost is negative or
+// NaN.
+func Isochrone(seeds []H3Index, budget float64, cost CellCostFunc) (map[H3Index]float64, error) {
+	if budget < 0 || math.IsNaN(budget) {
+		return nil, fmt.Errorf("%w: budget %v", ErrInvalidCost, budget)
+	}
+	for _, seed := range seeds {
+		if !H3IsValid(seed) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCell, seed)
+		}
+		if H3_GET_RESOLUTION(seed) != H3_GET_RESOLUTION(seeds[0]) {
+			return nil, fmt.Errorf("%w: %d and %d", ErrResolutionMismatch,
+				H3_GET_RESOLUTION(seeds[0]), H3_GET_RESOLUTION(seed))
+		}
+	}
+
+	reached := make(map[H3Index]float64, len(seeds))
+	queue := make(cellQueue, 0, len(seeds))
+	for _, seed := range seeds {
+		if _, ok := reached[seed]; !ok {
+			reached[seed] = 0
+			queue = append(queue, cellQueueItem{cell: seed})
+		}
+	}
+
+	costs := make(map[H3Index]float64)
+	var neighbors []H3Index
+	for queue.Len() > 0 {
+		item := heap.Pop(&queue).(cellQueueItem)
+		if item.cost > reached[item.cell] {
+			// superseded by a cheaper way to the same cell
+			continue
+		}
+
+		neighbors = appendNeighbors(neighbors[:0], item.cell)
+		for _, neighbor := range neighbors {
+			c, ok := costs[neighbor]
+			if !ok {
+				c = 1
+				if cost != nil {
+					c = cost(neighbor)
+				}
+				if c < 0 || math.IsNaN(c) {
+					return nil, fmt.Errorf("%w: %v for %s", ErrInvalidCost, c, neighbor)
+				}
+				costs[neighbor] = c
+			}
+
+			total := item.cost + c
+			if total > budget {
+				continue
+			}
+			if prev, seen := reached[neighbor]; seen && prev <= total {
+				continue
+			}
+			reached[neighbor] = total
+			heap.Push(&queue, cellQueueItem{cell: neighbor, cost: total, priority: total})
+		}
+	}
+	return reached, nil
+}
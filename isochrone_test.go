@@ -0,0 +1,160 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestIsochroneUnitCost(t *testing.T) {
+	// With unit costs the result is the disk of radius budget, with the
+	// grid distances as costs, also across pentagons.
+	for _, origin := range gridTestOrigins() {
+		for _, budget := range []float64{0, 1, 3.5} {
+			got, err := Isochrone([]H3Index{origin}, budget, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := diskDistances(origin, int(budget))
+			if len(got) != len(want) {
+				t.Fatalf("Isochrone(%s, %v) reached %d cells, want %d", origin, budget, len(got), len(want))
+			}
+			for cell, d := range want {
+				if got[cell] != float64(d) {
+					t.Fatalf("Isochrone(%s, %v) reached %s at %v, want %d", origin, budget, cell, got[cell], d)
+				}
+			}
+		}
+	}
+
+	// Several seeds reach each cell from the closest one.
+	pentagons := make([]H3Index, PentagonIndexCount())
+	GetPentagonIndexes(3, &pentagons)
+	ring, err := GridRing(pentagons[0], 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seeds := []H3Index{pentagons[0], ring[0], ring[0]}
+	got, err := Isochrone(seeds, 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := diskDistances(seeds[0], 2)
+	for cell, d := range diskDistances(seeds[1], 2) {
+		if prev, ok := want[cell]; !ok || d < prev {
+			want[cell] = d
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Isochrone(%v, 2) reached %d cells, want %d", seeds, len(got), len(want))
+	}
+	for cell, d := range want {
+		if got[cell] != float64(d) {
+			t.Fatalf("Isochrone(%v, 2) reached %s at %v, want %d", seeds, cell, got[cell], d)
+		}
+	}
+}
+
+func TestIsochroneWeighted(t *testing.T) {
+	pentagons := make([]H3Index, PentagonIndexCount())
+	GetPentagonIndexes(3, &pentagons)
+	rng := rand.New(rand.NewPCG(3, 4))
+
+	for _, pentagon := range pentagons {
+		// Free, impassable and weighted cells around the pentagon, walled
+		// in by impassable cells so that the free ones cannot leak out.
+		disk, err := GridDisk(pentagon, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		area := NewCellSet(disk...)
+		costs := make(map[H3Index]float64, len(disk))
+		for _, cell := range disk {
+			switch rng.IntN(6) {
+			case 0:
+				costs[cell] = 0
+			case 1:
+				costs[cell] = math.Inf(1)
+			default:
+				costs[cell] = 2 * rng.Float64()
+			}
+		}
+		cost := func(cell H3Index) float64 {
+			if c, ok := costs[cell]; ok {
+				return c
+			}
+			return math.Inf(1)
+		}
+
+		seed := disk[rng.IntN(len(disk))]
+		budget := 4.0
+		got, err := Isochrone([]H3Index{seed}, budget, cost)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cheapest := cheapestCosts(seed, area, cost)
+		reached := 0
+		for cell, c := range cheapest {
+			if c > budget {
+				continue
+			}
+			reached++
+			if math.Abs(got[cell]-c) > 1e-9 {
+				t.Fatalf("Isochrone from %s reached %s at %v, want %v", seed, cell, got[cell], c)
+			}
+		}
+		if len(got) != reached {
+			t.Fatalf("Isochrone from %s reached %d cells, want %d", seed, len(got), reached)
+		}
+	}
+}
+
+func TestIsochroneErrors(t *testing.T) {
+	pentagons := make([]H3Index, PentagonIndexCount())
+	GetPentagonIndexes(3, &pentagons)
+	seed := pentagons[0]
+
+	for _, tt := range []struct {
+		name   string
+		seeds  []H3Index
+		budget float64
+		cost   CellCostFunc
+		want   error
+	}{
+		{"negative budget", []H3Index{seed}, -1, nil, ErrInvalidCost},
+		{"NaN budget", []H3Index{seed}, math.NaN(), nil, ErrInvalidCost},
+		{"negative cost", []H3Index{seed}, 2, func(H3Index) float64 { return -1 }, ErrInvalidCost},
+		{"NaN cost", []H3Index{seed}, 2, func(H3Index) float64 { return math.NaN() }, ErrInvalidCost},
+		{"invalid seed", []H3Index{seed, H3Index(0x7fffffffffffffff)}, 2, nil, ErrInvalidCell},
+		{"resolution mismatch", []H3Index{seed, H3ToParent(seed, 2)}, 2, nil, ErrResolutionMismatch},
+	} {
+		if _, err := Isochrone(tt.seeds, tt.budget, tt.cost); !errors.Is(err, tt.want) {
+			t.Errorf("Isochrone with %s error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// Impassable cells all around leave just the seeds.
+	got, err := Isochrone([]H3Index{seed}, 100, func(H3Index) float64 { return math.Inf(1) })
+	if err != nil || len(got) != 1 || got[seed] != 0 {
+		t.Errorf("Isochrone with impassable cells = %v, %v, want only the seed", got, err)
+	}
+	if got, err := Isochrone(nil, 3, nil); err != nil || len(got) != 0 {
+		t.Errorf("Isochrone without seeds = %v, %v, want nothing", got, err)
+	}
+}
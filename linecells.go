@@ -0,0 +1,163 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"fmt"
+	"math"
+)
+
+// Maximum number of times GreatCircleLineCells halves the gap between two
+// samples whose cells are not neighbors.
+const MAX_LINE_SAMPLE_DEPTH = 24
+
+// GreatCircleLineCells traces the great circle arc from a to b through the
+// cells at res it passes, in order. Unlike GridPathCells, which draws lines
+// in grid space, the cells follow the actual shortest path on the sphere.
+//
+// The arc is sampled at four times the number of cells lineHexEstimate
+// expects along it, and wherever two consecutive samples land in cells that
+// are not neighbors, typically because the arc clips the corner of a cell
+// between them, the gap is halved until the cell in between is found.
+// Antipodal points are joined along an arbitrary one of the great circles
+// through both.
+//
+// Return the cells from the cell containing a to the cell containing b,
+// without duplicates, ErrInvalidResolution if res is out of range, or
+// ErrInvalidGeoCoord if a or b has a non-finite component.
+func GreatCircleLineCells(a, b GeoCoord, res int) ([]H3Index, error) {
+	if res < 0 || res > MAX_H3_RES {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidResolution, res)
+	}
+	for _, p := range [2]*GeoCoord{&a, &b} {
+		if !isFinite(p.lat) || !isFinite(p.lon) {
+			return nil, fmt.Errorf("%w: %v, %v", ErrInvalidGeoCoord, p.lat, p.lon)
+		}
+	}
+
+	t := arcTracer{a: a, dist: arcLengthRads(&a, &b), res: res}
+	t.az = _geoAzimuthRads(&a, &b)
+
+	n := 4 * lineHexEstimate(&a, &b, res)
+	seen := NewCellSet()
+	var out []H3Index
+	add := func(cell H3Index) {
+		if !seen.Has(cell) {
+			seen.Add(cell)
+			out = append(out, cell)
+		}
+	}
+
+	prev := GeoToH3(&a, res)
+	add(prev)
+	for i := 1; i <= n; i++ {
+		f := float64(i) / float64(n)
+		cell := t.cellAt(f)
+		if i == n {
+			// land exactly on b rather than on the end of the computed arc
+			cell = GeoToH3(&b, res)
+		}
+		if cell == prev {
+			continue
+		}
+		t.fillGap(float64(i-1)/float64(n), prev, f, cell, MAX_LINE_SAMPLE_DEPTH, add)
+		add(cell)
+		prev = cell
+	}
+	return out, nil
+}
+
+// arcTracer samples the cells along a great circle arc.
+type arcTracer struct {
+	a    GeoCoord // start of the arc
+	az   float64  // azimuth of the arc at a, in radians
+	dist float64  // length of the arc, in radians
+	res  int      // resolution of the cells
+}
+
+// cellAt returns the cell containing the point at fraction f along the arc.
+func (t *arcTracer) cellAt(f float64) H3Index {
+	var p GeoCoord
+	_geoAzDistanceRads(&t.a, t.az, t.dist*f, &p)
+	return GeoToH3(&p, t.res)
+}
+
+// fillGap adds the cells the arc passes between the samples at fractions f0
+// and f1, which landed in cells c0 and c1, by halving the gap as long as the
+// cells at its ends are not neighbors.
+func (t *arcTracer) fillGap(f0 float64, c0 H3Index, f1 float64, c1 H3Index, depth int, add func(H3Index)) {
+	if depth == 0 || c0 == c1 || H3IndexesAreNeighbors(c0, c1) {
+		return
+	}
+	fm := (f0 + f1) / 2
+	if fm <= f0 || fm >= f1 || math.IsNaN(fm) {
+		return
+	}
+	cm := t.cellAt(fm)
+	t.fillGap(f0, c0, fm, cm, depth-1, add)
+	if cm != c0 && cm != c1 {
+		add(cm)
+	}
+	t.fillGap(fm, cm, f1, c1, depth-1, add)
+}
+
+// PolylineCells covers the polyline through points, such as a GPS track or a
+// road geometry, with the cells at res its segments pass, each segment traced
+// along the great circle as GreatCircleLineCells does. A positive k widens
+// the cover by every cell within k grid steps of it, as GridDisks does.
+//
+// Return the cells without duplicates, in the order the polyline reaches
+// them or, when widened, the order of their disks, ErrInvalidResolution if res
+// is out of range, ErrInvalidGeoCoord if a point has a non-finite component,
+// or ErrInvalidK if k is out of range.
+func PolylineCells(points []GeoCoord, res int, k int) ([]H3Index, error) {
+	if res < 0 || res > MAX_H3_RES {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidResolution, res)
+	}
+	if _, err := MaxGridDiskSize(k); err != nil {
+		return nil, err
+	}
+
+	var out []H3Index
+	switch len(points) {
+	case 0:
+		return nil, nil
+	case 1:
+		cell := GeoToH3(&points[0], res)
+		if cell == H3_NULL {
+			return nil, fmt.Errorf("%w: point 0", ErrInvalidGeoCoord)
+		}
+		out = []H3Index{cell}
+	default:
+		seen := NewCellSet()
+		for i := 1; i < len(points); i++ {
+			line, err := GreatCircleLineCells(points[i-1], points[i], res)
+			if err != nil {
+				return nil, fmt.Errorf("%w: segment %d", err, i-1)
+			}
+			for _, cell := range line {
+				if !seen.Has(cell) {
+					seen.Add(cell)
+					out = append(out, cell)
+				}
+			}
+		}
+	}
+
+	if k == 0 {
+		return out, nil
+	}
+	return GridDisks(out, k, true)
+}
@@ -0,0 +1,348 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+// Return codes of normalizeMultiPolygon.
+const (
+	// NORMALIZATION_SUCCESS is returned when the polygon was normalized.
+	NORMALIZATION_SUCCESS = 0
+	// NORMALIZATION_ERR_MULTIPLE_POLYGONS is returned when the input had
+	// more than one polygon and was left untouched.
+	NORMALIZATION_ERR_MULTIPLE_POLYGONS = 1
+	// NORMALIZATION_ERR_UNASSIGNED_HOLES is returned when a hole could not
+	// be assigned to an outer loop and was dropped.
+	NORMALIZATION_ERR_UNASSIGNED_HOLES = 2
+)
+
+// LinkedGeoCoord is a vertex node in a linked geo structure, part of a linked
+// list.
+type LinkedGeoCoord struct {
+	vertex GeoCoord
+	next   *LinkedGeoCoord
+}
+
+// LinkedGeoLoop is a loop node in a linked geo structure, part of a linked
+// list.
+type LinkedGeoLoop struct {
+	first *LinkedGeoCoord
+	last  *LinkedGeoCoord
+	next  *LinkedGeoLoop
+}
+
+// LinkedGeoPolygon is a polygon node in a linked geo structure, part of a
+// linked list. The first loop of a polygon is its exterior boundary, and the
+// others are its holes.
+type LinkedGeoPolygon struct {
+	first *LinkedGeoLoop
+	last  *LinkedGeoLoop
+	next  *LinkedGeoPolygon
+}
+
+// Vertex returns the coordinates of the vertex.
+func (c *LinkedGeoCoord) Vertex() GeoCoord {
+	return c.vertex
+}
+
+// Next returns the next vertex of the loop, or nil for the last one.
+func (c *LinkedGeoCoord) Next() *LinkedGeoCoord {
+	return c.next
+}
+
+// First returns the first vertex of the loop, or nil if it is empty.
+func (loop *LinkedGeoLoop) First() *LinkedGeoCoord {
+	return loop.first
+}
+
+// Next returns the next loop of the polygon, or nil for the last one.
+func (loop *LinkedGeoLoop) Next() *LinkedGeoLoop {
+	return loop.next
+}
+
+// Geofence returns the vertices of the loop as a geofence.
+func (loop *LinkedGeoLoop) Geofence() Geofence {
+	fence := Geofence{numVerts: countLinkedCoords(loop)}
+	fence.verts = make([]GeoCoord, 0, fence.numVerts)
+	for coord := loop.first; coord != nil; coord = coord.next {
+		fence.verts = append(fence.verts, coord.vertex)
+	}
+	return fence
+}
+
+// First returns the exterior boundary of the polygon, or nil if it is empty.
+func (polygon *LinkedGeoPolygon) First() *LinkedGeoLoop {
+	return polygon.first
+}
+
+// Next returns the next polygon of the multipolygon, or nil for the last
+// one.
+func (polygon *LinkedGeoPolygon) Next() *LinkedGeoPolygon {
+	return polygon.next
+}
+
+// GeoPolygon returns the loops of the polygon as a polygon with holes.
+func (polygon *LinkedGeoPolygon) GeoPolygon() GeoPolygon {
+	if polygon.first == nil {
+		return GeoPolygon{}
+	}
+
+	var holes []Geofence
+	for loop := polygon.first.next; loop != nil; loop = loop.next {
+		holes = append(holes, loop.Geofence())
+	}
+	return NewGeoPolygon(polygon.first.Geofence(), holes...)
+}
+
+// addNewLinkedPolygon adds a linked polygon to the end of the linked list of
+// polygon, which must be its last element.
+//
+// Return the new polygon.
+func addNewLinkedPolygon(polygon *LinkedGeoPolygon) *LinkedGeoPolygon {
+	next := &LinkedGeoPolygon{}
+	polygon.next = next
+	return next
+}
+
+// addNewLinkedLoop adds a new linked loop to the current polygon.
+//
+// Return the new loop.
+func addNewLinkedLoop(polygon *LinkedGeoPolygon) *LinkedGeoLoop {
+	loop := &LinkedGeoLoop{}
+	return addLinkedLoop(polygon, loop)
+}
+
+// addLinkedLoop adds an existing linked loop to the current polygon.
+//
+// Return the added loop.
+func addLinkedLoop(polygon *LinkedGeoPolygon, loop *LinkedGeoLoop) *LinkedGeoLoop {
+	if polygon.last == nil {
+		polygon.first = loop
+	} else {
+		polygon.last.next = loop
+	}
+	polygon.last = loop
+	return loop
+}
+
+// addLinkedCoord adds a new linked coordinate to the current loop.
+//
+// Return the new coordinate.
+func addLinkedCoord(loop *LinkedGeoLoop, vertex *GeoCoord) *LinkedGeoCoord {
+	coord := &LinkedGeoCoord{vertex: *vertex}
+	if loop.last == nil {
+		loop.first = coord
+	} else {
+		loop.last.next = coord
+	}
+	loop.last = coord
+	return coord
+}
+
+// countLinkedPolygons counts the polygons in a multipolygon.
+//
+// Return the number of polygons.
+func countLinkedPolygons(polygon *LinkedGeoPolygon) int {
+	count := 0
+	for ; polygon != nil; polygon = polygon.next {
+		count++
+	}
+	return count
+}
+
+// countLinkedLoops counts the loops in a polygon.
+//
+// Return the number of loops.
+func countLinkedLoops(polygon *LinkedGeoPolygon) int {
+	count := 0
+	for loop := polygon.first; loop != nil; loop = loop.next {
+		count++
+	}
+	return count
+}
+
+// countLinkedCoords counts the coordinates in a loop.
+//
+// Return the number of coordinates.
+func countLinkedCoords(loop *LinkedGeoLoop) int {
+	count := 0
+	for coord := loop.first; coord != nil; coord = coord.next {
+		count++
+	}
+	return count
+}
+
+// isClockwiseLinkedGeoLoop determines whether a loop is clockwise.
+//
+// Return whether the loop is clockwise.
+func isClockwiseLinkedGeoLoop(loop *LinkedGeoLoop) bool {
+	fence := loop.Geofence()
+	return isClockwiseGeofence(&fence)
+}
+
+// bboxFromLinkedGeoLoop creates a bounding box from a loop.
+func bboxFromLinkedGeoLoop(loop *LinkedGeoLoop, bbox *BBox) {
+	fence := loop.Geofence()
+	bboxFromGeofence(&fence, bbox)
+}
+
+// pointInsideLinkedGeoLoop determines if the point is contained within the
+// loop, with its bounding box.
+//
+// Return whether the point is contained.
+func pointInsideLinkedGeoLoop(loop *LinkedGeoLoop, bbox *BBox, coord *GeoCoord) bool {
+	fence := loop.Geofence()
+	return pointInsideGeofence(&fence, bbox, coord)
+}
+
+// countContainers counts the number of polygons containing a given loop.
+//
+// Return the number of polygons containing the loop.
+func countContainers(loop *LinkedGeoLoop, polygons []*LinkedGeoPolygon, bboxes []*BBox) int {
+	containerCount := 0
+	for i, polygon := range polygons {
+		if loop != polygon.first &&
+			pointInsideLinkedGeoLoop(polygon.first, bboxes[i], &loop.first.vertex) {
+			containerCount++
+		}
+	}
+	return containerCount
+}
+
+// findDeepestContainer, given a list of nested containers, finds the one most
+// deeply nested.
+//
+// Return the deepest container, or nil if the list is empty.
+func findDeepestContainer(polygons []*LinkedGeoPolygon, bboxes []*BBox) *LinkedGeoPolygon {
+	// Set the initial return value to the first candidate
+	var parent *LinkedGeoPolygon
+	if len(polygons) > 0 {
+		parent = polygons[0]
+	}
+
+	// If we have multiple polygons, they must be nested inside each other.
+	// Find the innermost polygon by taking the one with the most containers
+	// in the list.
+	if len(polygons) > 1 {
+		max := -1
+		for i, polygon := range polygons {
+			count := countContainers(polygon.first, polygons, bboxes)
+			if count > max {
+				parent = polygons[i]
+				max = count
+			}
+		}
+	}
+
+	return parent
+}
+
+// findPolygonForHole finds the polygon to which a given hole should be
+// allocated. Note that this function will return nil if no parent is found.
+//
+// Return the parent polygon, or nil.
+func findPolygonForHole(loop *LinkedGeoLoop, polygon *LinkedGeoPolygon, bboxes []BBox, polygonCount int) *LinkedGeoPolygon {
+	// Early exit with no polygons
+	if polygonCount == 0 {
+		return nil
+	}
+
+	// Initialize arrays for candidate loops and their bounding boxes
+	candidates := make([]*LinkedGeoPolygon, 0, polygonCount)
+	candidateBBoxes := make([]*BBox, 0, polygonCount)
+
+	// Find all polygons that contain any point in the hole
+	for index := 0; polygon != nil; index++ {
+		// We are guaranteed not to overlap, so just test the first point
+		if pointInsideLinkedGeoLoop(polygon.first, &bboxes[index], &loop.first.vertex) {
+			candidates = append(candidates, polygon)
+			candidateBBoxes = append(candidateBBoxes, &bboxes[index])
+		}
+		polygon = polygon.next
+	}
+
+	// The most deeply nested container is the immediate parent
+	return findDeepestContainer(candidates, candidateBBoxes)
+}
+
+// normalizeMultiPolygon normalizes a LinkedGeoPolygon in-place into a
+// structure following GeoJSON MultiPolygon rules: each polygon must have
+// exactly one outer loop, which must be first in the list, followed by any
+// holes. Holes in this algorithm are identified by winding order (holes are
+// clockwise), which is guaranteed by the h3SetToVertexGraph algorithm.
+//
+// Input to this function is assumed to be a single polygon including all
+// loops to normalize. It's assumed that a valid arrangement is possible.
+//
+// Return NORMALIZATION_SUCCESS, or one of the NORMALIZATION_ERR codes.
+func normalizeMultiPolygon(root *LinkedGeoPolygon) int {
+	// We assume that the input is a single polygon with loops;
+	// if it has multiple polygons, don't touch it
+	if root.next != nil {
+		return NORMALIZATION_ERR_MULTIPLE_POLYGONS
+	}
+
+	// Count loops, exiting early if there's only one
+	loopCount := countLinkedLoops(root)
+	if loopCount <= 1 {
+		return NORMALIZATION_SUCCESS
+	}
+
+	resultCode := NORMALIZATION_SUCCESS
+
+	// Create an array to hold all of the inner loops, and one to hold the
+	// bounding boxes for the outer loops
+	innerLoops := make([]*LinkedGeoLoop, 0, loopCount)
+	bboxes := make([]BBox, 0, loopCount)
+
+	// Get the first loop and unlink it from root
+	loop := root.first
+	*root = LinkedGeoPolygon{}
+
+	// Iterate over all loops, moving inner loops into an array and
+	// assigning outer loops to new polygons
+	var polygon *LinkedGeoPolygon
+	for loop != nil {
+		if isClockwiseLinkedGeoLoop(loop) {
+			innerLoops = append(innerLoops, loop)
+		} else {
+			if polygon == nil {
+				polygon = root
+			} else {
+				polygon = addNewLinkedPolygon(polygon)
+			}
+			addLinkedLoop(polygon, loop)
+			var bbox BBox
+			bboxFromLinkedGeoLoop(loop, &bbox)
+			bboxes = append(bboxes, bbox)
+		}
+		// get the next loop and unlink it from this one
+		next := loop.next
+		loop.next = nil
+		loop = next
+	}
+
+	// Find polygon for each inner loop and assign the hole to it
+	for _, innerLoop := range innerLoops {
+		polygon = findPolygonForHole(innerLoop, root, bboxes, len(bboxes))
+		if polygon != nil {
+			addLinkedLoop(polygon, innerLoop)
+		} else {
+			// If we can't find a polygon (possible with invalid input), the
+			// hole is dropped
+			resultCode = NORMALIZATION_ERR_UNASSIGNED_HOLES
+		}
+	}
+
+	return resultCode
+}
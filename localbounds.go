@@ -0,0 +1,60 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import "fmt"
+
+// LocalIJBounds determines the bounding rectangle, in the local IJ
+// coordinates anchored at origin, of a set of cells. Together with
+// ExperimentalLocalIjToH3 this lets raster-style algorithms such as distance
+// transforms and scanline fills walk the set as a 2D grid. H3_NULL entries
+// are skipped, and an empty set yields two zero corners.
+//
+// Return the corners with the smallest and largest i and j, ErrInvalidCell
+// if origin or a cell is invalid, or ErrLocalIJFailed if a cell has no local
+// IJ coordinates relative to origin, for example because it is too far away
+// or on the other side of a pentagon.
+func LocalIJBounds(origin H3Index, cells []H3Index) (minIJ, maxIJ CoordIJ, err error) {
+	if !H3IsValid(origin) {
+		return CoordIJ{}, CoordIJ{}, fmt.Errorf("%w: %s", ErrInvalidCell, origin)
+	}
+
+	first := true
+	for _, cell := range cells {
+		if cell == H3_NULL {
+			continue
+		}
+		if !H3IsValid(cell) {
+			return CoordIJ{}, CoordIJ{}, fmt.Errorf("%w: %s", ErrInvalidCell, cell)
+		}
+
+		var ij CoordIJ
+		if ExperimentalH3ToLocalIj(origin, cell, &ij) != 0 {
+			return CoordIJ{}, CoordIJ{}, fmt.Errorf("%w: %s from origin %s",
+				ErrLocalIJFailed, cell, origin)
+		}
+
+		if first {
+			minIJ, maxIJ = ij, ij
+			first = false
+			continue
+		}
+		minIJ.i = min(minIJ.i, ij.i)
+		minIJ.j = min(minIJ.j, ij.j)
+		maxIJ.i = max(maxIJ.i, ij.i)
+		maxIJ.j = max(maxIJ.j, ij.j)
+	}
+	return minIJ, maxIJ, nil
+}
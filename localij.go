@@ -14,7 +14,10 @@
 
 package h3go
 
-import "math"
+import (
+	"fmt"
+	"math"
+)
 
 // Origin leading digit . index leading digit . rotations 60 cw
 // Either being 1 (K axis) is invalid.
@@ -68,6 +71,9 @@ var PENTAGON_ROTATIONS_REVERSE_POLAR = [7][7]int{
 	{0, 1, 1, 0, 1, 1, 1},        // 6
 }
 
+// Maximum depth to which H3Line splits a line it cannot draw in one piece.
+const MAX_LINE_SPLIT_DEPTH = 8
+
 // Prohibited directions when unfolding a pentagon.
 //
 // Indexes by two directions, both relative to the pentagon base cell. The first
@@ -103,6 +109,17 @@ var FAILED_DIRECTIONS = [7][7]bool{
 //
 // Return 0 on success, or another value on failure.
 func h3ToLocalIjk(origin H3Index, h3 H3Index, out *CoordIJK) int {
+	return _h3ToLocalIjk(origin, h3, out, true)
+}
+
+// _h3ToLocalIjk produces ijk+ coordinates for an index anchored by an origin,
+// like h3ToLocalIjk. If strict is false, the pentagon is also unfolded in the
+// FAILED_DIRECTIONS, across more than one icosahedron face. The coordinates
+// produced that way still identify the index uniquely and round trip through
+// localIjkToH3, but distances between them may not be grid distances.
+//
+// Return 0 on success, or another value on failure.
+func _h3ToLocalIjk(origin H3Index, h3 H3Index, out *CoordIJK, strict bool) int {
 	res := H3_GET_RESOLUTION(origin)
 
 	if res != H3_GET_RESOLUTION(h3) {
@@ -169,7 +186,7 @@ func h3ToLocalIjk(origin H3Index, h3 H3Index, out *CoordIJK) int {
 		if originOnPent {
 			originLeadingDigit := _h3LeadingNonZeroDigit(origin)
 
-			if FAILED_DIRECTIONS[originLeadingDigit][dir] {
+			if strict && FAILED_DIRECTIONS[originLeadingDigit][dir] {
 				// TODO: We may be unfolding the pentagon incorrectly in this
 				// case; return an error code until this is guaranteed to be
 				// correct.
@@ -181,7 +198,7 @@ func h3ToLocalIjk(origin H3Index, h3 H3Index, out *CoordIJK) int {
 		} else if indexOnPent {
 			indexLeadingDigit := _h3LeadingNonZeroDigit(h3)
 
-			if FAILED_DIRECTIONS[indexLeadingDigit][revDir] {
+			if strict && FAILED_DIRECTIONS[indexLeadingDigit][revDir] {
 				// TODO: We may be unfolding the pentagon incorrectly in this
 				// case; return an error code until this is guaranteed to be
 				// correct.
@@ -233,7 +250,7 @@ func h3ToLocalIjk(origin H3Index, h3 H3Index, out *CoordIJK) int {
 		originLeadingDigit := _h3LeadingNonZeroDigit(origin)
 		indexLeadingDigit := _h3LeadingNonZeroDigit(h3)
 
-		if FAILED_DIRECTIONS[originLeadingDigit][indexLeadingDigit] {
+		if strict && FAILED_DIRECTIONS[originLeadingDigit][indexLeadingDigit] {
 			// TODO: We may be unfolding the pentagon incorrectly in this case;
 			// return an error code until this is guaranteed to be correct.
 			return 5
@@ -331,10 +348,7 @@ func localIjkToH3(origin H3Index, ijk *CoordIJK, out *H3Index) int {
 	// If baseCell is invalid, it must be because the origin base cell is a
 	// pentagon, and because pentagon base cells do not border each other,
 	// baseCell must not be a pentagon.
-	indexOnPent := _isBaseCellPentagon(baseCell)
-	if baseCell == INVALID_BASE_CELL {
-		indexOnPent = false
-	}
+	indexOnPent := baseCell != INVALID_BASE_CELL && _isBaseCellPentagon(baseCell)
 
 	if dir != CENTER_DIGIT {
 		// If the index is in a warped direction, we need to unwarp the base
@@ -468,6 +482,38 @@ func ExperimentalH3ToLocalIj(origin H3Index, h3 H3Index, out *CoordIJ) int {
 	var ijk CoordIJK
 	failed := h3ToLocalIjk(origin, h3, &ijk)
 	if failed != 0 {
+		recordEvent(EventLocalIJFailure, 1)
+		return failed
+	}
+
+	ijkToIj(&ijk, out)
+
+	return 0
+}
+
+// ExperimentalH3ToLocalIjUnfolded produces ij coordinates for an index
+// anchored by an origin, like ExperimentalH3ToLocalIj, but also unfolds the
+// pentagon in the directions where ExperimentalH3ToLocalIj fails, across more
+// than one icosahedron face.
+//
+// The coordinates produced this way identify the index uniquely and round
+// trip through ExperimentalLocalIjToH3, but they are not consistent with the
+// grid: the ij distance between indexes on opposite sides of a pentagon may
+// not be their grid distance, which is why H3Distance and H3Line fail for
+// such indexes instead. Use these coordinates to label or store indexes
+// relative to an origin, not to measure between them.
+//
+// Failure may still occur if the index is too far away from the origin.
+//
+// This function is experimental, and its output is not guaranteed to be
+// compatible across different versions of H3.
+//
+// Return 0 on success, or another value on failure.
+func ExperimentalH3ToLocalIjUnfolded(origin H3Index, h3 H3Index, out *CoordIJ) int {
+	var ijk CoordIJK
+	failed := _h3ToLocalIjk(origin, h3, &ijk, false)
+	if failed != 0 {
+		recordEvent(EventLocalIJFailure, 1)
 		return failed
 	}
 
@@ -497,7 +543,11 @@ func ExperimentalLocalIjToH3(origin H3Index, ij *CoordIJ, out *H3Index) int {
 	var ijk CoordIJK
 	ijToIjk(ij, &ijk)
 
-	return localIjkToH3(origin, &ijk, out)
+	failed := localIjkToH3(origin, &ijk, out)
+	if failed != 0 {
+		recordEvent(EventLocalIJFailure, 1)
+	}
+	return failed
 }
 
 // H3Distance produces the grid distance between the two indexes.
@@ -516,12 +566,107 @@ func H3Distance(origin H3Index, h3 H3Index) int {
 		return -1 // LCOV_EXCL_LINE
 	}
 	if h3ToLocalIjk(origin, h3, &h3Ijk) != 0 {
+		recordEvent(EventLocalIJFailure, 1)
 		return -1
 	}
 
 	return ijkDistance(&originIjk, &h3Ijk)
 }
 
+// DistanceEstimate produces the grid distance between two cells like
+// H3Distance, and when that fails estimates it from the great circle between
+// the cell centers instead. The length of the arc is divided by the spacing
+// of neighboring cell centers, averaged around both cells, and scaled up by
+// as much as 2/sqrt(3) for the zigzag a grid path needs when the arc leaves
+// the origin between two grid axes. The estimate follows the local cell size
+// and orientation at the ends, but not the detours a grid path takes around
+// pentagons or the way cell size and axes change along the way. It is
+// typically within a few percent of the true distance, but can be off by
+// more than ten percent over long distances.
+//
+// Return the distance and whether it is an estimate, or a negative distance
+// if either cell is not a valid cell or they are at different resolutions.
+func DistanceEstimate(origin H3Index, h3 H3Index) (distance int, estimated bool) {
+	if !H3IsValid(origin) || !H3IsValid(h3) ||
+		H3_GET_RESOLUTION(origin) != H3_GET_RESOLUTION(h3) {
+		return -1, false
+	}
+	if d := H3Distance(origin, h3); d >= 0 {
+		return d, false
+	}
+
+	var a, b GeoCoord
+	H3ToGeo(origin, &a)
+	H3ToGeo(h3, &b)
+	originSpacing, axisAz := neighborSpacingRads(origin)
+	h3Spacing, _ := neighborSpacingRads(h3)
+	steps := arcLengthRads(&a, &b) / ((originSpacing + h3Spacing) / 2)
+
+	// angle of the arc from the nearest grid axis at the origin
+	theta := math.Mod(_posAngleRads(_geoAzimuthRads(&a, &b)-axisAz), M_PI/3)
+	steps *= math.Cos(theta) + math.Sin(theta)/(2*M_SQRT3_2)
+	return int(math.Round(steps)), true
+}
+
+// neighborSpacingRads finds the mean great circle distance in radians from
+// the center of cell to the centers of its neighbors, and the azimuth from
+// the center of cell to one of them, which lies along a grid axis.
+func neighborSpacingRads(cell H3Index) (spacing, axisAz float64) {
+	var center, neighborCenter GeoCoord
+	H3ToGeo(cell, &center)
+
+	neighbors := appendNeighbors(make([]H3Index, 0, 6), cell)
+	for i, neighbor := range neighbors {
+		H3ToGeo(neighbor, &neighborCenter)
+		spacing += arcLengthRads(&center, &neighborCenter)
+		if i == 0 {
+			axisAz = _geoAzimuthRads(&center, &neighborCenter)
+		}
+	}
+	return spacing / float64(len(neighbors)), axisAz
+}
+
+// GridDistance produces the grid distance between two cells. It uses the
+// local IJ coordinates where they can be unfolded, and otherwise measures the
+// distance exactly with a breadth-first search over the grid from origin,
+// up to maxSearchK steps. Unlike H3Distance, which fails for cells on
+// opposite sides of a pentagon or too far apart for the local coordinates,
+// the search finds the distance whenever it is at most maxSearchK; its cost
+// grows with the square of the distance. A maxSearchK of 0 or less disables
+// the search.
+//
+// Return the distance, ErrInvalidCell if either cell is not a valid cell,
+// ErrResolutionMismatch if they are at different resolutions, or
+// ErrLocalIJFailed if neither the local coordinates nor the search within
+// maxSearchK steps found the distance.
+func GridDistance(origin H3Index, destination H3Index, maxSearchK int) (int, error) {
+	for _, cell := range [2]H3Index{origin, destination} {
+		if !H3IsValid(cell) {
+			return -1, fmt.Errorf("%w: %s", ErrInvalidCell, cell)
+		}
+	}
+	if H3_GET_RESOLUTION(origin) != H3_GET_RESOLUTION(destination) {
+		return -1, fmt.Errorf("%w: %d and %d", ErrResolutionMismatch,
+			H3_GET_RESOLUTION(origin), H3_GET_RESOLUTION(destination))
+	}
+
+	var originIjk, destinationIjk CoordIJK
+	if h3ToLocalIjk(origin, origin, &originIjk) == 0 &&
+		h3ToLocalIjk(origin, destination, &destinationIjk) == 0 {
+		return ijkDistance(&originIjk, &destinationIjk), nil
+	}
+	recordEvent(EventLocalIJFailure, 1)
+
+	if maxSearchK > 0 {
+		recordEvent(EventPentagonFallback, 1)
+		if d := gridDistanceSearch(origin, destination, maxSearchK); d >= 0 {
+			return d, nil
+		}
+	}
+	return -1, fmt.Errorf("%w: from %s to %s within %d steps",
+		ErrLocalIJFailed, origin, destination, maxSearchK)
+}
+
 // H3LineSize is number of indexes in a line from the start index to the end
 // index, to be used for allocating memory. Returns a negative number if the
 // line cannot be computed.
@@ -535,6 +680,28 @@ func H3LineSize(start H3Index, end H3Index) int {
 	return distance
 }
 
+// GridPathCellsSize returns the number of cells in the line GridPathCells
+// draws from start to end, for allocating memory or enforcing a budget before
+// the line is stored. The size is exact, also for lines split around a
+// pentagon; finding it takes as long as drawing the line, but none of the
+// line is kept.
+//
+// Return the size, ErrInvalidCell if start or end is not a valid cell,
+// ErrResolutionMismatch if they are at different resolutions, or
+// ErrGridPathFailed if GridPathCells could not draw the line, matching
+// ErrLocalIJFailed or ErrPentagonDistortion as its error does.
+func GridPathCellsSize(start H3Index, end H3Index) (int, error) {
+	if err := validateGridPath(start, end); err != nil {
+		return 0, err
+	}
+
+	size, ok := h3LineSplitSize(start, end, MAX_LINE_SPLIT_DEPTH)
+	if !ok {
+		return 0, gridPathError(start, end)
+	}
+	return size, nil
+}
+
 // cubeRound round to valid integer coordinates with given cube coords as doubles.
 // Algorithm from https://www.redblobgames.com/grids/hexagons/#rounding.
 func cubeRound(i float64, j float64, k float64, ijk *CoordIJK) {
@@ -560,24 +727,193 @@ func cubeRound(i float64, j float64, k float64, ijk *CoordIJK) {
 	ijk.k = int(rk)
 }
 
-// H3Line return the line of indexes between them (inclusive) with given two H3
-// indexes.
+// GridPathCells returns the line of indexes between start and end
+// (inclusive).
 //
-// This function may fail to find the line between two indexes, for
-// example if they are very far apart. It may also fail when finding
-// distances for indexes on opposite sides of a pentagon.
+// When the line cannot be drawn in local coordinates, for example because
+// the indexes are very far apart or on opposite sides of a pentagon, the
+// segment is split at the cell containing the geographic midpoint of the two
+// cell centers and each half is drawn the same way, up to
+// MAX_LINE_SPLIT_DEPTH times, before the halves are joined.
 //
 // Notes:
 //
 //  - The specific output of this function should not be considered stable
 //    across library versions. The only guarantees the library provides are
-//    that the line length will be `h3Distance(start, end) + 1` and that
-//    every index in the line will be a neighbor of the preceding index.
+//    that every index in the line will be a neighbor of the preceding index,
+//    and that a line drawn without splitting has length
+//    `H3LineSize(start, end)`. A joined line may be longer;
+//    GridPathCellsSize gives the length either way.
 //  - Lines are drawn in grid space, and may not correspond exactly to either
 //    Cartesian lines or great arcs.
 //
+// Return the line, ErrInvalidCell if start or end is not a valid cell,
+// ErrResolutionMismatch if they are at different resolutions, or
+// ErrGridPathFailed if the line could not be drawn even after splitting. The
+// latter also matches ErrLocalIJFailed if the indexes are too far apart for
+// local coordinates, or ErrPentagonDistortion if a pentagon got in the way.
+func GridPathCells(start H3Index, end H3Index) ([]H3Index, error) {
+	if err := validateGridPath(start, end); err != nil {
+		return nil, err
+	}
+
+	line, ok := h3LineSplit(start, end, MAX_LINE_SPLIT_DEPTH)
+	if !ok {
+		return nil, gridPathError(start, end)
+	}
+	return line, nil
+}
+
+// validateGridPath checks the ends of a line for GridPathCells.
+//
+// Return ErrInvalidCell if start or end is not a valid cell, or
+// ErrResolutionMismatch if they are at different resolutions.
+func validateGridPath(start H3Index, end H3Index) error {
+	for _, cell := range [2]H3Index{start, end} {
+		if !H3IsValid(cell) {
+			return fmt.Errorf("%w: %s", ErrInvalidCell, cell)
+		}
+	}
+	if H3_GET_RESOLUTION(start) != H3_GET_RESOLUTION(end) {
+		return fmt.Errorf("%w: %d and %d", ErrResolutionMismatch,
+			H3_GET_RESOLUTION(start), H3_GET_RESOLUTION(end))
+	}
+	return nil
+}
+
+// gridPathError returns the error for a line GridPathCells could not draw,
+// telling apart indexes too far apart for the local coordinates from lines
+// broken by a pentagon.
+func gridPathError(start H3Index, end H3Index) error {
+	cause := ErrPentagonDistortion
+	var ijk CoordIJK
+	if failed := h3ToLocalIjk(start, end, &ijk); failed == 1 || failed == 2 {
+		cause = ErrLocalIJFailed
+	}
+	return fmt.Errorf("%w: from %s to %s: %w", ErrGridPathFailed, start, end, cause)
+}
+
+// H3Line return the line of indexes between them (inclusive) with given two H3
+// indexes, as GridPathCells does. *out is resized to the length of the line.
+//
 // Return 0 on success, or another value on failure.
+//
+// Deprecated: Use GridPathCells instead.
 func H3Line(start H3Index, end H3Index, out *[]H3Index) int {
+	line, err := GridPathCells(start, end)
+	if err != nil {
+		return -1
+	}
+	*out = append((*out)[:0], line...)
+	return 0
+}
+
+// h3LineSplit draws the line of indexes between start and end (inclusive),
+// splitting it at the midpoint cell whenever it cannot be drawn in local
+// coordinates, at most depth times deep.
+//
+// Return the line, and whether it could be drawn.
+func h3LineSplit(start H3Index, end H3Index, depth int) ([]H3Index, bool) {
+	if size := H3LineSize(start, end); size > 0 {
+		line := make([]H3Index, 0, size)
+		if h3LineLocal(start, end, func(h H3Index) bool {
+			line = append(line, h)
+			return true
+		}) == 0 && isContiguousLine(line) {
+			return line, true
+		}
+	}
+	if depth == 0 {
+		return nil, false
+	}
+
+	midCell, ok := h3LineMidpoint(start, end)
+	if !ok {
+		return nil, false
+	}
+	recordEvent(EventLineSplit, 1)
+
+	first, ok := h3LineSplit(start, midCell, depth-1)
+	if !ok {
+		return nil, false
+	}
+	second, ok := h3LineSplit(midCell, end, depth-1)
+	if !ok {
+		return nil, false
+	}
+	return append(first, second[1:]...), true
+}
+
+// h3LineSplitSize counts the indexes of the line h3LineSplit draws between
+// start and end, splitting it the same way but without storing it.
+//
+// Return the number of indexes, and whether the line could be drawn.
+func h3LineSplitSize(start H3Index, end H3Index, depth int) (int, bool) {
+	if H3LineSize(start, end) > 0 {
+		size, prev := 0, H3_NULL
+		if h3LineLocal(start, end, func(h H3Index) bool {
+			if size > 0 && !H3IndexesAreNeighbors(prev, h) {
+				return false
+			}
+			size, prev = size+1, h
+			return true
+		}) == 0 {
+			return size, true
+		}
+	}
+	if depth == 0 {
+		return 0, false
+	}
+
+	midCell, ok := h3LineMidpoint(start, end)
+	if !ok {
+		return 0, false
+	}
+
+	first, ok := h3LineSplitSize(start, midCell, depth-1)
+	if !ok {
+		return 0, false
+	}
+	second, ok := h3LineSplitSize(midCell, end, depth-1)
+	if !ok {
+		return 0, false
+	}
+	return first + second - 1, true
+}
+
+// h3LineMidpoint finds the cell at which h3LineSplit splits the line between
+// start and end, the one containing the geographic midpoint of their
+// centers.
+//
+// Return the cell, and whether it is neither start nor end, as otherwise the
+// split makes no progress.
+func h3LineMidpoint(start H3Index, end H3Index) (H3Index, bool) {
+	var a, b, mid GeoCoord
+	H3ToGeo(start, &a)
+	H3ToGeo(end, &b)
+	_geoAzDistanceRads(&a, _geoAzimuthRads(&a, &b), arcLengthRads(&a, &b)/2, &mid)
+	midCell := GeoToH3(&mid, H3_GET_RESOLUTION(start))
+	return midCell, midCell != start && midCell != end
+}
+
+// isContiguousLine reports whether every index of line is a neighbor of the
+// preceding one. Lines drawn in local coordinates near a pentagon can skip
+// over the deleted subsequence.
+func isContiguousLine(line []H3Index) bool {
+	for i := 1; i < len(line); i++ {
+		if !H3IndexesAreNeighbors(line[i-1], line[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// h3LineLocal draws the line of indexes between start and end (inclusive) in
+// local coordinates anchored at start, passing each index in order to visit,
+// which returns whether to go on.
+//
+// Return 0 on success, or another value on failure or if visit stopped.
+func h3LineLocal(start H3Index, end H3Index, visit func(H3Index) bool) int {
 	distance := H3Distance(start, end)
 	// Early exit if we can't calculate the line
 	if distance < 0 {
@@ -617,7 +953,13 @@ func H3Line(start H3Index, end H3Index, out *[]H3Index) int {
 			float64(startIjk.k)+kStep*float64(n), &currentIjk)
 		// Convert cube -> ijk -> h3 index
 		cubeToIjk(&currentIjk)
-		localIjkToH3(start, &currentIjk, &(*out)[n])
+		var h H3Index
+		if failed := localIjkToH3(start, &currentIjk, &h); failed != 0 {
+			return failed
+		}
+		if !visit(h) {
+			return -1
+		}
 	}
 
 	return 0
@@ -0,0 +1,206 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestH3LeadingNonZeroDigit(t *testing.T) {
+	for _, digit := range []Direction{K_AXES_DIGIT, J_AXES_DIGIT, IJ_AXES_DIGIT} {
+		var h H3Index
+		setH3Index(&h, 3, 10, CENTER_DIGIT)
+		H3_SET_INDEX_DIGIT(&h, 2, digit)
+		H3_SET_INDEX_DIGIT(&h, 3, I_AXES_DIGIT)
+		if got := _h3LeadingNonZeroDigit(h); got != digit {
+			t.Errorf("_h3LeadingNonZeroDigit(%s) = %d, want %d", h, got, digit)
+		}
+	}
+
+	var center H3Index
+	setH3Index(&center, 3, 10, CENTER_DIGIT)
+	if got := _h3LeadingNonZeroDigit(center); got != CENTER_DIGIT {
+		t.Errorf("_h3LeadingNonZeroDigit(%s) = %d, want CENTER_DIGIT", center, got)
+	}
+}
+
+func TestLocalIjRoundTrip(t *testing.T) {
+	var children []H3Index
+	H3ToChildren(H3Index(0x85283473fffffff), 7, &children)
+	origin := children[0]
+	for _, h := range children {
+		var ij CoordIJ
+		if err := ExperimentalH3ToLocalIj(origin, h, &ij); err != 0 {
+			t.Fatalf("ExperimentalH3ToLocalIj(%s, %s) failed with %d", origin, h, err)
+		}
+		var got H3Index
+		if err := ExperimentalLocalIjToH3(origin, &ij, &got); err != 0 {
+			t.Fatalf("ExperimentalLocalIjToH3(%s, %v) failed with %d", origin, ij, err)
+		}
+		if got != h {
+			t.Errorf("round trip of %s gave %s", h, got)
+		}
+	}
+}
+
+func TestLocalIjToH3PentagonDeletedDirection(t *testing.T) {
+	// Coordinates around a pentagon base cell reach into its deleted
+	// direction, which has no base cell; they must fail rather than panic.
+	for bc := 0; bc < NUM_BASE_CELLS; bc++ {
+		if !_isBaseCellPentagon(bc) {
+			continue
+		}
+		var origin H3Index
+		setH3Index(&origin, 2, bc, CENTER_DIGIT)
+		failed := 0
+		for i := -8; i <= 8; i++ {
+			for j := -8; j <= 8; j++ {
+				var out H3Index
+				if ExperimentalLocalIjToH3(origin, &CoordIJ{i, j}, &out) != 0 {
+					failed++
+				}
+			}
+		}
+		if failed == 0 {
+			t.Errorf("no coordinates around pentagon %s failed", origin)
+		}
+	}
+}
+
+func TestLocalIjAcrossPentagon(t *testing.T) {
+	// Around each pentagon, the coordinates unfolded across more than one
+	// icosahedron face must still round trip and be unique, while
+	// ExperimentalH3ToLocalIj and H3Distance keep refusing them.
+	pentagons := make([]H3Index, PentagonIndexCount())
+	GetPentagonIndexes(2, &pentagons)
+	unfolded := 0
+	for _, pentagon := range pentagons {
+		for _, origin := range kRing(pentagon, 2) {
+			if origin == H3_NULL {
+				continue
+			}
+			seen := make(map[CoordIJ]H3Index)
+			for _, h := range kRing(pentagon, 4) {
+				if h == H3_NULL {
+					continue
+				}
+				var ij CoordIJ
+				if ExperimentalH3ToLocalIjUnfolded(origin, h, &ij) != 0 {
+					continue
+				}
+				if other, ok := seen[ij]; ok {
+					t.Fatalf("%s and %s both at %v from %s", other, h, ij, origin)
+				}
+				seen[ij] = h
+
+				var got H3Index
+				if failed := ExperimentalLocalIjToH3(origin, &ij, &got); failed != 0 || got != h {
+					t.Fatalf("round trip of %s from %s gave %s, %d", h, origin, got, failed)
+				}
+
+				var strict CoordIJ
+				if ExperimentalH3ToLocalIj(origin, h, &strict) == 0 {
+					if strict != ij {
+						t.Fatalf("%s from %s is at %v strictly, %v unfolded", h, origin, strict, ij)
+					}
+					continue
+				}
+				unfolded++
+				if d := H3Distance(origin, h); d >= 0 {
+					t.Errorf("H3Distance(%s, %s) = %d, want failure", origin, h, d)
+				}
+			}
+		}
+	}
+	if unfolded == 0 {
+		t.Error("no index was unfolded across a pentagon")
+	}
+}
+
+func TestGridDistance(t *testing.T) {
+	pentagons := make([]H3Index, PentagonIndexCount())
+	GetPentagonIndexes(3, &pentagons)
+	searched := 0
+	for _, pentagon := range pentagons {
+		origin := kRing(pentagon, 1)[1]
+		cells, distances := kRingDistances(origin, 4)
+		for i, h := range cells {
+			if h == H3_NULL {
+				continue
+			}
+			got, err := GridDistance(origin, h, 4)
+			if err != nil || got != distances[i] {
+				t.Errorf("GridDistance(%s, %s, 4) = %d, %v, want %d",
+					origin, h, got, err, distances[i])
+			}
+			if H3Distance(origin, h) >= 0 {
+				continue
+			}
+			searched++
+			if _, err := GridDistance(origin, h, 0); !errors.Is(err, ErrLocalIJFailed) {
+				t.Errorf("GridDistance(%s, %s, 0) error = %v, want ErrLocalIJFailed",
+					origin, h, err)
+			}
+		}
+	}
+	if searched == 0 {
+		t.Error("H3Distance did not fail for any cell around a pentagon")
+	}
+
+	var origin H3Index = 0x85283473fffffff
+	if _, err := GridDistance(origin, H3_NULL, 4); !errors.Is(err, ErrInvalidCell) {
+		t.Errorf("GridDistance with an invalid cell error = %v, want ErrInvalidCell", err)
+	}
+	if _, err := GridDistance(origin, H3ToParent(origin, 4), 4); !errors.Is(err, ErrResolutionMismatch) {
+		t.Errorf("GridDistance across resolutions error = %v, want ErrResolutionMismatch", err)
+	}
+}
+
+func TestGridPathCellsSize(t *testing.T) {
+	// Lines between cells on opposite sides of a pentagon are split around
+	// it; the size must still match the line drawn.
+	pentagons := make([]H3Index, PentagonIndexCount())
+	GetPentagonIndexes(3, &pentagons)
+	split := 0
+	for _, pentagon := range pentagons {
+		ring, err := GridRing(pentagon, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, start := range ring {
+			end := ring[(i+len(ring)/2)%len(ring)]
+			line, lineErr := GridPathCells(start, end)
+			size, err := GridPathCellsSize(start, end)
+			if lineErr != nil {
+				if !errors.Is(err, ErrGridPathFailed) {
+					t.Errorf("GridPathCellsSize(%s, %s) error = %v, want ErrGridPathFailed",
+						start, end, err)
+				}
+				continue
+			}
+			if err != nil || size != len(line) {
+				t.Errorf("GridPathCellsSize(%s, %s) = %d, %v, want %d",
+					start, end, size, err, len(line))
+			}
+			if size != H3LineSize(start, end) {
+				split++
+			}
+		}
+	}
+	if split == 0 {
+		t.Error("no line was split around a pentagon")
+	}
+}
@@ -14,6 +14,8 @@
 
 package h3go
 
+import "math"
+
 func abs(x int) int {
 	if x < 0 {
 		return -x
@@ -43,3 +45,8 @@ func _ipow(base, exp int) int {
 
 	return result
 }
+
+// isFinite reports whether f is neither infinite nor NaN, as isfinite.
+func isFinite(f float64) bool {
+	return !math.IsInf(f, 0) && !math.IsNaN(f)
+}
@@ -0,0 +1,158 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// CellRecord is a cell with arbitrary per-cell properties. It is the unit of
+// the newline-delimited JSON interchange format, where every line is an object
+// of the form {"cell":"8a2a1072b59ffff","props":{...}}.
+type CellRecord struct {
+	Cell  H3Index
+	Props map[string]any
+}
+
+// ndjsonLine is the wire representation of a CellRecord.
+type ndjsonLine struct {
+	Cell  string         `json:"cell"`
+	Props map[string]any `json:"props"`
+}
+
+// NDJSONWriter writes CellRecords as newline-delimited JSON.
+type NDJSONWriter struct {
+	w   *bufio.Writer
+	buf bytes.Buffer
+	enc *json.Encoder
+}
+
+// NewNDJSONWriter returns a writer that writes records to w. Output is
+// buffered; call Flush once all records are written.
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	nw := &NDJSONWriter{w: bufio.NewWriter(w)}
+	nw.enc = json.NewEncoder(&nw.buf)
+	nw.enc.SetEscapeHTML(false)
+	return nw
+}
+
+// Write writes a single record as one line.
+//
+// Return ErrInvalidCell if the record cell is not a valid cell, or an error
+// if the properties cannot be encoded.
+func (w *NDJSONWriter) Write(rec CellRecord) error {
+	if !rec.Cell.IsValid() {
+		return fmt.Errorf("%w: %s", ErrInvalidCell, rec.Cell)
+	}
+
+	props := rec.Props
+	if props == nil {
+		props = map[string]any{}
+	}
+
+	// encode into the scratch buffer first so that a failed encoding never
+	// leaves a partial line in the output
+	w.buf.Reset()
+	if err := w.enc.Encode(ndjsonLine{Cell: rec.Cell.String(), Props: props}); err != nil {
+		return err
+	}
+	_, err := w.w.Write(w.buf.Bytes())
+	return err
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (w *NDJSONWriter) Flush() error {
+	return w.w.Flush()
+}
+
+// WriteNDJSON writes all records yielded by seq to w and flushes the output.
+func WriteNDJSON(w io.Writer, seq iter.Seq[CellRecord]) error {
+	nw := NewNDJSONWriter(w)
+	for rec := range seq {
+		if err := nw.Write(rec); err != nil {
+			return err
+		}
+	}
+	return nw.Flush()
+}
+
+// NDJSONReader reads CellRecords from newline-delimited JSON. Blank lines are
+// skipped.
+type NDJSONReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+// NewNDJSONReader returns a reader that reads records from r.
+func NewNDJSONReader(r io.Reader) *NDJSONReader {
+	return &NDJSONReader{r: bufio.NewReader(r)}
+}
+
+// Read reads the next record.
+//
+// Return io.EOF when there are no more records. Malformed lines and invalid
+// cells are reported with their line number; the reader may continue past
+// them.
+func (r *NDJSONReader) Read() (CellRecord, error) {
+	for {
+		data, err := r.r.ReadBytes('\n')
+		if len(data) == 0 && err != nil {
+			return CellRecord{}, err
+		}
+		r.line++
+
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			if err != nil {
+				return CellRecord{}, err
+			}
+			continue
+		}
+
+		var l ndjsonLine
+		if err := json.Unmarshal(data, &l); err != nil {
+			return CellRecord{}, fmt.Errorf("ndjson line %d: %w", r.line, err)
+		}
+
+		cell := StringToH3(l.Cell)
+		if !cell.IsValid() {
+			return CellRecord{}, fmt.Errorf("ndjson line %d: %w: %q", r.line, ErrInvalidCell, l.Cell)
+		}
+
+		return CellRecord{Cell: cell, Props: l.Props}, nil
+	}
+}
+
+// All returns an iterator over the remaining records. Iteration stops after
+// the first error, which is yielded with a zero record; io.EOF is not
+// reported.
+func (r *NDJSONReader) All() iter.Seq2[CellRecord, error] {
+	return func(yield func(CellRecord, error) bool) {
+		for {
+			rec, err := r.Read()
+			if err == io.EOF {
+				return
+			}
+			if !yield(rec, err) || err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"context"
+	"fmt"
+)
+
+// Option configures PolygonToCells and Compact. New settings can be added as
+// options without changing the signatures of the functions taking them.
+type Option func(*options)
+
+// options holds the settings made by Options. The zero value is the
+// behavior of the functions taking no options.
+type options struct {
+	ctx      context.Context
+	mode     ContainmentMode
+	maxCells int
+	workers  int
+	parallel bool
+}
+
+// WithContainmentMode selects which cells a polygon is filled with, see
+// PolygonToCellsWithMode. The default is CONTAINMENT_CENTER. Compact
+// ignores it.
+func WithContainmentMode(mode ContainmentMode) Option {
+	return func(o *options) { o.mode = mode }
+}
+
+// WithMaxCells limits the result to maxCells cells, failing with
+// ErrBudgetExceeded if there are more. The limit is checked once the cells
+// are found, so it bounds the memory held by the caller rather than the work
+// done. A limit that is not positive, the default, is no limit.
+func WithMaxCells(maxCells int) Option {
+	return func(o *options) { o.maxCells = maxCells }
+}
+
+// WithParallelism fills polygons on a pool of workers goroutines, or
+// GOMAXPROCS of them if workers is not positive, see PolygonToCellsParallel.
+// By default polygons are filled on the calling goroutine. Compact ignores
+// it.
+func WithParallelism(workers int) Option {
+	return func(o *options) { o.workers, o.parallel = workers, true }
+}
+
+// WithContext lets ctx cancel the work, which then fails with ctx.Err().
+// Polygons are then filled as by PolygonToCellsParallel, with a single
+// worker unless WithParallelism asks for more, and Compact checks ctx
+// between its passes.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) { o.ctx = ctx }
+}
+
+// newOptions applies opts to the default settings.
+func newOptions(opts []Option) options {
+	o := options{mode: CONTAINMENT_CENTER}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// context returns the context set by WithContext, or the background
+// context.
+func (o *options) context() context.Context {
+	if o.ctx == nil {
+		return context.Background()
+	}
+	return o.ctx
+}
+
+// polygonFill returns the function finding the cells of a polygon by
+// center, on the calling goroutine unless WithContext or WithParallelism
+// were given.
+func (o *options) polygonFill() func(GeoPolygon, int) ([]H3Index, error) {
+	if o.ctx == nil && !o.parallel {
+		return polygonToCells
+	}
+	ctx, workers := o.context(), 1
+	if o.parallel {
+		workers = o.workers
+	}
+	return func(poly GeoPolygon, res int) ([]H3Index, error) {
+		return polygonToCellsParallel(ctx, poly, res, workers)
+	}
+}
+
+// checkMaxCells holds cells to the limit set by WithMaxCells.
+//
+// Return cells, or ErrBudgetExceeded if there are more than allowed.
+func (o *options) checkMaxCells(cells []H3Index) ([]H3Index, error) {
+	if o.maxCells > 0 && len(cells) > o.maxCells {
+		return nil, fmt.Errorf("%w: %d cells for %d", ErrBudgetExceeded, len(cells), o.maxCells)
+	}
+	return cells, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestPolygonToCellsOptions(t *testing.T) {
+	origin := NewGeoCoordDegs(37.7749, -122.4194)
+	poly := boxAround(GeoToH3(&origin, 4), 1)
+
+	want, err := PolygonToCells(poly, 7)
+	if err != nil || len(want) == 0 {
+		t.Fatalf("PolygonToCells = %d cells, %v", len(want), err)
+	}
+	for name, opts := range map[string][]Option{
+		"parallel":   {WithParallelism(4)},
+		"context":    {WithContext(context.Background())},
+		"both":       {WithContext(context.Background()), WithParallelism(0)},
+		"max cells":  {WithMaxCells(len(want))},
+		"mode":       {WithContainmentMode(CONTAINMENT_CENTER)},
+		"no options": nil,
+	} {
+		if got, err := PolygonToCells(poly, 7, opts...); err != nil || !slices.Equal(got, want) {
+			t.Errorf("PolygonToCells with %s = %d cells, %v, want %d", name, len(got), err, len(want))
+		}
+	}
+
+	overlapping, err := PolygonToCellsWithMode(poly, 7, CONTAINMENT_OVERLAPPING)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := PolygonToCells(poly, 7, WithContainmentMode(CONTAINMENT_OVERLAPPING), WithParallelism(2))
+	if err != nil || !slices.Equal(got, overlapping) {
+		t.Errorf("PolygonToCells overlapping in parallel = %d cells, %v, want %d",
+			len(got), err, len(overlapping))
+	}
+
+	if _, err := PolygonToCells(poly, 7, WithMaxCells(len(want)-1)); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("PolygonToCells over budget error = %v, want ErrBudgetExceeded", err)
+	}
+	if _, err := PolygonToCells(poly, 7, WithContainmentMode(-1)); !errors.Is(err, ErrInvalidContainment) {
+		t.Errorf("PolygonToCells with mode -1 error = %v, want ErrInvalidContainment", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := PolygonToCells(poly, 7, WithContext(ctx)); !errors.Is(err, context.Canceled) {
+		t.Errorf("PolygonToCells with a canceled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestCompactOptions(t *testing.T) {
+	var children []H3Index
+	H3ToChildren(H3Index(0x85283473fffffff), 7, &children)
+	set := children[1:] // one short of the whole parent
+
+	want, err := Compact(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Compact(set, WithMaxCells(len(want))); err != nil || !slices.Equal(got, want) {
+		t.Errorf("Compact within budget = %v, %v, want %v", got, err, want)
+	}
+	if _, err := Compact(set, WithMaxCells(len(want)-1)); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("Compact over budget error = %v, want ErrBudgetExceeded", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Compact(set, WithContext(ctx)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Compact with a canceled context error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// CellCostFunc gives the cost of entering a cell. Costs must not be negative
+// or NaN; a cost of +Inf makes the cell impassable.
+type CellCostFunc func(cell H3Index) float64
+
+// ShortestPath finds the cheapest path of neighboring cells from start to
+// end that only passes through cells of allowed, where the cost of a path is
+// the sum of the costs of the cells it enters. A nil cost gives every cell a
+// cost of 1, so the path found is one with the fewest steps.
+//
+// The search is A*, guided by the grid distance to end in local IJ
+// coordinates times the smallest cost of any allowed cell. The costs of all
+// allowed cells are evaluated once up front to find that bound.
+//
+// Return the path from start to end inclusive and its cost, ErrInvalidCell if
+// start or end is not a valid cell, ErrResolutionMismatch if they are at
+// different resolutions, ErrInvalidCost if cost returned a negative or NaN
+// value, or ErrNoPath if start or end is not in allowed or end cannot be
+// reached from start.
+func ShortestPath(start, end H3Index, allowed CellSet, cost CellCostFunc) ([]H3Index, float64, error) {
+	for _, cell := range [2]H3Index{start, end} {
+		if !H3IsValid(cell) {
+			return nil, 0, fmt.Errorf("%w: %s", ErrInvalidCell, cell)
+		}
+		if !allowed.Has(cell) {
+			return nil, 0, fmt.Errorf("%w: %s is not allowed", ErrNoPath, cell)
+		}
+	}
+	if H3_GET_RESOLUTION(start) != H3_GET_RESOLUTION(end) {
+		return nil, 0, fmt.Errorf("%w: %d and %d", ErrResolutionMismatch,
+			H3_GET_RESOLUTION(start), H3_GET_RESOLUTION(end))
+	}
+	if start == end {
+		return []H3Index{start}, 0, nil
+	}
+
+	costs, minCost, err := evaluateCosts(allowed, cost)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Lower bound of the cost from cell to end. Where the local coordinates
+	// cannot be unfolded the bound is 0, which is still admissible.
+	var endIjk CoordIJK
+	h3ToLocalIjk(end, end, &endIjk)
+	remaining := func(cell H3Index) float64 {
+		var ijk CoordIJK
+		if h3ToLocalIjk(end, cell, &ijk) != 0 {
+			return 0
+		}
+		return float64(ijkDistance(&endIjk, &ijk)) * minCost
+	}
+
+	best := map[H3Index]float64{start: 0}
+	parent := make(map[H3Index]H3Index)
+	queue := cellQueue{{cell: start, cost: 0, priority: remaining(start)}}
+	var neighbors []H3Index
+	for queue.Len() > 0 {
+		item := heap.Pop(&queue).(cellQueueItem)
+		if item.cost > best[item.cell] {
+			// superseded by a cheaper way to the same cell
+			continue
+		}
+		if item.cell == end {
+			path := []H3Index{end}
+			for cell := end; cell != start; {
+				cell = parent[cell]
+				path = append(path, cell)
+			}
+			slices.Reverse(path)
+			return path, item.cost, nil
+		}
+
+		neighbors = appendNeighbors(neighbors[:0], item.cell)
+		for _, neighbor := range neighbors {
+			c, ok := costs[neighbor]
+			if !ok || math.IsInf(c, 1) {
+				continue
+			}
+			total := item.cost + c
+			if prev, seen := best[neighbor]; seen && prev <= total {
+				continue
+			}
+			best[neighbor] = total
+			parent[neighbor] = item.cell
+			heap.Push(&queue, cellQueueItem{
+				cell:     neighbor,
+				cost:     total,
+				priority: total + remaining(neighbor),
+			})
+		}
+	}
+
+	return nil, 0, fmt.Errorf("%w: from %s to %s", ErrNoPath, start, end)
+}
+
+// evaluateCosts finds the cost of every cell of cells, with a nil cost
+// giving every cell a cost of 1.
+//
+// Return the costs, the smallest finite cost (0 if there is none), or
+// ErrInvalidCost if cost returned a negative or NaN value.
+func evaluateCosts(cells CellSet, cost CellCostFunc) (map[H3Index]float64, float64, error) {
+	costs := make(map[H3Index]float64, len(cells))
+	minCost := math.Inf(1)
+	for cell := range cells {
+		c := 1.0
+		if cost != nil {
+			c = cost(cell)
+		}
+		if c < 0 || math.IsNaN(c) {
+			return nil, 0, fmt.Errorf("%w: %v for %s", ErrInvalidCost, c, cell)
+		}
+		costs[cell] = c
+		minCost = math.Min(minCost, c)
+	}
+	if math.IsInf(minCost, 1) {
+		minCost = 0
+	}
+	return costs, minCost, nil
+}
+
+// cellQueueItem is a cell waiting in a cellQueue, with the cost of reaching
+// it and its priority.
+type cellQueueItem struct {
+	cell     H3Index
+	cost     float64
+	priority float64
+}
+
+// cellQueue is a min-heap of cells ordered by priority, for use with
+// container/heap.
+type cellQueue []cellQueueItem
+
+func (q cellQueue) Len() int           { return len(q) }
+func (q cellQueue) Less(i, j int) bool { return q[i].priority < q[j].priority }
+func (q cellQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *cellQueue) Push(x any) { *q = append(*q, x.(cellQueueItem)) }
+
+func (q *cellQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,191 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// cheapestCosts finds the cost of the cheapest way from start to every cell
+// of allowed, by relaxing every cell until nothing changes. Unreachable
+// cells are left out.
+func cheapestCosts(start H3Index, allowed CellSet, cost CellCostFunc) map[H3Index]float64 {
+	best := map[H3Index]float64{start: 0}
+	for changed := true; changed; {
+		changed = false
+		for cell := range allowed {
+			c, ok := best[cell]
+			if !ok {
+				continue
+			}
+			for _, neighbor := range appendNeighbors(nil, cell) {
+				if !allowed.Has(neighbor) {
+					continue
+				}
+				total := c + cost(neighbor)
+				if prev, seen := best[neighbor]; math.IsInf(total, 1) || seen && prev <= total {
+					continue
+				}
+				best[neighbor] = total
+				changed = true
+			}
+		}
+	}
+	return best
+}
+
+// checkPath fails the test unless path is a path of neighboring cells of
+// allowed from start to end whose cells after start cost cost in total.
+func checkPath(t *testing.T, path []H3Index, start, end H3Index, allowed CellSet, cost CellCostFunc, want float64) {
+	t.Helper()
+	if len(path) == 0 || path[0] != start || path[len(path)-1] != end {
+		t.Fatalf("path %v does not lead from %s to %s", path, start, end)
+	}
+	total := 0.0
+	for i, cell := range path {
+		if !allowed.Has(cell) {
+			t.Fatalf("path %v passes through %s, which is not allowed", path, cell)
+		}
+		if i > 0 {
+			if !H3IndexesAreNeighbors(path[i-1], cell) {
+				t.Fatalf("path %v jumps from %s to %s", path, path[i-1], cell)
+			}
+			total += cost(cell)
+		}
+	}
+	if math.Abs(total-want) > 1e-9 {
+		t.Fatalf("path %v from %s to %s costs %v, want %v", path, start, end, total, want)
+	}
+}
+
+func TestShortestPathAroundPentagon(t *testing.T) {
+	pentagons := make([]H3Index, PentagonIndexCount())
+	GetPentagonIndexes(3, &pentagons)
+	unit := func(H3Index) float64 { return 1 }
+
+	for _, pentagon := range pentagons[:4] {
+		disk, err := GridDisk(pentagon, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		allowed := NewCellSet(disk...)
+		ring, err := GridRing(pentagon, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// With unit costs the paths from one side of the pentagon have as
+		// many steps as the breadth-first search takes.
+		start := ring[0]
+		want := cheapestCosts(start, allowed, unit)
+		for end := range allowed {
+			path, got, err := ShortestPath(start, end, allowed, nil)
+			if err != nil {
+				t.Fatalf("ShortestPath(%s, %s): %v", start, end, err)
+			}
+			if got != want[end] || len(path) != int(got)+1 {
+				t.Fatalf("ShortestPath(%s, %s) = %d cells costing %v, want cost %v", start, end, len(path), got, want[end])
+			}
+			checkPath(t, path, start, end, allowed, unit, got)
+		}
+	}
+}
+
+func TestShortestPathWeighted(t *testing.T) {
+	pentagons := make([]H3Index, PentagonIndexCount())
+	GetPentagonIndexes(3, &pentagons)
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	for _, pentagon := range pentagons {
+		disk, err := GridDisk(pentagon, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		allowed := NewCellSet(disk...)
+
+		// Costs between 0 and 3, with free and impassable cells.
+		costs := make(map[H3Index]float64, len(disk))
+		for _, cell := range disk {
+			switch rng.IntN(8) {
+			case 0:
+				costs[cell] = 0
+			case 1:
+				costs[cell] = math.Inf(1)
+			default:
+				costs[cell] = 3 * rng.Float64()
+			}
+		}
+		cost := func(cell H3Index) float64 { return costs[cell] }
+
+		start := disk[rng.IntN(len(disk))]
+		want := cheapestCosts(start, allowed, cost)
+		for _, end := range disk {
+			path, got, err := ShortestPath(start, end, allowed, cost)
+			if _, reachable := want[end]; !reachable {
+				if !errors.Is(err, ErrNoPath) {
+					t.Fatalf("ShortestPath(%s, %s) to an unreachable cell error = %v, want ErrNoPath", start, end, err)
+				}
+				continue
+			}
+			if err != nil || math.Abs(got-want[end]) > 1e-9 {
+				t.Fatalf("ShortestPath(%s, %s) = cost %v, %v, want %v", start, end, got, err, want[end])
+			}
+			checkPath(t, path, start, end, allowed, cost, got)
+		}
+	}
+}
+
+func TestShortestPathErrors(t *testing.T) {
+	pentagons := make([]H3Index, PentagonIndexCount())
+	GetPentagonIndexes(3, &pentagons)
+	disk, err := GridDisk(pentagons[0], 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowed := NewCellSet(disk...)
+	start, end := disk[0], disk[len(disk)-1]
+
+	for _, tt := range []struct {
+		name       string
+		start, end H3Index
+		allowed    CellSet
+		cost       CellCostFunc
+		want       error
+	}{
+		{"invalid start", H3Index(0x7fffffffffffffff), end, allowed, nil, ErrInvalidCell},
+		{"start not allowed", start, end, NewCellSet(end), nil, ErrNoPath},
+		{"resolution mismatch", start, H3ToParent(end, 2), NewCellSet(start, H3ToParent(end, 2)), nil, ErrResolutionMismatch},
+		{"negative cost", start, end, allowed, func(H3Index) float64 { return -1 }, ErrInvalidCost},
+		{"NaN cost", start, end, allowed, func(H3Index) float64 { return math.NaN() }, ErrInvalidCost},
+		{"impassable", start, end, allowed, func(cell H3Index) float64 {
+			if cell == end {
+				return math.Inf(1)
+			}
+			return 1
+		}, ErrNoPath},
+		{"disconnected", start, end, NewCellSet(start, end), nil, ErrNoPath},
+	} {
+		if _, _, err := ShortestPath(tt.start, tt.end, tt.allowed, tt.cost); !errors.Is(err, tt.want) {
+			t.Errorf("ShortestPath with %s error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	if path, cost, err := ShortestPath(start, start, allowed, nil); err != nil || len(path) != 1 || cost != 0 {
+		t.Errorf("ShortestPath(%s, %s) = %v, %v, %v, want just the start", start, start, path, cost, err)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"context"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// Number of chunks PolygonToCellsParallel aims to hand each worker, so that
+// workers finishing small chunks early pick up the remaining ones.
+const parallelPolyfillChunksPerWorker = 8
+
+// Number of cells a polyfill worker classifies between checks for
+// cancellation.
+const parallelPolyfillCheckInterval = 256
+
+// PolygonToCellsParallel produces the same cells as PolygonToCells on a pool
+// of workers goroutines, or GOMAXPROCS of them if workers is not positive.
+//
+// The area around the polygon is partitioned into coarse cells, which serve
+// as chunks. Each worker fills its chunks by refinement, as
+// PolygonToCellsCompact does, and expands the cells it keeps to res, so the
+// chunks never overlap and their results are simply concatenated. Workers
+// stop as soon as ctx is done.
+//
+// It is PolygonToCells with the WithContext and WithParallelism options.
+//
+// Return the cells in ascending order, ctx.Err() if ctx was done before all
+// the cells were found, or the errors of PolygonToCells.
+func PolygonToCellsParallel(ctx context.Context, poly GeoPolygon, res int, workers int) ([]H3Index, error) {
+	return PolygonToCells(poly, res, WithContext(ctx), WithParallelism(workers))
+}
+
+// polygonToCellsParallel is PolygonToCellsParallel, finding the cells by
+// center.
+func polygonToCellsParallel(ctx context.Context, poly GeoPolygon, res int, workers int) ([]H3Index, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if res <= compactPolyfillStartRes {
+		return polygonToCells(poly, res)
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	chunks, err := compactPolyfillSeeds(&poly)
+	if err != nil || len(chunks) == 0 {
+		return nil, err
+	}
+	for len(chunks) < parallelPolyfillChunksPerWorker*workers && chunks[0].GetResolution() < res {
+		finer := make([]H3Index, 0, 7*len(chunks))
+		for _, chunk := range chunks {
+			finer = AppendChildren(finer, chunk, chunk.GetResolution()+1)
+		}
+		chunks = finer
+	}
+
+	bboxes := make([]BBox, poly.numHoles+1)
+	bboxesFromGeoPolygon(&poly, bboxes)
+
+	results := make([][]H3Index, len(chunks))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(chunks)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = fillPolygonChunk(ctx, chunks[i], res, &poly, bboxes)
+			}
+		}()
+	}
+
+feed:
+	for i := range chunks {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	size := 0
+	for _, cells := range results {
+		size += len(cells)
+	}
+	out := make([]H3Index, 0, size)
+	for _, cells := range results {
+		out = append(out, cells...)
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+// fillPolygonChunk finds the cells at res descending from chunk whose
+// centers are inside poly, with its bounding boxes, giving up once ctx is
+// done.
+//
+// Return the cells in no particular order, or nil if ctx is done.
+func fillPolygonChunk(ctx context.Context, chunk H3Index, res int, poly *GeoPolygon, bboxes []BBox) []H3Index {
+	var out []H3Index
+	stack := []H3Index{chunk}
+	for n := 1; len(stack) > 0; n++ {
+		if n%parallelPolyfillCheckInterval == 0 && ctx.Err() != nil {
+			return nil
+		}
+
+		cell := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		keep, split := classifyPolyfillCell(cell, res, poly, bboxes)
+		switch {
+		case split:
+			stack = AppendChildren(stack, cell, cell.GetResolution()+1)
+		case keep:
+			out = AppendChildren(out, cell, res)
+		}
+	}
+	return out
+}
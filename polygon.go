@@ -0,0 +1,610 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Geofence is similar to GeoBoundary, but requires more alloc work
+type Geofence struct {
+	numVerts int
+	verts    []GeoCoord
+}
+
+// GeoPolygon is simplified core of GeoJSON Polygon coordinates definition
+type GeoPolygon struct {
+	geofence Geofence   // exterior boundary of the polygon
+	numHoles int        // number of elements in the array pointed to by holes
+	holes    []Geofence // interior boundaries (holes) in the polygon
+	bboxes   []BBox     // bounding boxes of geofence and holes, if precomputed
+}
+
+// NewGeofence returns a geofence with the given vertices. The loop is closed
+// implicitly, so the last vertex should not repeat the first.
+func NewGeofence(verts []GeoCoord) Geofence {
+	v := make([]GeoCoord, len(verts))
+	copy(v, verts)
+	return Geofence{numVerts: len(v), verts: v}
+}
+
+// NewGeoPolygon returns a polygon with the exterior boundary geofence and the
+// given holes.
+func NewGeoPolygon(geofence Geofence, holes ...Geofence) GeoPolygon {
+	h := make([]Geofence, len(holes))
+	copy(h, holes)
+	return GeoPolygon{geofence: geofence, numHoles: len(h), holes: h}
+}
+
+// NewValidGeofence returns a geofence with the given vertices after checking
+// that they form a ring the polygon functions can use. The ring may be
+// closed explicitly by repeating the first vertex at the end, and repeated
+// consecutive vertices are merged. If checkSelfIntersection is set, rings
+// whose edges cross or touch other than at shared vertices are rejected too,
+// at a cost quadratic in the number of vertices.
+//
+// Return the geofence, ErrInvalidGeoCoord if a vertex is not finite, has a
+// latitude beyond a pole or a longitude outside [-180, 180] degrees (see
+// NormalizeGeofence), or ErrInvalidPolygon if fewer than 3 distinct vertices
+// remain or the ring intersects itself.
+func NewValidGeofence(verts []GeoCoord, checkSelfIntersection bool) (Geofence, error) {
+	v := make([]GeoCoord, 0, len(verts))
+	for i, vert := range verts {
+		if !isFinite(vert.lat) || !isFinite(vert.lon) ||
+			math.Abs(vert.lat) > M_PI_2 || math.Abs(vert.lon) > M_PI {
+			return Geofence{}, fmt.Errorf("%w: vertex %d (%v, %v)", ErrInvalidGeoCoord, i, vert.LatDegs(), vert.LonDegs())
+		}
+		if len(v) > 0 && v[len(v)-1] == vert {
+			continue
+		}
+		v = append(v, vert)
+	}
+	if len(v) > 1 && v[0] == v[len(v)-1] {
+		v = v[:len(v)-1]
+	}
+	if len(v) < 3 {
+		return Geofence{}, fmt.Errorf("%w: ring has %d distinct vertices, needs at least 3", ErrInvalidPolygon, len(v))
+	}
+
+	geofence := Geofence{numVerts: len(v), verts: v}
+	if checkSelfIntersection {
+		if i, j, ok := geofenceSelfIntersection(&geofence); ok {
+			return Geofence{}, fmt.Errorf("%w: edges %d and %d of the ring intersect", ErrInvalidPolygon, i, j)
+		}
+	}
+	return geofence, nil
+}
+
+// NewValidGeoPolygon returns a polygon with the exterior boundary geofence
+// and the given holes, as NewGeoPolygon does, after checking every ring as
+// NewValidGeofence does without the self-intersection test. The bounding
+// boxes of the rings are computed once here rather than on every use of the
+// polygon.
+//
+// Return the polygon, or the errors of NewValidGeofence.
+func NewValidGeoPolygon(geofence Geofence, holes ...Geofence) (GeoPolygon, error) {
+	rings := append([]Geofence{geofence}, holes...)
+	for i := range rings {
+		ring, err := NewValidGeofence(rings[i].verts[:rings[i].numVerts], false)
+		if err != nil {
+			if i == 0 {
+				return GeoPolygon{}, fmt.Errorf("exterior boundary: %w", err)
+			}
+			return GeoPolygon{}, fmt.Errorf("hole %d: %w", i-1, err)
+		}
+		rings[i] = ring
+	}
+
+	poly := NewGeoPolygon(rings[0], rings[1:]...)
+	bboxes := make([]BBox, len(rings))
+	bboxesFromGeoPolygon(&poly, bboxes)
+	poly.bboxes = bboxes
+	return poly, nil
+}
+
+// geofenceSelfIntersection finds two edges of geofence which intersect,
+// other than consecutive edges at their shared vertex. Edge i runs from
+// vertex i to the next.
+//
+// Return the indexes of the edges, and whether there are any.
+func geofenceSelfIntersection(geofence *Geofence) (int, int, bool) {
+	var bbox BBox
+	bboxFromGeofence(geofence, &bbox)
+	isTransmeridian := bboxIsTransmeridian(&bbox)
+
+	n := geofence.numVerts
+	for i := 0; i < n; i++ {
+		a1, a2 := &geofence.verts[i], &geofence.verts[(i+1)%n]
+		for j := i + 2; j < n; j++ {
+			if i == 0 && j == n-1 {
+				continue
+			}
+			b1, b2 := &geofence.verts[j], &geofence.verts[(j+1)%n]
+			if segmentsIntersect(a1, a2, b1, b2, isTransmeridian) {
+				return i, j, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// Verts returns the vertices of the geofence.
+func (g Geofence) Verts() []GeoCoord {
+	return g.verts[:g.numVerts]
+}
+
+// Geofence returns the exterior boundary of the polygon.
+func (p GeoPolygon) Geofence() Geofence {
+	return p.geofence
+}
+
+// Holes returns the interior boundaries of the polygon.
+func (p GeoPolygon) Holes() []Geofence {
+	return p.holes[:p.numHoles]
+}
+
+// NormalizeGeofence returns a copy of geofence with every longitude wrapped
+// into [-180, 180] degrees.
+//
+// The polygon functions take an edge spanning more than 180 degrees of
+// longitude to cross the antimeridian, which only works for normalized
+// longitudes. Rings spanning the antimeridian are often written instead with
+// longitudes running continuously past it, such as from 179 to 181 degrees,
+// and must be normalized before use; rings already normalized are returned
+// unchanged.
+func NormalizeGeofence(geofence Geofence) Geofence {
+	verts := make([]GeoCoord, geofence.numVerts)
+	for i, v := range geofence.verts[:geofence.numVerts] {
+		verts[i] = GeoCoord{lat: v.lat, lon: constrainLng(v.lon)}
+	}
+	return Geofence{numVerts: len(verts), verts: verts}
+}
+
+// normalizeLng normalizes longitude, shifting negative values into the
+// eastern hemisphere for transmeridian shapes.
+func normalizeLng(lng float64, isTransmeridian bool) float64 {
+	if isTransmeridian && lng < 0 {
+		return lng + M_2PI
+	}
+	return lng
+}
+
+// pointInsideGeofence takes a given geofence and its bounding box, and
+// determines if the point is contained within it, using the ray casting
+// algorithm.
+//
+// Return whether the point is contained.
+func pointInsideGeofence(geofence *Geofence, bbox *BBox, coord *GeoCoord) bool {
+	// fail fast if we're outside the bounding box
+	if !bboxContains(bbox, coord) {
+		return false
+	}
+	isTransmeridian := bboxIsTransmeridian(bbox)
+	contains := false
+
+	lat := coord.lat
+	lng := normalizeLng(coord.lon, isTransmeridian)
+
+	for i := 0; i < geofence.numVerts; i++ {
+		a := geofence.verts[i]
+		b := geofence.verts[(i+1)%geofence.numVerts]
+
+		// Ray casting algo requires the second point to always be higher
+		// than the first, so swap if needed
+		if a.lat > b.lat {
+			a, b = b, a
+		}
+
+		// If we're totally above or below the latitude ranges, the test
+		// ray cannot intersect the line segment, so let's move on
+		if lat < a.lat || lat > b.lat {
+			continue
+		}
+
+		aLng := normalizeLng(a.lon, isTransmeridian)
+		bLng := normalizeLng(b.lon, isTransmeridian)
+
+		// Rays are cast in the longitudinal direction, in case a point
+		// exactly matches, to decide tiebreakers, bias westerly
+		if aLng == lng || bLng == lng {
+			lng -= DBL_EPSILON
+		}
+
+		// For the latitude of the point, compute the longitude of the
+		// point that lies on the line segment defined by a and b
+		// This is done by computing the percent above a the lat is,
+		// and traversing the same percent in the longitudinal direction
+		// of a to b
+		ratio := (lat - a.lat) / (b.lat - a.lat)
+		testLng := normalizeLng(aLng+(bLng-aLng)*ratio, isTransmeridian)
+
+		// Intersection of the ray
+		if testLng > lng {
+			contains = !contains
+		}
+	}
+
+	return contains
+}
+
+// bboxFromGeofence creates a bounding box from a simple polygon loop.
+// Known limitations:
+//   - Does not support polygons with two adjacent points > 180 degrees of
+//     longitude apart. These will be interpreted as crossing the antimeridian.
+//   - Does not currently support polygons containing a pole.
+//   - Requires normalized longitudes, see NormalizeGeofence.
+func bboxFromGeofence(geofence *Geofence, bbox *BBox) {
+	// Early exit if there are no vertices
+	if geofence.numVerts == 0 {
+		*bbox = BBox{}
+		return
+	}
+
+	bbox.south = math.MaxFloat64
+	bbox.west = math.MaxFloat64
+	bbox.north = -math.MaxFloat64
+	bbox.east = -math.MaxFloat64
+	minPosLon := math.MaxFloat64
+	maxNegLon := -math.MaxFloat64
+	isTransmeridian := false
+
+	for i := 0; i < geofence.numVerts; i++ {
+		coord := geofence.verts[i]
+		next := geofence.verts[(i+1)%geofence.numVerts]
+
+		lat := coord.lat
+		lon := coord.lon
+		if lat < bbox.south {
+			bbox.south = lat
+		}
+		if lon < bbox.west {
+			bbox.west = lon
+		}
+		if lat > bbox.north {
+			bbox.north = lat
+		}
+		if lon > bbox.east {
+			bbox.east = lon
+		}
+		// Save the min positive and max negative longitude for
+		// use in the transmeridian case
+		if lon > 0 && lon < minPosLon {
+			minPosLon = lon
+		}
+		if lon < 0 && lon > maxNegLon {
+			maxNegLon = lon
+		}
+		// check for arcs > 180 degrees longitude, flagging as transmeridian
+		if math.Abs(lon-next.lon) > M_PI {
+			isTransmeridian = true
+		}
+	}
+	// Swap east and west if transmeridian
+	if isTransmeridian {
+		bbox.east = maxNegLon
+		bbox.west = minPosLon
+	}
+}
+
+// isClockwiseNormalizedGeofence determines whether a geofence is clockwise,
+// using the sum of the edges of the loop. If isTransmeridian is not set and a
+// transmeridian arc is found, it starts over with the flag set.
+func isClockwiseNormalizedGeofence(geofence *Geofence, isTransmeridian bool) bool {
+	sum := 0.0
+	for i := 0; i < geofence.numVerts; i++ {
+		a := geofence.verts[i]
+		b := geofence.verts[(i+1)%geofence.numVerts]
+
+		// If we identify a transmeridian arc (> 180 degrees longitude),
+		// start over with the transmeridian flag set
+		if !isTransmeridian && math.Abs(a.lon-b.lon) > M_PI {
+			return isClockwiseNormalizedGeofence(geofence, true)
+		}
+		sum += (normalizeLng(b.lon, isTransmeridian) - normalizeLng(a.lon, isTransmeridian)) *
+			(b.lat + a.lat)
+	}
+
+	return sum > 0
+}
+
+// isClockwiseGeofence determines whether a geofence is clockwise.
+//
+// Return whether the loop is clockwise.
+func isClockwiseGeofence(geofence *Geofence) bool {
+	return isClockwiseNormalizedGeofence(geofence, false)
+}
+
+// bboxesFromGeoPolygon creates the bounding boxes of a polygon, one for the
+// exterior boundary followed by one per hole. bboxes must have room for
+// numHoles + 1 bounding boxes. Precomputed bounding boxes are copied.
+func bboxesFromGeoPolygon(polygon *GeoPolygon, bboxes []BBox) {
+	if len(polygon.bboxes) == polygon.numHoles+1 {
+		copy(bboxes, polygon.bboxes)
+		return
+	}
+	bboxFromGeofence(&polygon.geofence, &bboxes[0])
+	for i := 0; i < polygon.numHoles; i++ {
+		bboxFromGeofence(&polygon.holes[i], &bboxes[i+1])
+	}
+}
+
+// pointInsidePolygon takes a given GeoPolygon data structure and checks if it
+// contains a given geo coordinate.
+//
+// Return whether the point is contained.
+func pointInsidePolygon(geoPolygon *GeoPolygon, bboxes []BBox, coord *GeoCoord) bool {
+	// Start with contains state of primary geofence
+	contains := pointInsideGeofence(&geoPolygon.geofence, &bboxes[0], coord)
+
+	// If the point is contained in the primary geofence, but there are holes in
+	// the geofence iterate through all holes and return false if the point is
+	// contained in any hole
+	if contains && geoPolygon.numHoles > 0 {
+		for i := 0; i < geoPolygon.numHoles; i++ {
+			if pointInsideGeofence(&geoPolygon.holes[i], &bboxes[i+1], coord) {
+				return false
+			}
+		}
+	}
+
+	return contains
+}
+
+// segmentsIntersect reports whether the segments a1-a2 and b1-b2 intersect,
+// including touching, in the plane of normalized longitude and latitude.
+func segmentsIntersect(a1, a2, b1, b2 *GeoCoord, isTransmeridian bool) bool {
+	ax1, ax2 := normalizeLng(a1.lon, isTransmeridian), normalizeLng(a2.lon, isTransmeridian)
+	bx1, bx2 := normalizeLng(b1.lon, isTransmeridian), normalizeLng(b2.lon, isTransmeridian)
+
+	// orientation of the point (x, y) relative to the directed line p-q
+	orient := func(px, py, qx, qy, x, y float64) float64 {
+		return (qx-px)*(y-py) - (qy-py)*(x-px)
+	}
+	// whether (x, y), known to be collinear with p-q, lies on the segment
+	onSegment := func(px, py, qx, qy, x, y float64) bool {
+		return math.Min(px, qx) <= x && x <= math.Max(px, qx) &&
+			math.Min(py, qy) <= y && y <= math.Max(py, qy)
+	}
+
+	d1 := orient(bx1, b1.lat, bx2, b2.lat, ax1, a1.lat)
+	d2 := orient(bx1, b1.lat, bx2, b2.lat, ax2, a2.lat)
+	d3 := orient(ax1, a1.lat, ax2, a2.lat, bx1, b1.lat)
+	d4 := orient(ax1, a1.lat, ax2, a2.lat, bx2, b2.lat)
+
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) &&
+		((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+
+	return (d1 == 0 && onSegment(bx1, b1.lat, bx2, b2.lat, ax1, a1.lat)) ||
+		(d2 == 0 && onSegment(bx1, b1.lat, bx2, b2.lat, ax2, a2.lat)) ||
+		(d3 == 0 && onSegment(ax1, a1.lat, ax2, a2.lat, bx1, b1.lat)) ||
+		(d4 == 0 && onSegment(ax1, a1.lat, ax2, a2.lat, bx2, b2.lat))
+}
+
+// geofenceIntersectsGeofence reports whether any edge of a crosses or touches
+// any edge of b.
+func geofenceIntersectsGeofence(a, b *Geofence, isTransmeridian bool) bool {
+	for i := 0; i < a.numVerts; i++ {
+		a1 := &a.verts[i]
+		a2 := &a.verts[(i+1)%a.numVerts]
+		for j := 0; j < b.numVerts; j++ {
+			b1 := &b.verts[j]
+			b2 := &b.verts[(j+1)%b.numVerts]
+			if segmentsIntersect(a1, a2, b1, b2, isTransmeridian) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cellGeofence returns the boundary of cell as a geofence with its bounding
+// box.
+func cellGeofence(cell H3Index) (Geofence, BBox) {
+	var gb GeoBoundary
+	H3ToGeoBoundary(cell, &gb)
+
+	fence := Geofence{numVerts: gb.numVerts, verts: make([]GeoCoord, gb.numVerts)}
+	copy(fence.verts, gb.verts[:gb.numVerts])
+
+	var bbox BBox
+	bboxFromGeofence(&fence, &bbox)
+	return fence, bbox
+}
+
+// CellIntersectsPolygon reports whether the area of cell and the area of poly
+// overlap. This is the case if any vertex of the cell is inside the polygon,
+// any vertex of the polygon is inside the cell, or any edge of the cell
+// crosses an edge of the polygon or of one of its holes.
+//
+// Edges are treated as straight lines in latitude and longitude, consistent
+// with the point-in-polygon test used for polyfill.
+func CellIntersectsPolygon(cell H3Index, poly GeoPolygon) bool {
+	if !cell.IsValid() || poly.geofence.numVerts == 0 {
+		return false
+	}
+
+	bboxes := make([]BBox, poly.numHoles+1)
+	bboxesFromGeoPolygon(&poly, bboxes)
+	fence, cellBBox := cellGeofence(cell)
+
+	return cellIntersectsPolygon(&fence, &cellBBox, &poly, bboxes)
+}
+
+// cellIntersectsPolygon is CellIntersectsPolygon for a cell boundary fence
+// with its bounding box and a polygon with its bounding boxes.
+func cellIntersectsPolygon(fence *Geofence, cellBBox *BBox, poly *GeoPolygon, bboxes []BBox) bool {
+	for i := 0; i < fence.numVerts; i++ {
+		if pointInsidePolygon(poly, bboxes, &fence.verts[i]) {
+			return true
+		}
+	}
+
+	outer := &poly.geofence
+	for i := 0; i < outer.numVerts; i++ {
+		if pointInsideGeofence(fence, cellBBox, &outer.verts[i]) {
+			return true
+		}
+	}
+
+	isTransmeridian := bboxIsTransmeridian(cellBBox) || bboxIsTransmeridian(&bboxes[0])
+	if geofenceIntersectsGeofence(fence, outer, isTransmeridian) {
+		return true
+	}
+	for i := 0; i < poly.numHoles; i++ {
+		if geofenceIntersectsGeofence(fence, &poly.holes[i], isTransmeridian) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SegmentIntersectsCell reports whether the segment from a to b touches the
+// area of cell, either because an end point lies inside the cell or because
+// the segment crosses an edge of the cell.
+//
+// The segment is treated as a straight line in latitude and longitude, which
+// is a close approximation of the great circle arc for segments short
+// relative to the cell, such as consecutive GPS fixes. Segments spanning more
+// than 180 degrees of longitude are taken to cross the antimeridian.
+func SegmentIntersectsCell(a, b GeoCoord, cell H3Index) bool {
+	if !cell.IsValid() {
+		return false
+	}
+
+	fence, bbox := cellGeofence(cell)
+	if pointInsideGeofence(&fence, &bbox, &a) || pointInsideGeofence(&fence, &bbox, &b) {
+		return true
+	}
+
+	isTransmeridian := bboxIsTransmeridian(&bbox) || math.Abs(a.lon-b.lon) > M_PI
+	for i := 0; i < fence.numVerts; i++ {
+		if segmentsIntersect(&a, &b, &fence.verts[i], &fence.verts[(i+1)%fence.numVerts], isTransmeridian) {
+			return true
+		}
+	}
+	return false
+}
+
+// PolygonBoundaryCells returns the cells at res intersecting the boundary of
+// poly, that is its exterior loop and the loops of its holes, sorted in
+// ascending order. Cells entirely inside or outside the polygon are not
+// included, which keeps the result small for border monitoring at fine
+// resolutions.
+//
+// Each edge is traced by walking from the cell containing its start through
+// neighboring cells intersecting the edge, see SegmentIntersectsCell.
+//
+// Return ErrInvalidResolution if res is out of range, or ErrInvalidGeoCoord
+// if a vertex of the polygon is not finite.
+func PolygonBoundaryCells(poly GeoPolygon, res int) ([]H3Index, error) {
+	if res < 0 || res > MAX_H3_RES {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidResolution, res)
+	}
+
+	found := make(map[H3Index]struct{})
+	if err := traceGeofenceCells(&poly.geofence, res, found); err != nil {
+		return nil, err
+	}
+	for i := 0; i < poly.numHoles; i++ {
+		if err := traceGeofenceCells(&poly.holes[i], res, found); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]H3Index, 0, len(found))
+	for cell := range found {
+		out = append(out, cell)
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+// Extra room MaxPolygonToCellsSize adds for very small polygons near an
+// icosahedron edge at odd resolutions, where tracing the edges finds more
+// cells than the bounding box estimate allows for.
+const POLYFILL_BUFFER = 12
+
+// MaxPolygonToCellsSize estimates the number of cells at res whose centers
+// fall within poly, erring on the large side, so the cells of a polygon can
+// be allocated and held to a memory budget before they are computed. The
+// estimate is that of the reference implementation: the cells fitting in
+// the bounding box of the exterior boundary, at least the number of
+// vertices, plus POLYFILL_BUFFER. Holes are not subtracted.
+//
+// Return the estimate, or ErrInvalidResolution if res is out of range.
+func MaxPolygonToCellsSize(poly GeoPolygon, res int) (int, error) {
+	if res < 0 || res > MAX_H3_RES {
+		return 0, fmt.Errorf("%w: %d", ErrInvalidResolution, res)
+	}
+
+	var bbox BBox
+	bboxFromGeofence(&poly.geofence, &bbox)
+	numHexagons := bboxHexEstimate(&bbox, res)
+
+	// The estimate assumes that the vertices are usually fewer than the
+	// cells; this keeps it from failing when they are not.
+	totalVerts := poly.geofence.numVerts
+	for i := 0; i < poly.numHoles; i++ {
+		totalVerts += poly.holes[i].numVerts
+	}
+	numHexagons = max(numHexagons, totalVerts)
+
+	return numHexagons + POLYFILL_BUFFER, nil
+}
+
+// traceGeofenceCells adds the cells at res intersecting the edges of geofence
+// to found.
+func traceGeofenceCells(geofence *Geofence, res int, found map[H3Index]struct{}) error {
+	for i := 0; i < geofence.numVerts; i++ {
+		a := geofence.verts[i]
+		b := geofence.verts[(i+1)%geofence.numVerts]
+
+		start := GeoToH3(&a, res)
+		if start == H3_NULL {
+			return fmt.Errorf("%w: vertex %d", ErrInvalidGeoCoord, i)
+		}
+
+		// The cells intersecting a segment are connected, so a search from
+		// the cell containing its start finds all of them.
+		visited := map[H3Index]struct{}{start: {}}
+		queue := []H3Index{start}
+		for len(queue) > 0 {
+			cell := queue[0]
+			queue = queue[1:]
+			recordEvent(EventPolyfillCellVisited, 1)
+			if !SegmentIntersectsCell(a, b, cell) {
+				continue
+			}
+			found[cell] = struct{}{}
+
+			for _, neighbor := range kRing(cell, 1) {
+				if neighbor == H3_NULL {
+					continue
+				}
+				if _, ok := visited[neighbor]; ok {
+					continue
+				}
+				visited[neighbor] = struct{}{}
+				queue = append(queue, neighbor)
+			}
+		}
+	}
+	return nil
+}
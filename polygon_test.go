@@ -0,0 +1,132 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// Rings crossing the antimeridian, written with longitudes running
+// continuously past 180 degrees as they often are in source data.
+var (
+	fijiDegs = [][2]float64{
+		{-16.0, 177.0}, {-16.0, 180.5}, {-19.2, 180.5}, {-19.2, 177.0},
+	}
+	chukotkaDegs = [][2]float64{
+		{67.5, 172.0}, {67.5, 190.0}, {64.0, 190.0}, {64.0, 180.0}, {65.0, 172.0},
+	}
+)
+
+func geofenceDegs(verts [][2]float64) Geofence {
+	coords := make([]GeoCoord, len(verts))
+	for i, v := range verts {
+		coords[i] = NewGeoCoordDegs(v[0], v[1])
+	}
+	return NewGeofence(coords)
+}
+
+func TestNormalizeGeofence(t *testing.T) {
+	got := NormalizeGeofence(geofenceDegs(fijiDegs)).verts
+	want := []GeoCoord{
+		NewGeoCoordDegs(-16.0, 177.0), NewGeoCoordDegs(-16.0, -179.5),
+		NewGeoCoordDegs(-19.2, -179.5), NewGeoCoordDegs(-19.2, 177.0),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("NormalizeGeofence gave %d vertices, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].lat != want[i].lat || math.Abs(got[i].lon-want[i].lon) > EPSILON_RAD {
+			t.Errorf("vertex %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	normalized := NormalizeGeofence(geofenceDegs(fijiDegs))
+	if again := NormalizeGeofence(normalized).verts; !slices.Equal(again, normalized.verts) {
+		t.Errorf("NormalizeGeofence changed a normalized ring: %v to %v", normalized.verts, again)
+	}
+
+	var bbox BBox
+	bboxFromGeofence(&normalized, &bbox)
+	if !bboxIsTransmeridian(&bbox) {
+		t.Errorf("bbox %v of normalized ring is not transmeridian", bbox)
+	}
+	if math.Abs(bbox.west-DegsToRads(177.0)) > EPSILON_RAD ||
+		math.Abs(bbox.east-DegsToRads(-179.5)) > EPSILON_RAD {
+		t.Errorf("bbox %v, want west 177 and east -179.5 degrees", bbox)
+	}
+}
+
+func TestPolygonAntimeridian(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		verts   [][2]float64
+		inside  [][2]float64
+		outside [][2]float64
+	}{
+		{
+			name:    "Fiji",
+			verts:   fijiDegs,
+			inside:  [][2]float64{{-17.7, 178.0}, {-17.7, 179.99}, {-17.7, -179.8}},
+			outside: [][2]float64{{-17.7, 176.0}, {-17.7, -179.0}, {-15.0, 179.0}},
+		},
+		{
+			name:    "Chukotka",
+			verts:   chukotkaDegs,
+			inside:  [][2]float64{{66.0, 175.0}, {66.0, -179.9}, {65.0, -175.0}},
+			outside: [][2]float64{{66.0, -169.0}, {64.5, 172.5}, {68.0, 180.0}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			poly := NewGeoPolygon(NormalizeGeofence(geofenceDegs(tt.verts)))
+			bboxes := make([]BBox, 1)
+			bboxesFromGeoPolygon(&poly, bboxes)
+			for _, p := range tt.inside {
+				g := NewGeoCoordDegs(p[0], p[1])
+				if !pointInsidePolygon(&poly, bboxes, &g) {
+					t.Errorf("%v is not inside the polygon", p)
+				}
+			}
+			for _, p := range tt.outside {
+				g := NewGeoCoordDegs(p[0], p[1])
+				if pointInsidePolygon(&poly, bboxes, &g) {
+					t.Errorf("%v is inside the polygon", p)
+				}
+			}
+
+			cells, err := PolygonToCells(poly, 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var east, west int
+			for _, cell := range cells {
+				var center GeoCoord
+				H3ToGeo(cell, &center)
+				if !pointInsidePolygon(&poly, bboxes, &center) {
+					t.Errorf("center of %s is not inside the polygon", cell)
+				}
+				if center.lon < 0 {
+					west++
+				} else {
+					east++
+				}
+			}
+			if east == 0 || west == 0 {
+				t.Errorf("%d cells east and %d west of the antimeridian, want both", east, west)
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"fmt"
+	"math"
+)
+
+// PolygonCoverageStats measures how well a set of cells covers a polygon.
+// Percentages are relative to the area of the polygon, and are left zero if
+// it has none.
+type PolygonCoverageStats struct {
+	PolygonKm2 float64 // area of the polygon
+	CoverKm2   float64 // area of the cells
+	OverKm2    float64 // area of the cells outside the polygon
+	UnderKm2   float64 // area of the polygon outside the cells
+
+	OverPercent  float64
+	UnderPercent float64
+}
+
+// MeasurePolygonCoverage compares the area of poly with the area of a cover
+// of it, such as the result of PolygonToCells, to tell how much of the cover
+// lies outside the polygon and how much of the polygon is left uncovered.
+// Comparing the figures for several resolutions or containment modes shows
+// the cost of each in accuracy.
+//
+// The cells may be at mixed resolutions; duplicates and cells covered by
+// their ancestors are counted once. Every cell is clipped against the
+// polygon, so the cost grows with the number of cells times the number of
+// vertices of the polygon. Edges are straight lines in latitude and
+// longitude, as for PolygonToCells, for the polygon and the cells alike.
+//
+// Return the measurements, or ErrInvalidCell if a cell is invalid.
+func MeasurePolygonCoverage(poly GeoPolygon, cover []H3Index) (PolygonCoverageStats, error) {
+	cells, err := normalizeCoverage(cover)
+	if err != nil {
+		return PolygonCoverageStats{}, err
+	}
+
+	clipper := newPolygonClipper(&poly)
+	var stats PolygonCoverageStats
+	var inside float64
+	for _, cell := range cells {
+		cellArea, overlap := clipper.cellOverlapRads2(cell)
+		stats.CoverKm2 += cellArea
+		inside += overlap
+	}
+	stats.PolygonKm2 = clipper.areaRads2()
+
+	// Convert once at the end, the sums being over many small areas.
+	rads2ToKm2 := EARTH_RADIUS_KM * EARTH_RADIUS_KM
+	stats.PolygonKm2 *= rads2ToKm2
+	stats.CoverKm2 *= rads2ToKm2
+	inside *= rads2ToKm2
+	stats.OverKm2 = math.Max(stats.CoverKm2-inside, 0)
+	stats.UnderKm2 = math.Max(stats.PolygonKm2-inside, 0)
+	if stats.PolygonKm2 > 0 {
+		stats.OverPercent = 100 * stats.OverKm2 / stats.PolygonKm2
+		stats.UnderPercent = 100 * stats.UnderKm2 / stats.PolygonKm2
+	}
+	return stats, nil
+}
+
+// CellPolygonOverlap returns the fraction of the area of cell inside poly,
+// from 0 for a cell outside the polygon to 1 for one entirely inside it.
+// Spreading a figure known for the polygon over cells in proportion to the
+// overlap disaggregates it onto the grid.
+//
+// The cell is clipped against the polygon, with edges as straight lines in
+// latitude and longitude as for PolygonToCells, so the fraction is exact up
+// to that approximation. Cells containing a pole are not supported.
+//
+// Return the fraction, or ErrInvalidCell if cell is invalid.
+func CellPolygonOverlap(cell H3Index, poly GeoPolygon) (float64, error) {
+	fractions, err := CellsPolygonOverlap([]H3Index{cell}, poly)
+	if err != nil {
+		return 0, err
+	}
+	return fractions[0], nil
+}
+
+// CellsPolygonOverlap is CellPolygonOverlap for many cells, preparing the
+// polygon only once.
+//
+// Return the fraction for each cell, in the order of cells, or
+// ErrInvalidCell if a cell is invalid.
+func CellsPolygonOverlap(cells []H3Index, poly GeoPolygon) ([]float64, error) {
+	for _, cell := range cells {
+		if !cell.IsValid() {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCell, cell)
+		}
+	}
+
+	fractions := make([]float64, len(cells))
+	if poly.geofence.numVerts == 0 {
+		return fractions, nil
+	}
+	clipper := newPolygonClipper(&poly)
+	for i, cell := range cells {
+		cellArea, overlap := clipper.cellOverlapRads2(cell)
+		if cellArea > 0 {
+			fractions[i] = overlap / cellArea
+		}
+	}
+	return fractions, nil
+}
+
+// polygonClipper measures the overlap of cells with a polygon. It holds the
+// rings of the polygon in both longitude frames, see normalizeLng, so cells
+// on either side of the antimeridian can be clipped without copying them.
+type polygonClipper struct {
+	rings  [2][][]GeoCoord // exterior boundary then holes, per frame
+	bboxes []BBox
+}
+
+// newPolygonClipper prepares poly for clipping.
+func newPolygonClipper(poly *GeoPolygon) *polygonClipper {
+	c := &polygonClipper{bboxes: make([]BBox, poly.numHoles+1)}
+	bboxesFromGeoPolygon(poly, c.bboxes)
+
+	loops := append([]Geofence{poly.geofence}, poly.holes[:poly.numHoles]...)
+	for frame := range c.rings {
+		c.rings[frame] = make([][]GeoCoord, len(loops))
+		for i := range loops {
+			c.rings[frame][i] = normalizedRing(loops[i].verts[:loops[i].numVerts], frame == 1)
+		}
+	}
+	return c
+}
+
+// areaRads2 returns the area of the polygon in radians^2, that of its
+// exterior boundary less that of its holes.
+func (c *polygonClipper) areaRads2() float64 {
+	frame := 0
+	if bboxIsTransmeridian(&c.bboxes[0]) {
+		frame = 1
+	}
+
+	area := 0.0
+	for i, ring := range c.rings[frame] {
+		if i == 0 {
+			area += math.Abs(ringAreaRads2(ring))
+		} else {
+			area -= math.Abs(ringAreaRads2(ring))
+		}
+	}
+	return math.Max(area, 0)
+}
+
+// cellOverlapRads2 clips cell against the polygon.
+//
+// Return the area of the cell and the area of its part inside the polygon,
+// in radians^2.
+func (c *polygonClipper) cellOverlapRads2(cell H3Index) (cellArea, overlap float64) {
+	fence, bbox := cellGeofence(cell)
+	isTransmeridian := bboxIsTransmeridian(&bbox) || bboxIsTransmeridian(&c.bboxes[0])
+	frame := 0
+	if isTransmeridian {
+		frame = 1
+	}
+
+	clip := normalizedRing(fence.verts, isTransmeridian)
+	cellArea = ringAreaRads2(clip)
+	if cellArea < 0 {
+		reverseRing(clip)
+		cellArea = -cellArea
+	}
+
+	for i, ring := range c.rings[frame] {
+		if bbox.north < c.bboxes[i].south || bbox.south > c.bboxes[i].north {
+			continue
+		}
+		area := math.Abs(ringAreaRads2(clipRingToConvex(ring, clip)))
+		if i == 0 {
+			overlap += area
+		} else {
+			overlap -= area
+		}
+	}
+	return cellArea, math.Min(math.Max(overlap, 0), cellArea)
+}
+
+// normalizedRing returns a copy of ring with its longitudes normalized for
+// the given frame, see normalizeLng.
+func normalizedRing(ring []GeoCoord, isTransmeridian bool) []GeoCoord {
+	out := make([]GeoCoord, len(ring))
+	for i, v := range ring {
+		out[i] = GeoCoord{lat: v.lat, lon: normalizeLng(v.lon, isTransmeridian)}
+	}
+	return out
+}
+
+// reverseRing reverses the order of the vertices of ring in place.
+func reverseRing(ring []GeoCoord) {
+	for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+		ring[i], ring[j] = ring[j], ring[i]
+	}
+}
+
+// ringAreaRads2 computes the area in radians^2 enclosed by a ring whose edges
+// are straight lines in latitude and longitude, by integrating -sin(lat) along
+// the edges, which is exact for such edges.
+//
+// Return the area, positive if the ring is counter-clockwise and negative
+// otherwise.
+func ringAreaRads2(ring []GeoCoord) float64 {
+	sum := 0.0
+	for i := range ring {
+		a := &ring[i]
+		b := &ring[(i+1)%len(ring)]
+		// (cos(a) - cos(b)) / (b - a), written so as not to cancel on short
+		// edges, which tends to sin(a) as b approaches a.
+		halfDLat := (b.lat - a.lat) / 2
+		term := math.Sin(a.lat + halfDLat)
+		if halfDLat != 0 {
+			term *= math.Sin(halfDLat) / halfDLat
+		}
+		sum += (b.lon - a.lon) * term
+	}
+	return -sum
+}
+
+// clipRingToConvex clips ring against the convex, counter-clockwise ring
+// clip with the Sutherland-Hodgman algorithm. The ring need not be convex:
+// parts of it cut off from each other are then joined by edges running back
+// and forth, which enclose no area, so the area of the result is still that
+// of the intersection.
+//
+// Return the clipped ring, which is empty if they do not overlap.
+func clipRingToConvex(ring, clip []GeoCoord) []GeoCoord {
+	// whether p is left of, or on, the directed line a-b
+	inside := func(p, a, b *GeoCoord) bool {
+		return (b.lon-a.lon)*(p.lat-a.lat)-(b.lat-a.lat)*(p.lon-a.lon) >= 0
+	}
+	// intersection of the segment p-q with the line a-b
+	intersect := func(p, q, a, b *GeoCoord) GeoCoord {
+		d1 := (b.lon-a.lon)*(p.lat-a.lat) - (b.lat-a.lat)*(p.lon-a.lon)
+		d2 := (b.lon-a.lon)*(q.lat-a.lat) - (b.lat-a.lat)*(q.lon-a.lon)
+		t := d1 / (d1 - d2)
+		return GeoCoord{lat: p.lat + t*(q.lat-p.lat), lon: p.lon + t*(q.lon-p.lon)}
+	}
+
+	out := ring
+	var in []GeoCoord
+	for i := range clip {
+		if len(out) == 0 {
+			break
+		}
+		a := &clip[i]
+		b := &clip[(i+1)%len(clip)]
+		in, out = out, make([]GeoCoord, 0, len(out)+4)
+		for j := range in {
+			p := &in[j]
+			q := &in[(j+1)%len(in)]
+			pIn, qIn := inside(p, a, b), inside(q, a, b)
+			if pIn {
+				out = append(out, *p)
+			}
+			if pIn != qIn {
+				out = append(out, intersect(p, q, a, b))
+			}
+		}
+	}
+	return out
+}
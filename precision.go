@@ -0,0 +1,55 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !h3precise
+
+package h3go
+
+import "math"
+
+// hex2dPolarToGeo finds the point on the sphere given by the hex2d polar
+// coordinates (r, theta) on face at resolution res, with theta measured
+// counter-clockwise from the hex2d x axis. By default this uses
+// plain float64 arithmetic; building with the h3precise tag swaps in the
+// extended-precision version from precision_precise.go.
+func hex2dPolarToGeo(face, res int, substrate bool, r, theta float64, g *GeoCoord) {
+	// adjust theta for Class III
+	// if a substrate grid, then it's already been adjusted for Class III
+	if !substrate && isResClassIII(res) {
+		theta = _posAngleRads(theta + M_AP7_ROT_RADS)
+	}
+
+	// find theta as an azimuth
+	theta = _posAngleRads(faceAxesAzRadsCII[face][0] - theta)
+
+	// scale for current resolution length u
+	for i := 0; i < res; i++ {
+		r /= M_SQRT7
+	}
+
+	// scale accordingly if this is a substrate grid
+	if substrate {
+		r /= 3.0
+		if isResClassIII(res) {
+			r /= M_SQRT7
+		}
+	}
+
+	r *= RES0_U_GNOMONIC
+
+	// perform inverse gnomonic scaling of r
+	r = math.Atan(r)
+
+	_geoAzDistanceRads(&faceCenterGeo[face], theta, r, g)
+}
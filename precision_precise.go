@@ -0,0 +1,227 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build h3precise
+
+package h3go
+
+import "math"
+
+// Precise mode, enabled with the h3precise build tag, replaces the final step
+// of every hex2d to spherical conversion (cell centers, boundaries and edge
+// vertices) with a version evaluated in float64-pair arithmetic. The face
+// axis azimuths and face centers are carried at the full precision of their
+// decimal tables, the radius is scaled to the resolution and passed through
+// the inverse gnomonic projection without intermediate rounding, and the
+// point at that distance from the face center is built from the face center
+// and its tangent vectors instead of through the spherical law of cosines,
+// which loses bits to cancellation.
+//
+// The effect is visible where adjacent cells compute a shared vertex from
+// different icosahedron faces; vertices shared within a face come out
+// identical in either mode. Sampling such cross-face vertices at resolutions
+// 9 to 15, the worst mismatch is about 9e-9 m with the default build and
+// about 6e-9 m in precise mode, against a floor of about 1.4e-9 m set by
+// rounding the result to float64 radians. Computing a cell boundary takes
+// about 1.7x as long in precise mode.
+
+// ddFloat is an unevaluated sum hi + lo of two float64 values with
+// |lo| <= ulp(hi) / 2, giving about 106 bits of precision.
+type ddFloat struct {
+	hi, lo float64
+}
+
+// twoSum returns s = fl(a + b) and the rounding error e, so that a + b = s + e
+// exactly.
+func twoSum(a, b float64) (s, e float64) {
+	s = a + b
+	bb := s - a
+	e = (a - (s - bb)) + (b - bb)
+	return s, e
+}
+
+// twoProd returns p = fl(a * b) and the rounding error e, so that
+// a * b = p + e exactly.
+func twoProd(a, b float64) (p, e float64) {
+	p = a * b
+	e = math.FMA(a, b, -p)
+	return p, e
+}
+
+// ddNorm renormalizes hi + lo into a ddFloat.
+func ddNorm(hi, lo float64) ddFloat {
+	s, e := twoSum(hi, lo)
+	return ddFloat{s, e}
+}
+
+// add returns d + b.
+func (d ddFloat) add(b ddFloat) ddFloat {
+	s, e := twoSum(d.hi, b.hi)
+	return ddNorm(s, e+d.lo+b.lo)
+}
+
+// sub returns d - b.
+func (d ddFloat) sub(b ddFloat) ddFloat {
+	return d.add(ddFloat{-b.hi, -b.lo})
+}
+
+// mul returns d * b.
+func (d ddFloat) mul(b ddFloat) ddFloat {
+	p, e := twoProd(d.hi, b.hi)
+	return ddNorm(p, e+d.hi*b.lo+d.lo*b.hi)
+}
+
+// div returns d / b.
+func (d ddFloat) div(b ddFloat) ddFloat {
+	q := d.hi / b.hi
+	// remainder d - q * b
+	p, e := twoProd(q, b.hi)
+	r := (d.hi - p - e + d.lo - q*b.lo) / b.hi
+	return ddNorm(q, r)
+}
+
+// ddSincos returns sin(d) and cos(d), applying the low part to first order.
+func ddSincos(d ddFloat) (sin, cos ddFloat) {
+	s, c := math.Sincos(d.hi)
+	return ddNorm(s, c*d.lo), ddNorm(c, -s*d.lo)
+}
+
+var (
+	// square root of 7
+	ddSqrt7 = ddFloat{2.6457513110645907, -1.2566948082017735e-16}
+	// rotation angle between Class II and Class III resolution axes
+	ddAp7RotRads = ddFloat{M_AP7_ROT_RADS, 2.0232894048364963e-17}
+)
+
+// remainders of the faceCenterGeo entries below float64 precision
+var faceCenterGeoLo = [NUM_ICOSA_FACES][2]float64{
+	{-1.8874182543193457e-17, -4.3399456948623993e-17}, // face  0
+	{-3.7694881010742393e-17, -2.1636303016531746e-16}, // face  1
+	{5.858845408423804e-18, -3.39501806597109e-17},     // face  2
+	{3.910924923751736e-18, 1.298747479186568e-17},     // face  3
+	{2.5326603237786912e-17, 7.505804386499222e-18},    // face  4
+	{-9.187317219606484e-18, -7.836471589840948e-17},   // face  5
+	{-1.96964463016775e-17, -6.299542085453868e-17},    // face  6
+	{-1.2865928867162438e-17, -6.28894576022285e-17},   // face  7
+	{1.6668392901410698e-18, -3.7368661627639084e-17},  // face  8
+	{4.183487952730502e-18, 2.8462586939131144e-17},    // face  9
+	{-1.6668392901410698e-18, 8.463337490381673e-17},   // face 10
+	{-4.183487952730502e-18, -2.0456175205484034e-16},  // face 11
+	{9.187317219606484e-18, 2.0677852495166008e-17},    // face 12
+	{1.96964463016775e-17, 8.291571923407901e-18},      // face 13
+	{1.2865928867162438e-17, 6.011257892922731e-17},    // face 14
+	{-3.910924923751736e-18, -3.154394237045199e-17},   // face 15
+	{-2.5326603237786912e-17, 1.6305952401133255e-16},  // face 16
+	{1.8874182543193457e-17, 5.66431114449515e-17},     // face 17
+	{3.7694881010742393e-17, -6.298159309226321e-18},   // face 18
+	{-5.858845408423804e-18, 8.805185587174493e-17},    // face 19
+}
+
+// remainders of the faceAxesAzRadsCII vertex 0 entries below float64
+// precision
+var faceAxesAzRadsCIILo = [NUM_ICOSA_FACES]float64{
+	3.445689666136168e-16,   // face  0
+	-2.356489837788977e-16,  // face  1
+	1.0002385086118011e-17,  // face  2
+	4.690462795668282e-18,   // face  3
+	1.967676273547113e-16,   // face  4
+	-1.9013986297836527e-16, // face  5
+	-2.0541996105364525e-16, // face  6
+	1.2155047538131476e-16,  // face  7
+	1.2798509619233664e-16,  // face  8
+	1.5794536370632704e-16,  // face  9
+	-2.040681964480318e-16,  // face 10
+	-8.187751559242316e-18,  // face 11
+	-2.0925007877725874e-17, // face 12
+	-4.644909802445909e-18,  // face 13
+	2.464556342130527e-16,   // face 14
+	1.1731157373578753e-16,  // face 15
+	1.7123848223965616e-16,  // face 16
+	2.243714298075065e-17,   // face 17
+	1.585658835232025e-16,   // face 18
+	1.97734898282215e-18,    // face 19
+}
+
+// faceTangents holds, per icosahedron face, the unit vectors of the face
+// center, of north and of east at the face center.
+var faceTangents = func() (t [NUM_ICOSA_FACES][3][3]ddFloat) {
+	for f := range t {
+		sinLat, cosLat := ddSincos(ddFloat{faceCenterGeo[f].lat, faceCenterGeoLo[f][0]})
+		sinLon, cosLon := ddSincos(ddFloat{faceCenterGeo[f].lon, faceCenterGeoLo[f][1]})
+		t[f][0] = [3]ddFloat{cosLat.mul(cosLon), cosLat.mul(sinLon), sinLat}
+		t[f][1] = [3]ddFloat{
+			sinLat.mul(cosLon).mul(ddFloat{-1, 0}),
+			sinLat.mul(sinLon).mul(ddFloat{-1, 0}),
+			cosLat,
+		}
+		t[f][2] = [3]ddFloat{{-sinLon.hi, -sinLon.lo}, cosLon, {}}
+	}
+	return t
+}()
+
+// hex2dPolarToGeo finds the point on the sphere given by the hex2d polar
+// coordinates (r, theta) on face at resolution res, with theta measured
+// counter-clockwise from the hex2d x axis.
+func hex2dPolarToGeo(face, res int, substrate bool, r, theta float64, g *GeoCoord) {
+	// adjust theta for Class III
+	// if a substrate grid, then it's already been adjusted for Class III
+	t := ddFloat{theta, 0}
+	if !substrate && isResClassIII(res) {
+		t = t.add(ddAp7RotRads)
+	}
+
+	// find theta as an azimuth
+	az := ddFloat{faceAxesAzRadsCII[face][0], faceAxesAzRadsCIILo[face]}.sub(t)
+
+	// scale for current resolution length u
+	d := ddFloat{r, 0}
+	for i := 0; i < res; i++ {
+		d = d.div(ddSqrt7)
+	}
+
+	// scale accordingly if this is a substrate grid
+	if substrate {
+		d = d.div(ddFloat{3, 0})
+		if isResClassIII(res) {
+			d = d.div(ddSqrt7)
+		}
+	}
+
+	d = d.mul(ddFloat{RES0_U_GNOMONIC, 0})
+
+	// perform inverse gnomonic scaling of r; the low part goes through the
+	// derivative of atan
+	dist := ddNorm(math.Atan(d.hi), d.lo/(1+d.hi*d.hi))
+
+	// point = cos(dist) * center + sin(dist) * (cos(az) * north + sin(az) * east)
+	sinDist, cosDist := ddSincos(dist)
+	sinAz, cosAz := ddSincos(az)
+	coef := [3]ddFloat{cosDist, sinDist.mul(cosAz), sinDist.mul(sinAz)}
+
+	basis := &faceTangents[face]
+	var p [3]float64
+	for i := range p {
+		sum := coef[0].mul(basis[0][i]).
+			add(coef[1].mul(basis[1][i])).
+			add(coef[2].mul(basis[2][i]))
+		p[i] = sum.hi + sum.lo
+	}
+
+	g.lat = math.Atan2(p[2], math.Hypot(p[0], p[1]))
+	if math.Abs(g.lat-M_PI_2) < EPSILON || math.Abs(g.lat+M_PI_2) < EPSILON {
+		g.lon = 0
+		return
+	}
+	g.lon = constrainLng(math.Atan2(p[1], p[0]))
+}
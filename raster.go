@@ -0,0 +1,177 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"slices"
+	"sort"
+)
+
+// RasterOptions configures RasterizeCells and RenderPNG. The zero value
+// renders an 800 pixels wide image on a transparent background, coloring
+// cells on a blue to red ramp spanning the range of the values.
+type RasterOptions struct {
+	// Width of the image in pixels. Defaults to 800.
+	Width int
+
+	// Height of the image in pixels. Defaults to the height preserving the
+	// aspect ratio of the projected cells.
+	Height int
+
+	// Padding around the cells in pixels.
+	Padding float64
+
+	// Min and Max bound the values mapped onto the color ramp. When they are
+	// equal, the range of the values is used instead.
+	Min, Max float64
+
+	// Color maps a value normalized to [0, 1] onto a color. Defaults to
+	// HeatColor.
+	Color func(t float64) color.RGBA
+
+	// Background fills the image before the cells are drawn. Defaults to
+	// transparent.
+	Background color.Color
+
+	// Projection used for the cell boundaries. Defaults to Equirectangular.
+	Projection Projection
+}
+
+// HeatColor maps t in [0, 1] linearly from opaque blue to opaque red.
+func HeatColor(t float64) color.RGBA {
+	t = math.Max(0, math.Min(1, t))
+	return color.RGBA{R: uint8(255 * t), G: 0, B: uint8(255 * (1 - t)), A: 255}
+}
+
+// RasterizeCells draws the cells of values filled with the color of their
+// value into a new image. Cells are drawn in index order, so overlapping
+// cells of mixed resolutions resolve deterministically.
+//
+// Return ErrInvalidCell if any cell is invalid.
+func RasterizeCells(values map[H3Index]float64, opts RasterOptions) (*image.RGBA, error) {
+	if opts.Width <= 0 {
+		opts.Width = 800
+	}
+	if opts.Color == nil {
+		opts.Color = HeatColor
+	}
+
+	cells := make([]H3Index, 0, len(values))
+	for cell := range values {
+		cells = append(cells, cell)
+	}
+	slices.Sort(cells)
+
+	shapes, bounds, err := projectCells(cells, opts.Projection)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Height <= 0 {
+		opts.Height = opts.Width
+		if bounds.width() > 0 {
+			opts.Height = int(math.Ceil(float64(opts.Width) * bounds.height() / bounds.width()))
+		}
+	}
+	tr := fitProjected(bounds, float64(opts.Width), float64(opts.Height), opts.Padding)
+
+	lo, hi := opts.Min, opts.Max
+	if lo == hi {
+		lo, hi = math.Inf(1), math.Inf(-1)
+		for _, v := range values {
+			lo = math.Min(lo, v)
+			hi = math.Max(hi, v)
+		}
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, opts.Width, opts.Height))
+	if opts.Background != nil {
+		draw.Draw(img, img.Bounds(), image.NewUniform(opts.Background), image.Point{}, draw.Src)
+	}
+
+	pts := make([][2]float64, 0, MAX_CELL_BNDRY_VERTS)
+	for _, shape := range shapes {
+		t := 0.0
+		if hi > lo {
+			t = (values[shape.cell] - lo) / (hi - lo)
+		}
+
+		pts = pts[:0]
+		for _, p := range shape.points {
+			x, y := tr.apply(p)
+			pts = append(pts, [2]float64{x, y})
+		}
+		fillPolygon(img, pts, opts.Color(t))
+	}
+
+	return img, nil
+}
+
+// RenderPNG rasterizes the cells of values with RasterizeCells and writes the
+// result to w as a PNG image.
+func RenderPNG(w io.Writer, values map[H3Index]float64, opts RasterOptions) error {
+	img, err := RasterizeCells(values, opts)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// fillPolygon fills the polygon pts, given in pixel coordinates, with c using
+// an even-odd scanline sampled at pixel centers.
+func fillPolygon(img *image.RGBA, pts [][2]float64, c color.RGBA) {
+	if len(pts) < 3 {
+		return
+	}
+
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, p := range pts {
+		minY = math.Min(minY, p[1])
+		maxY = math.Max(maxY, p[1])
+	}
+
+	b := img.Bounds()
+	y0 := max(int(math.Floor(minY)), b.Min.Y)
+	y1 := min(int(math.Ceil(maxY)), b.Max.Y)
+
+	var xs []float64
+	for py := y0; py < y1; py++ {
+		sy := float64(py) + 0.5
+
+		xs = xs[:0]
+		for i := range pts {
+			a, z := pts[i], pts[(i+1)%len(pts)]
+			if (a[1] <= sy) == (z[1] <= sy) {
+				continue
+			}
+			xs = append(xs, a[0]+(sy-a[1])*(z[0]-a[0])/(z[1]-a[1]))
+		}
+		sort.Float64s(xs)
+
+		for i := 0; i+1 < len(xs); i += 2 {
+			x0 := max(int(math.Ceil(xs[i]-0.5)), b.Min.X)
+			x1 := min(int(math.Ceil(xs[i+1]-0.5)), b.Max.X)
+			for px := x0; px < x1; px++ {
+				img.SetRGBA(px, py, c)
+			}
+		}
+	}
+}
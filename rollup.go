@@ -0,0 +1,110 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"fmt"
+	"math"
+)
+
+// Aggregation selects how RollUp combines the values of the cells under a
+// parent.
+type Aggregation int
+
+const (
+	// The sum of the values.
+	AggregateSum Aggregation = iota
+	// The mean of the values present.
+	AggregateMean
+	// The smallest value.
+	AggregateMin
+	// The largest value.
+	AggregateMax
+	// The mean over every descendant of the parent, counting descendants
+	// without a value as zero. The number of descendants is exact, so
+	// pentagon parents are divided by their smaller count rather than by a
+	// power of 7.
+	AggregateCoverageMean
+
+	numAggregations
+)
+
+// rollUpAcc accumulates the values under one parent.
+type rollUpAcc struct {
+	sum, min, max float64
+	n             int
+}
+
+// RollUp combines values, given for cells all at the same resolution, into
+// one value for each ancestor at res which has values under it, using agg.
+// res may equal the resolution of the cells, which keeps every cell as its
+// own parent.
+//
+// Return ErrInvalidAggregation if agg is unknown, ErrInvalidResolution if
+// res is out of range, ErrInvalidCell if values has a key which is not a
+// valid cell, or ErrResolutionMismatch if the cells are not all at the same
+// resolution or are coarser than res.
+func RollUp(values map[H3Index]float64, res int, agg Aggregation) (map[H3Index]float64, error) {
+	if agg < 0 || agg >= numAggregations {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidAggregation, agg)
+	}
+	if res < 0 || res > MAX_H3_RES {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidResolution, res)
+	}
+
+	fineRes := -1
+	accs := make(map[H3Index]*rollUpAcc)
+	for cell, v := range values {
+		if !H3IsValid(cell) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCell, cell)
+		}
+		if fineRes < 0 {
+			fineRes = H3_GET_RESOLUTION(cell)
+			if fineRes < res {
+				return nil, fmt.Errorf("%w: %d and %d", ErrResolutionMismatch, fineRes, res)
+			}
+		} else if H3_GET_RESOLUTION(cell) != fineRes {
+			return nil, fmt.Errorf("%w: %d and %d", ErrResolutionMismatch, H3_GET_RESOLUTION(cell), fineRes)
+		}
+
+		parent := H3ToParent(cell, res)
+		acc := accs[parent]
+		if acc == nil {
+			acc = &rollUpAcc{min: math.Inf(1), max: math.Inf(-1)}
+			accs[parent] = acc
+		}
+		acc.sum += v
+		acc.min = math.Min(acc.min, v)
+		acc.max = math.Max(acc.max, v)
+		acc.n++
+	}
+
+	out := make(map[H3Index]float64, len(accs))
+	for parent, acc := range accs {
+		switch agg {
+		case AggregateSum:
+			out[parent] = acc.sum
+		case AggregateMean:
+			out[parent] = acc.sum / float64(acc.n)
+		case AggregateMin:
+			out[parent] = acc.min
+		case AggregateMax:
+			out[parent] = acc.max
+		case AggregateCoverageMean:
+			out[parent] = acc.sum / float64(childrenSize(parent, fineRes))
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"fmt"
+	"math"
+)
+
+// POLYGON_SIMPLIFY_EDGE_FRACTION is the tolerance SimplifyGeoPolygonForRes
+// allows, as a fraction of the average edge length of the cells. Moving the
+// boundary by so little changes which side of it only the few centers very
+// close to it fall on.
+const POLYGON_SIMPLIFY_EDGE_FRACTION = 0.1
+
+// SimplifyGeoPolygonForRes simplifies poly before it is filled with cells at
+// res, see SimplifyGeoPolygon, with a tolerance of
+// POLYGON_SIMPLIFY_EDGE_FRACTION of the average edge length at res. Detailed
+// boundaries such as coastlines can have many vertices per cell, and the cost
+// of PolygonToCells grows with the number of vertices, so simplifying them
+// first saves time at the cost of a few cells near the boundary.
+//
+// Return the simplified polygon, or ErrInvalidResolution if res is out of
+// range.
+func SimplifyGeoPolygonForRes(poly GeoPolygon, res int) (GeoPolygon, error) {
+	if res < 0 || res > MAX_H3_RES {
+		return GeoPolygon{}, fmt.Errorf("%w: %d", ErrInvalidResolution, res)
+	}
+	tolerance := POLYGON_SIMPLIFY_EDGE_FRACTION * EdgeLengthKm(res) / EARTH_RADIUS_KM
+	return SimplifyGeoPolygon(poly, tolerance), nil
+}
+
+// SimplifyGeoPolygon simplifies the exterior boundary and the holes of poly,
+// see SimplifyGeofence.
+func SimplifyGeoPolygon(poly GeoPolygon, toleranceRads float64) GeoPolygon {
+	out := GeoPolygon{
+		geofence: SimplifyGeofence(poly.geofence, toleranceRads),
+		numHoles: poly.numHoles,
+	}
+	if poly.numHoles > 0 {
+		out.holes = make([]Geofence, poly.numHoles)
+		for i := range out.holes {
+			out.holes[i] = SimplifyGeofence(poly.holes[i], toleranceRads)
+		}
+	}
+	return out
+}
+
+// SimplifyGeofence drops the vertices of geofence which lie within
+// toleranceRads of the boundary through the remaining ones, with the
+// Douglas-Peucker algorithm. Distances are measured in a plane tangent to the
+// sphere along each edge, which is accurate for tolerances small relative to
+// the radius of the earth.
+//
+// The result keeps at least three vertices, but it is not guaranteed to be
+// free of self-intersections where the boundary runs closer to itself than
+// the tolerance.
+func SimplifyGeofence(geofence Geofence, toleranceRads float64) Geofence {
+	n := geofence.numVerts
+	verts := geofence.verts[:n]
+	if n <= 3 || !(toleranceRads > 0) {
+		out := Geofence{numVerts: n, verts: make([]GeoCoord, n)}
+		copy(out.verts, verts)
+		return out
+	}
+
+	var bbox BBox
+	bboxFromGeofence(&geofence, &bbox)
+	ring := normalizedRing(verts, bboxIsTransmeridian(&bbox))
+
+	// The ring is split in two at the vertex farthest from the first, and
+	// index n stands for the first vertex again at the end of the ring.
+	far := 0
+	farDist := -1.0
+	for i := 1; i < n; i++ {
+		if d := segmentDistanceRads(&ring[i], &ring[0], &ring[0]); d > farDist {
+			far, farDist = i, d
+		}
+	}
+
+	keep := make([]bool, n)
+	keep[0], keep[far] = true, true
+	numKept := 2
+	type span struct{ from, to int }
+	stack := []span{{0, far}, {far, n}}
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		k, kDist := -1, toleranceRads
+		a, b := &ring[s.from], &ring[s.to%n]
+		for i := s.from + 1; i < s.to; i++ {
+			if d := segmentDistanceRads(&ring[i], a, b); d > kDist {
+				k, kDist = i, d
+			}
+		}
+		if k >= 0 {
+			keep[k] = true
+			numKept++
+			stack = append(stack, span{s.from, k}, span{k, s.to})
+		}
+	}
+
+	// A ring narrower than the tolerance is kept as the triangle of its
+	// farthest vertices.
+	if numKept < 3 {
+		k, kDist := -1, -1.0
+		for i := 1; i < n; i++ {
+			if d := segmentDistanceRads(&ring[i], &ring[0], &ring[far]); i != far && d > kDist {
+				k, kDist = i, d
+			}
+		}
+		keep[k] = true
+	}
+
+	out := Geofence{verts: make([]GeoCoord, 0, max(numKept, 3))}
+	for i, v := range verts {
+		if keep[i] {
+			out.verts = append(out.verts, v)
+		}
+	}
+	out.numVerts = len(out.verts)
+	return out
+}
+
+// segmentDistanceRads approximates the distance in radians from p to the
+// segment from a to b, all with normalized longitudes, in a plane where
+// longitudes are scaled to the latitude of the segment.
+func segmentDistanceRads(p, a, b *GeoCoord) float64 {
+	scale := math.Cos((a.lat + b.lat) / 2)
+	ax, ay := a.lon*scale, a.lat
+	bx, by := b.lon*scale, b.lat
+	px, py := p.lon*scale, p.lat
+
+	dx, dy := bx-ax, by-ay
+	t := 0.0
+	if l2 := dx*dx + dy*dy; l2 > 0 {
+		t = math.Min(math.Max(((px-ax)*dx+(py-ay)*dy)/l2, 0), 1)
+	}
+	return math.Hypot(px-(ax+t*dx), py-(ay+t*dy))
+}
@@ -0,0 +1,59 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+// SnapLatLng quantizes p to the center of the cell containing it at the
+// given resolution, the usual way of coarsening coordinates for privacy or
+// deduplication.
+//
+// Return the center of the containing cell, or the zero GeoCoord if p has a
+// non-finite component or res is out of range.
+func SnapLatLng(p GeoCoord, res int) GeoCoord {
+	var center GeoCoord
+	cell := GeoToH3(&p, res)
+	if cell == H3_NULL {
+		return center
+	}
+	H3ToGeo(cell, &center)
+	return center
+}
+
+// SnapLatLngs quantizes every point of points to the center of its
+// containing cell at the given resolution, as SnapLatLng does.
+//
+// Return a new slice of the snapped points in input order. Points that cannot
+// be indexed become the zero GeoCoord.
+func SnapLatLngs(points []GeoCoord, res int) []GeoCoord {
+	out := make([]GeoCoord, len(points))
+	if res < 0 || res > MAX_H3_RES {
+		return out
+	}
+
+	// consecutive points often fall in the same cell, so reuse the last center
+	last := H3_NULL
+	var center GeoCoord
+	for i := range points {
+		cell := GeoToH3(&points[i], res)
+		if cell == H3_NULL {
+			continue
+		}
+		if cell != last {
+			H3ToGeo(cell, &center)
+			last = cell
+		}
+		out[i] = center
+	}
+	return out
+}
@@ -0,0 +1,242 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Projection maps a coordinate given in degrees onto a plane. The y axis of
+// the plane points north.
+type Projection func(latDegs, lngDegs float64) (x, y float64)
+
+// Equirectangular is the plate carrée projection, mapping longitude to x and
+// latitude to y.
+func Equirectangular(latDegs, lngDegs float64) (x, y float64) {
+	return lngDegs, latDegs
+}
+
+// SVGStyle configures the document written by RenderSVG. The zero value
+// renders an 800 pixels wide document with blue cells and black outlines.
+type SVGStyle struct {
+	// Width of the document in pixels. Defaults to 800.
+	Width int
+
+	// Height of the document in pixels. Defaults to the height preserving
+	// the aspect ratio of the projected cells.
+	Height int
+
+	// Padding around the cells in pixels.
+	Padding float64
+
+	// Fill color of the cells. Defaults to "#3388ff".
+	Fill string
+
+	// FillOpacity of the cells between 0 and 1. Defaults to 0.4.
+	FillOpacity float64
+
+	// Stroke color of the cell outlines. Defaults to "#000000".
+	Stroke string
+
+	// StrokeWidth of the cell outlines in pixels. Defaults to 1.
+	StrokeWidth float64
+
+	// CellFill optionally overrides the fill color per cell. An empty result
+	// falls back to Fill.
+	CellFill func(H3Index) string
+
+	// Projection used for the cell boundaries. Defaults to Equirectangular.
+	Projection Projection
+}
+
+// RenderSVG writes an SVG document with the boundaries of cells to w. The
+// projected cells are scaled uniformly to fit the document, and each cell
+// carries its index as a title so it can be identified in a browser.
+//
+// Return ErrInvalidCell if any cell is invalid, or the first write error.
+func RenderSVG(w io.Writer, cells []H3Index, style SVGStyle) error {
+	if style.Width <= 0 {
+		style.Width = 800
+	}
+	if style.Fill == "" {
+		style.Fill = "#3388ff"
+	}
+	if style.FillOpacity <= 0 {
+		style.FillOpacity = 0.4
+	}
+	if style.Stroke == "" {
+		style.Stroke = "#000000"
+	}
+	if style.StrokeWidth <= 0 {
+		style.StrokeWidth = 1
+	}
+
+	shapes, bounds, err := projectCells(cells, style.Projection)
+	if err != nil {
+		return err
+	}
+
+	if style.Height <= 0 {
+		style.Height = style.Width
+		if bounds.width() > 0 {
+			style.Height = int(math.Ceil(float64(style.Width) * bounds.height() / bounds.width()))
+		}
+	}
+	tr := fitProjected(bounds, float64(style.Width), float64(style.Height), style.Padding)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		style.Width, style.Height, style.Width, style.Height)
+	fmt.Fprintf(bw, `<g fill-opacity="%s" stroke="%s" stroke-width="%s" stroke-linejoin="round">`+"\n",
+		formatSVGNumber(style.FillOpacity), html.EscapeString(style.Stroke), formatSVGNumber(style.StrokeWidth))
+
+	var buf []byte
+	for _, shape := range shapes {
+		fill := style.Fill
+		if style.CellFill != nil {
+			if f := style.CellFill(shape.cell); f != "" {
+				fill = f
+			}
+		}
+
+		buf = append(buf[:0], `<polygon points="`...)
+		for i, p := range shape.points {
+			if i > 0 {
+				buf = append(buf, ' ')
+			}
+			x, y := tr.apply(p)
+			buf = strconv.AppendFloat(buf, x, 'f', 2, 64)
+			buf = append(buf, ',')
+			buf = strconv.AppendFloat(buf, y, 'f', 2, 64)
+		}
+		buf = append(buf, `" fill="`...)
+		buf = append(buf, html.EscapeString(fill)...)
+		buf = append(buf, `"><title>`...)
+		buf = append(buf, shape.cell.String()...)
+		buf = append(buf, "</title></polygon>\n"...)
+		bw.Write(buf)
+	}
+
+	bw.WriteString("</g>\n</svg>\n")
+	return bw.Flush()
+}
+
+// formatSVGNumber formats a float attribute value without superfluous digits.
+func formatSVGNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// projectedCell is the boundary of a cell projected onto a plane.
+type projectedCell struct {
+	cell   H3Index
+	points [][2]float64
+}
+
+// projectedBounds is the bounding rectangle of projected cells.
+type projectedBounds struct {
+	minX, minY, maxX, maxY float64
+}
+
+func (b *projectedBounds) width() float64  { return b.maxX - b.minX }
+func (b *projectedBounds) height() float64 { return b.maxY - b.minY }
+
+// projectCells projects the boundaries of cells with proj, defaulting to
+// Equirectangular. Boundary longitudes are unwrapped so that cells crossing
+// the antimeridian stay contiguous instead of spanning the whole map.
+func projectCells(cells []H3Index, proj Projection) ([]projectedCell, projectedBounds, error) {
+	if proj == nil {
+		proj = Equirectangular
+	}
+
+	bounds := projectedBounds{
+		minX: math.Inf(1), minY: math.Inf(1),
+		maxX: math.Inf(-1), maxY: math.Inf(-1),
+	}
+	shapes := make([]projectedCell, 0, len(cells))
+
+	var gb GeoBoundary
+	for _, cell := range cells {
+		if !cell.IsValid() {
+			return nil, projectedBounds{}, fmt.Errorf("%w: %s", ErrInvalidCell, cell)
+		}
+
+		H3ToGeoBoundary(cell, &gb)
+		shape := projectedCell{cell: cell, points: make([][2]float64, gb.numVerts)}
+		firstLng := RadsToDegs(gb.verts[0].lon)
+		for i := 0; i < gb.numVerts; i++ {
+			lng := RadsToDegs(gb.verts[i].lon)
+			if lng-firstLng > 180 {
+				lng -= 360
+			} else if firstLng-lng > 180 {
+				lng += 360
+			}
+
+			x, y := proj(RadsToDegs(gb.verts[i].lat), lng)
+			shape.points[i] = [2]float64{x, y}
+			bounds.minX = math.Min(bounds.minX, x)
+			bounds.minY = math.Min(bounds.minY, y)
+			bounds.maxX = math.Max(bounds.maxX, x)
+			bounds.maxY = math.Max(bounds.maxY, y)
+		}
+		shapes = append(shapes, shape)
+	}
+
+	if len(shapes) == 0 {
+		bounds = projectedBounds{}
+	}
+	return shapes, bounds, nil
+}
+
+// pixelTransform maps projected coordinates onto pixel coordinates, where the
+// y axis points down.
+type pixelTransform struct {
+	scale      float64
+	offX, offY float64
+	maxY       float64
+}
+
+// fitProjected returns the transform scaling bounds uniformly into a width by
+// height pixel area, centered, leaving padding pixels on every side.
+func fitProjected(bounds projectedBounds, width, height, padding float64) pixelTransform {
+	availW := math.Max(width-2*padding, 1)
+	availH := math.Max(height-2*padding, 1)
+
+	scale := 1.0
+	switch {
+	case bounds.width() > 0 && bounds.height() > 0:
+		scale = math.Min(availW/bounds.width(), availH/bounds.height())
+	case bounds.width() > 0:
+		scale = availW / bounds.width()
+	case bounds.height() > 0:
+		scale = availH / bounds.height()
+	}
+
+	return pixelTransform{
+		scale: scale,
+		offX:  (width-bounds.width()*scale)/2 - bounds.minX*scale,
+		offY:  (height-bounds.height()*scale)/2 - bounds.minY*scale,
+		maxY:  height,
+	}
+}
+
+// apply transforms a projected point into pixel coordinates.
+func (t *pixelTransform) apply(p [2]float64) (x, y float64) {
+	return p[0]*t.scale + t.offX, t.maxY - (p[1]*t.scale + t.offY)
+}
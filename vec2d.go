@@ -43,7 +43,9 @@ func v2dIntersect(p0, p1, p2, p3 *Vec2d) *Vec2d {
 	s2.x = p3.x - p2.x
 	s2.y = p3.y - p2.y
 
-	t := (s2.x*(p0.y-p2.y) - s2.y*(p0.x-p2.x)) / (-s2.x*s1.y + s1.x*s2.y)
+	// The reference implementation keeps t in single precision, which decides
+	// whether an intersection falls exactly on a vertex; match it.
+	t := float64(float32((s2.x*(p0.y-p2.y) - s2.y*(p0.x-p2.x)) / (-s2.x*s1.y + s1.x*s2.y)))
 
 	return &Vec2d{
 		x: p0.x + (t * s1.x),
@@ -63,7 +65,9 @@ func _v2dIntersect(p0, p1, p2, p3 *Vec2d, inter *Vec2d) {
 	s2.x = p3.x - p2.x
 	s2.y = p3.y - p2.y
 
-	t := (s2.x*(p0.y-p2.y) - s2.y*(p0.x-p2.x)) / (-s2.x*s1.y + s1.x*s2.y)
+	// The reference implementation keeps t in single precision, which decides
+	// whether an intersection falls exactly on a vertex; match it.
+	t := float64(float32((s2.x*(p0.y-p2.y) - s2.y*(p0.x-p2.x)) / (-s2.x*s1.y + s1.x*s2.y)))
 
 	inter.x = p0.x + (t * s1.x)
 	inter.y = p0.y + (t * s1.y)
@@ -0,0 +1,198 @@
+// Copyright 2022  Il Sub Bang
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h3go
+
+import "fmt"
+
+// Verification levels of Verify.
+const (
+	// VerifyQuick checks all pentagons and one cell per base cell at
+	// resolutions 0 to 3, taking a few milliseconds.
+	VerifyQuick = 0
+
+	// VerifyStandard checks all pentagons and two cells per base cell at
+	// every resolution.
+	VerifyStandard = 1
+
+	// VerifyThorough checks all pentagons with their surroundings and eight
+	// cells per base cell at every resolution.
+	VerifyThorough = 2
+)
+
+// Verify runs internal invariant checks of the library, so that deployments
+// can sanity check it at startup, for example on a new architecture:
+//
+//   - every resolution has 12 valid pentagons, each with 5 neighbors
+//   - the center of sampled cells indexes back to the cell
+//   - sampled cells are children of their parent, which has 7 children, or 6
+//     if it is a pentagon
+//   - the neighbors of sampled cells are valid, adjacent, and have the cell
+//     as a neighbor in turn
+//   - local IJ coordinates of neighbors round trip
+//
+// Sampled cells are chosen deterministically. Levels below VerifyQuick run as
+// VerifyQuick and levels above VerifyThorough as VerifyThorough.
+//
+// Return nil if all checks pass, or ErrVerifyFailed describing the first
+// failure.
+func Verify(level int) error {
+	maxRes, samples, pentagonK := 3, 1, 1
+	switch {
+	case level >= VerifyThorough:
+		maxRes, samples, pentagonK = MAX_H3_RES, 8, 2
+	case level == VerifyStandard:
+		maxRes, samples = MAX_H3_RES, 2
+	}
+
+	pentagons := make([]H3Index, NUM_PENTAGONS)
+	for res := 0; res <= maxRes; res++ {
+		GetPentagonIndexes(res, &pentagons)
+		for _, pentagon := range pentagons {
+			if err := verifyPentagon(pentagon, res, pentagonK); err != nil {
+				return err
+			}
+		}
+	}
+
+	// A linear congruential generator picks the digits of sampled cells.
+	seed := uint64(0x9e3779b97f4a7c15)
+	for res := 0; res <= maxRes; res++ {
+		for baseCell := 0; baseCell < NUM_BASE_CELLS; baseCell++ {
+			for i := 0; i < samples; i++ {
+				cell := H3_INIT
+				H3_SET_MODE(&cell, H3_HEXAGON_MODE)
+				H3_SET_RESOLUTION(&cell, res)
+				H3_SET_BASE_CELL(&cell, baseCell)
+				for r := 1; r <= res; r++ {
+					seed = seed*6364136223846793005 + 1442695040888963407
+					H3_SET_INDEX_DIGIT(&cell, r, Direction((seed>>33)%uint64(NUM_DIGITS)))
+				}
+				if _isBaseCellPentagon(baseCell) && _h3LeadingNonZeroDigit(cell) == K_AXES_DIGIT {
+					cell = _h3Rotate60cw(cell)
+				}
+
+				if err := verifyCell(cell); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// verifyPentagon checks a pentagon at res and the cells within k of it.
+func verifyPentagon(pentagon H3Index, res int, k int) error {
+	if !pentagon.IsValid() || !pentagon.IsPentagon() || H3_GET_RESOLUTION(pentagon) != res {
+		return fmt.Errorf("%w: %s is not a valid pentagon at resolution %d", ErrVerifyFailed, pentagon, res)
+	}
+
+	if err := verifyCell(pentagon); err != nil {
+		return err
+	}
+	for _, cell := range kRing(pentagon, k) {
+		if cell == H3_NULL || cell == pentagon {
+			continue
+		}
+		if cell.IsPentagon() {
+			return fmt.Errorf("%w: pentagons %s and %s are within %d steps", ErrVerifyFailed, pentagon, cell, k)
+		}
+		if err := verifyCell(cell); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyCell checks the invariants of a single valid cell.
+func verifyCell(cell H3Index) error {
+	if !cell.IsValid() {
+		return fmt.Errorf("%w: %s is not valid", ErrVerifyFailed, cell)
+	}
+	res := H3_GET_RESOLUTION(cell)
+
+	var center GeoCoord
+	H3ToGeo(cell, &center)
+	if got := GeoToH3(&center, res); got != cell {
+		return fmt.Errorf("%w: center of %s indexes to %s", ErrVerifyFailed, cell, got)
+	}
+
+	if res > 0 {
+		parent := H3ToParent(cell, res-1)
+		children := parent.ToChildren(res)
+		want := 7
+		if parent.IsPentagon() {
+			want = 6
+		}
+
+		found := false
+		n := 0
+		for _, child := range children {
+			if child == H3_NULL {
+				continue
+			}
+			n++
+			if child == cell {
+				found = true
+			}
+			if H3ToParent(child, res-1) != parent {
+				return fmt.Errorf("%w: child %s of %s has parent %s", ErrVerifyFailed, child, parent, H3ToParent(child, res-1))
+			}
+		}
+		if !found || n != want {
+			return fmt.Errorf("%w: %s has %d children, want %d including %s", ErrVerifyFailed, parent, n, want, cell)
+		}
+	}
+
+	want := 6
+	if cell.IsPentagon() {
+		want = 5
+	}
+	neighbors := appendNeighbors(nil, cell)
+	if len(neighbors) != want {
+		return fmt.Errorf("%w: %s has %d neighbors, want %d", ErrVerifyFailed, cell, len(neighbors), want)
+	}
+
+	for _, neighbor := range neighbors {
+		if !neighbor.IsValid() || H3_GET_RESOLUTION(neighbor) != res {
+			return fmt.Errorf("%w: neighbor %s of %s is not valid", ErrVerifyFailed, neighbor, cell)
+		}
+		if !H3IndexesAreNeighbors(cell, neighbor) {
+			return fmt.Errorf("%w: %s and %s are not reported as neighbors", ErrVerifyFailed, cell, neighbor)
+		}
+
+		symmetric := false
+		for _, back := range appendNeighbors(nil, neighbor) {
+			if back == cell {
+				symmetric = true
+				break
+			}
+		}
+		if !symmetric {
+			return fmt.Errorf("%w: %s is a neighbor of %s but not the reverse", ErrVerifyFailed, neighbor, cell)
+		}
+
+		// Local IJ may fail near pentagons, but must round trip otherwise.
+		var ij CoordIJ
+		if ExperimentalH3ToLocalIj(cell, neighbor, &ij) == 0 {
+			var back H3Index
+			if ExperimentalLocalIjToH3(cell, &ij, &back) != 0 || back != neighbor {
+				return fmt.Errorf("%w: local IJ of %s from %s does not round trip", ErrVerifyFailed, neighbor, cell)
+			}
+		}
+	}
+
+	return nil
+}
@@ -68,9 +68,12 @@ func destroyVertexGraph(graph *VertexGraph) {
 /**
  * Get an integer hash for a lat/lon point, at a precision determined
  * by the current hexagon resolution.
- * TODO: Light testing suggests this might not be sufficient at resolutions
- * finer than 10. Design a better hash function if performance and collisions
- * seem to be an issue here.
+ *
+ * The precision is a thousandth of the edge length at the resolution, which
+ * keeps the vertices of a hexagon apart while giving the same hash to copies
+ * of a vertex computed from neighboring hexagons, which differ by rounding
+ * only. A fixed number of decimal places would split such copies at coarse
+ * resolutions and merge distinct vertices at fine ones.
  * @param  vertex     Lat/lon vertex to hash
  * @param  res        Resolution of the hexagon the vertex belongs to
  * @param  numBuckets Number of buckets in the graph
@@ -79,11 +82,10 @@ func destroyVertexGraph(graph *VertexGraph) {
 func _hashVertex(vertex *GeoCoord, res int, numBuckets int) uint32 {
 	// Simple hash: Take the sum of the lat and lon with a precision level
 	// determined by the resolution, converted to int, modulo bucket count.
+	precision := 1000 * EARTH_RADIUS_KM / EdgeLengthKm(res)
 	return uint32(
 		math.Mod(
-			math.Abs(
-				(vertex.lat+vertex.lon)*math.Pow(10, float64(15-res)),
-			),
+			math.Abs((vertex.lat+vertex.lon)*precision),
 			float64(numBuckets),
 		),
 	)